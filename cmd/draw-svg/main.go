@@ -1,14 +1,50 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 
 	"github.com/flowdev/gflowparser/svg"
+	"gopkg.in/yaml.v2"
 )
 
 func main() {
-	buf, err := svg.FromFlowData(flowData)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"Usage: %s [FILE]\n\n"+
+				"Draws the svg.Flow described in FILE (or standard input) as JSON or\n"+
+				"YAML and writes the SVG diagram to standard output.\n",
+			os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var buf []byte
+	var err error
+	switch flag.NArg() {
+	case 0:
+		buf, err = ioutil.ReadAll(os.Stdin)
+	case 1:
+		buf, err = ioutil.ReadFile(flag.Arg(0))
+	default:
+		flag.Usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Unable to read flow data: %s.\n", err)
+		os.Exit(1)
+	}
+
+	flowData, err := decodeFlow(buf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: Unable to decode flow data: %s.\n", err)
+		os.Exit(1)
+	}
+
+	buf, err = svg.FromFlowData(flowData)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "ERROR: %s.\n", err)
 		os.Exit(2)
@@ -23,3 +59,41 @@ func main() {
 		os.Exit(3)
 	}
 }
+
+// decodeFlow decodes a flow given as JSON or YAML.
+// Since JSON is valid YAML, the data is always read as YAML and converted
+// to JSON for the decoding of the shapes.
+func decodeFlow(buf []byte) (svg.Flow, error) {
+	var v interface{}
+	err := yaml.Unmarshal(buf, &v)
+	if err != nil {
+		return svg.Flow{}, err
+	}
+	buf, err = json.Marshal(yamlToJSON(v))
+	if err != nil {
+		return svg.Flow{}, err
+	}
+	f := svg.Flow{}
+	err = json.Unmarshal(buf, &f)
+	return f, err
+}
+
+// yamlToJSON converts the maps created by the YAML decoder into maps with
+// string keys as needed by the JSON encoder.
+func yamlToJSON(v interface{}) interface{} {
+	switch x := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(x))
+		for k, val := range x {
+			m[fmt.Sprint(k)] = yamlToJSON(val)
+		}
+		return m
+	case []interface{}:
+		for i, val := range x {
+			x[i] = yamlToJSON(val)
+		}
+		return x
+	default:
+		return v
+	}
+}
@@ -1,14 +1,100 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/flowdev/gflowparser"
+	"github.com/flowdev/gflowparser/data"
+	"github.com/flowdev/gflowparser/svg"
 )
 
 func main() {
+	layout := flag.String("layout", "rows", "layout engine to use: 'rows' or 'layered'")
+	scale := flag.Float64("scale", 1, "factor for scaling all sizes of the diagram")
+	maxTextWidth := flag.Int("max-text-width", 0, "maximal width of texts in pixels (0: unlimited)")
+	truncate := flag.Bool("truncate", false, "truncate texts that are too long instead of wrapping them")
+	portMarkers := flag.Bool("port-markers", false, "draw ports as small squares on the edges of components")
+	contMarkers := flag.Bool("continuation-markers", false, "draw continuations as numbered connector circles (rows layout)")
+	joinConts := flag.Bool("join-continuations", false, "join continued flow lines so the diagram looks as if they had never been wrapped")
+	markErrors := flag.Bool("mark-errors", false, "draw error arrows dashed and red")
+	collapseErrors := flag.Bool("collapse-errors", false, "let all error arrows end in a shared error sink")
+	errorPorts := flag.String("error-ports", "error,err", "comma separated names of error ports (used by -mark-errors and -collapse-errors)")
+	format := flag.String("format", "svg", "output format: 'svg', 'text' (Unicode box-drawing characters), 'ascii', 'mermaid', 'plantuml' or 'drawio'")
+	astJSON := flag.Bool("ast-json", false, "write the parsed flow as JSON instead of a diagram")
+	flowName := flag.String("flow", "", "name of the flow to convert if the input contains multiple flows")
+	all := flag.Bool("all", false, "convert all flows of the input into files in the output directory")
+	outDir := flag.String("out-dir", ".", "output directory for converting all flows")
+	subflowMode := flag.String("subflow-mode", "none", "how to draw components implemented by other flows: 'none', 'expand' or 'link'")
+	subflowFiles := flag.String("subflows", "", "comma separated flow files with subflows ('[pkg=]file', the package defaults to the name of the directory)")
+	attributes := flag.String("attributes", "", "comma separated keys of the component and arrow attributes to show")
+	flag.Parse()
+
+	opts := gflowparser.Options{}
+	switch *layout {
+	case "rows":
+		opts.Layout = gflowparser.LayoutRows
+	case "layered":
+		opts.Layout = gflowparser.LayoutLayered
+	default:
+		fmt.Fprintf(os.Stderr, "ERROR: Unknown layout engine '%s'.\n", *layout)
+		os.Exit(1)
+	}
+	switch *format {
+	case "svg":
+		opts.Format = gflowparser.FormatSVG
+	case "text":
+		opts.Format = gflowparser.FormatText
+	case "ascii":
+		opts.Format = gflowparser.FormatASCII
+	case "mermaid":
+		opts.Format = gflowparser.FormatMermaid
+	case "plantuml":
+		opts.Format = gflowparser.FormatPlantUML
+	case "drawio":
+		opts.Format = gflowparser.FormatDrawIO
+	default:
+		fmt.Fprintf(os.Stderr, "ERROR: Unknown output format '%s'.\n", *format)
+		os.Exit(1)
+	}
+	if *scale <= 0 {
+		fmt.Fprintf(os.Stderr, "ERROR: Scale factor has to be positive but is: %g.\n", *scale)
+		os.Exit(1)
+	}
+	cfg := svg.DefaultLayoutConfig()
+	cfg.MaxTextWidth = *maxTextWidth
+	cfg.TruncateTexts = *truncate
+	cfg.PortMarkers = *portMarkers
+	cfg.ContinuationMarkers = *contMarkers
+	cfg.MarkErrors = *markErrors
+	cfg.CollapseErrors = *collapseErrors
+	cfg.ErrorPorts = strings.Split(*errorPorts, ",")
+	cfg = cfg.Scale(*scale)
+	opts.LayoutConfig = &cfg
+	opts.FlowName = *flowName
+	switch *subflowMode {
+	case "none":
+		opts.SubflowMode = gflowparser.SubflowNone
+	case "expand":
+		opts.SubflowMode = gflowparser.SubflowExpand
+	case "link":
+		opts.SubflowMode = gflowparser.SubflowLink
+	default:
+		fmt.Fprintf(os.Stderr, "ERROR: Unknown subflow mode '%s'.\n", *subflowMode)
+		os.Exit(1)
+	}
+	opts.JoinContinuations = *joinConts
+	if *attributes != "" {
+		opts.Attributes = strings.Split(*attributes, ",")
+	}
+	if *subflowFiles != "" {
+		opts.Subflows = readSubflows(strings.Split(*subflowFiles, ","))
+	}
+
 	buf, err := ioutil.ReadAll(os.Stdin)
 	if err != nil {
 		fmt.Fprintf(os.Stderr,
@@ -16,10 +102,19 @@ func main() {
 		os.Exit(2)
 	}
 
-	buf, _, _, fb, err := gflowparser.ConvertFlowDSLToSVG(string(buf), "standard input")
+	if *astJSON {
+		writeASTJSON(string(buf), *flowName)
+		return
+	}
+	if *all {
+		writeAllFlows(string(buf), *outDir, *format, opts)
+		return
+	}
+
+	buf, _, _, fb, err := gflowparser.ConvertFlowDSLToSVGWithOptions(string(buf), "standard input", opts)
 	if err != nil {
 		fmt.Fprintf(os.Stderr,
-			"ERROR: Unable to convert flow to SVG:\n%s", err)
+			"ERROR: Unable to convert flow to format '%s':\n%s", *format, err)
 		os.Exit(3)
 	}
 	os.Stderr.WriteString(fb)
@@ -27,7 +122,101 @@ func main() {
 	_, err = os.Stdout.Write(buf)
 	if err != nil {
 		fmt.Fprintf(os.Stderr,
-			"ERROR: Unable to write SVG to standard output: %s.\n", err)
+			"ERROR: Unable to write %s output to standard output: %s.\n", *format, err)
 		os.Exit(7)
 	}
 }
+
+func writeASTJSON(flowContent, flowName string) {
+	ff, fb, err := gflowparser.ParseFlowFileDSL(flowContent, "standard input")
+	if err != nil {
+		fmt.Fprintf(os.Stderr,
+			"ERROR: Unable to parse flow:\n%s", err)
+		os.Exit(3)
+	}
+	os.Stderr.WriteString(fb)
+	flow, err := gflowparser.SelectFlow(ff, flowName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %s.\n", err)
+		os.Exit(3)
+	}
+
+	buf, err := data.EncodeJSON(flow)
+	if err != nil {
+		fmt.Fprintf(os.Stderr,
+			"ERROR: Unable to encode flow as JSON: %s.\n", err)
+		os.Exit(4)
+	}
+	buf = append(buf, '\n')
+
+	_, err = os.Stdout.Write(buf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr,
+			"ERROR: Unable to write JSON to standard output: %s.\n", err)
+		os.Exit(7)
+	}
+}
+
+func writeAllFlows(fileContent, outDir, format string, opts gflowparser.Options) {
+	diagrams, fb, err := gflowparser.ConvertFlowFileDSL(fileContent, "standard input", opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr,
+			"ERROR: Unable to convert flows:\n%s", err)
+		os.Exit(3)
+	}
+	os.Stderr.WriteString(fb)
+
+	for _, d := range diagrams {
+		name := d.Name
+		if name == "" {
+			name = "flow"
+		}
+		fileName := filepath.Join(outDir, name+fileExtensions[format])
+		err = ioutil.WriteFile(fileName, d.Diagram, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr,
+				"ERROR: Unable to write diagram to file: %s.\n", err)
+			os.Exit(7)
+		}
+	}
+}
+
+func readSubflows(files []string) *gflowparser.Subflows {
+	subs := gflowparser.NewSubflows()
+	for _, file := range files {
+		var pkg string
+		if i := strings.IndexByte(file, '='); i >= 0 {
+			pkg, file = file[:i], file[i+1:]
+		} else {
+			abs, err := filepath.Abs(file)
+			if err != nil {
+				fmt.Fprintf(os.Stderr,
+					"ERROR: Unable to find directory of subflow file: %s.\n", err)
+				os.Exit(2)
+			}
+			pkg = filepath.Base(filepath.Dir(abs))
+		}
+		buf, err := ioutil.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr,
+				"ERROR: Unable to read subflow file: %s.\n", err)
+			os.Exit(2)
+		}
+		err = subs.AddFlowFileDSL(pkg, string(buf), file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr,
+				"ERROR: Unable to parse subflow file:\n%s", err)
+			os.Exit(3)
+		}
+	}
+	return subs
+}
+
+var fileExtensions = map[string]string{
+	"svg":      ".svg",
+	"text":     ".txt",
+	"ascii":    ".txt",
+	"mermaid":  ".mmd",
+	"plantuml": ".puml",
+	"drawio":   ".drawio",
+}
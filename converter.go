@@ -1,6 +1,9 @@
 package gflowparser
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/flowdev/gflowparser/data"
 	"github.com/flowdev/gflowparser/data2svg"
 	"github.com/flowdev/gflowparser/parser"
@@ -8,6 +11,86 @@ import (
 	"github.com/flowdev/gparselib"
 )
 
+// Layout is the engine used for laying out a flow diagram.
+type Layout int
+
+// Available layout engines.
+const (
+	// LayoutRows draws every flow line in its own row and connects the rows
+	// with splits and merges.
+	LayoutRows Layout = iota
+	// LayoutLayered works directly on the graph of the flow and arranges the
+	// components in layers (layered graph drawing).
+	LayoutLayered
+)
+
+// Format is the output format of a flow diagram.
+type Format int
+
+// Available output formats.
+const (
+	// FormatSVG creates a SVG image.
+	FormatSVG Format = iota
+	// FormatText creates text using Unicode box-drawing characters
+	// (e.g. for terminals).
+	FormatText
+	// FormatASCII creates text using plain ASCII characters only.
+	FormatASCII
+	// FormatMermaid creates a Mermaid flowchart (independent of the layout).
+	FormatMermaid
+	// FormatPlantUML creates a PlantUML component diagram (independent of
+	// the layout).
+	FormatPlantUML
+	// FormatDrawIO creates a draw.io diagram with the computed layout for
+	// polishing it manually.
+	FormatDrawIO
+)
+
+// Options configure the conversion of a flow into a diagram.
+// They select the layout engine and the output format (SVG, text, ASCII,
+// Mermaid, PlantUML or draw.io).
+// The zero value is the default configuration (a SVG image in rows layout).
+type Options struct {
+	Layout Layout
+	Format Format
+	// LayoutConfig contains the metrics used for drawing.
+	// If it is nil, svg.DefaultLayoutConfig() is used.
+	// It is ignored for text, Mermaid and PlantUML formats.
+	LayoutConfig *svg.LayoutConfig
+	// FlowName is the name of the flow to convert.
+	// It can be empty if the flow file contains only a single flow.
+	FlowName string
+	// SubflowMode tells how components implemented by other flows are drawn.
+	// It is ignored for Mermaid and PlantUML formats.
+	SubflowMode SubflowMode
+	// Subflows are flows of other files that can implement components.
+	// The flows of the converted file are always used, too.
+	Subflows *Subflows
+	// SubflowLink returns the link to the diagram of a subflow.
+	// If it is nil, links point to '<name>.svg' or '<pkg>/<name>.svg'.
+	SubflowLink func(pkg, name string) string
+	// JoinContinuations joins continued flow lines before drawing them, so
+	// the diagram looks as if the lines had never been wrapped.
+	// The layered layout, Mermaid and PlantUML always join them.
+	JoinContinuations bool
+	// Attributes are the keys of the component and arrow attributes that are
+	// shown in the diagram.
+	// If it is empty, no attributes are shown.
+	Attributes []string
+	// ReadFile reads imported flow files.
+	// If it is nil, ioutil.ReadFile is used.
+	ReadFile func(name string) ([]byte, error)
+}
+
+// FlowDiagram is the diagram of a single flow of a flow file plus its
+// component (subflow) and data types.
+type FlowDiagram struct {
+	Name      string
+	Diagram   []byte
+	CompTypes []data.Type
+	DataTypes []data.Type
+}
+
 // ConvertFlowDSLToSVG transforms a flow given as DSL string into a SVG image
 // plus component (subflow) types, data types, (currently empty) feedback
 // string and potential error(s).
@@ -19,33 +102,216 @@ func ConvertFlowDSLToSVG(flowContent, flowName string,
 	feedback string,
 	err error,
 ) {
-	pd := gparselib.NewParseData(flowName, flowContent)
-	pFlow, err := parser.NewFlowParser()
+	return ConvertFlowDSLToSVGWithOptions(flowContent, flowName, Options{})
+}
+
+// ConvertFlowDSLToSVGWithOptions works just like ConvertFlowDSLToSVG but
+// allows to configure the conversion.
+func ConvertFlowDSLToSVGWithOptions(flowContent, flowName string, opts Options,
+) (
+	svgData []byte,
+	compTypes []data.Type,
+	dataTypes []data.Type,
+	feedback string,
+	err error,
+) {
+	ff, src, fb, err := parseFlowFileDSL(flowContent, flowName, opts.ReadFile)
 	if err != nil {
 		return nil, nil, nil, "", err
 	}
-	pd, _ = pFlow.ParseFlow(pd, nil)
-
-	fb, err := parser.CheckFeedback(pd.Result)
+	flow, err := SelectFlow(ff, opts.FlowName)
 	if err != nil {
 		return nil, nil, nil, "", err
 	}
 
-	flow := pd.Result.Value.(data.Flow)
-
-	sf, err := data2svg.Convert(flow, pd.Source)
+	buf, err := flowToSVG(flow, src, opts, opts.Subflows.withFlowFile(ff, src))
 	if err != nil {
 		return nil, nil, nil, "", err
 	}
 	compTypes, dataTypes = extractTypes(flow)
 
-	//fmt.Fprintf(os.Stderr, "DEBUG: svgFlow=`%s`\n", spew.Sdump(sf))
-	buf, err := svg.FromFlowData(sf)
+	return buf, compTypes, dataTypes, fb, nil
+}
+
+// ConvertFlowFileDSL converts all flows of a flow file given as DSL string
+// into diagrams (in source order) plus (currently empty) feedback string and
+// potential error(s).
+// opts.FlowName is ignored.
+func ConvertFlowFileDSL(fileContent, fileName string, opts Options,
+) (diagrams []FlowDiagram, feedback string, err error) {
+	ff, src, fb, err := parseFlowFileDSL(fileContent, fileName, opts.ReadFile)
 	if err != nil {
-		return nil, nil, nil, "", err
+		return nil, "", err
 	}
 
-	return buf, compTypes, dataTypes, fb, nil
+	subs := opts.Subflows.withFlowFile(ff, src)
+	diagrams = make([]FlowDiagram, len(ff.Flows))
+	for i, flow := range ff.Flows {
+		buf, err := flowToSVG(flow, src, opts, subs)
+		if err != nil {
+			if flow.Name != "" {
+				err = fmt.Errorf("unable to convert flow '%s': %s", flow.Name, err)
+			}
+			return nil, "", err
+		}
+		compTypes, dataTypes := extractTypes(flow)
+		diagrams[i] = FlowDiagram{
+			Name:      flow.Name,
+			Diagram:   buf,
+			CompTypes: compTypes,
+			DataTypes: dataTypes,
+		}
+	}
+	return diagrams, fb, nil
+}
+
+// ParseFlowDSL parses a flow given as DSL string into its semantic
+// representation plus (currently empty) feedback string and potential
+// error(s).
+// If the DSL string contains multiple named flows, an error is returned.
+func ParseFlowDSL(flowContent, flowName string) (flow data.Flow, feedback string, err error) {
+	flow, _, feedback, err = parseFlowDSL(flowContent, flowName, "")
+	return flow, feedback, err
+}
+
+// ParseFlowFileDSL parses a flow file given as DSL string into its semantic
+// representation plus (currently empty) feedback string and potential
+// error(s).
+func ParseFlowFileDSL(fileContent, fileName string) (ff data.FlowFile, feedback string, err error) {
+	ff, _, feedback, err = parseFlowFileDSL(fileContent, fileName, nil)
+	return ff, feedback, err
+}
+
+func parseFlowDSL(flowContent, fileName, flowName string,
+) (flow data.Flow, src *data.FileSet, feedback string, err error) {
+	ff, src, fb, err := parseFlowFileDSL(flowContent, fileName, nil)
+	if err != nil {
+		return data.Flow{}, nil, "", err
+	}
+	flow, err = SelectFlow(ff, flowName)
+	if err != nil {
+		return data.Flow{}, nil, "", err
+	}
+	return flow, src, fb, nil
+}
+
+// parseFlowFileDSL parses the flow file and resolves all imports.
+// All source positions are global positions of the returned FileSet.
+func parseFlowFileDSL(fileContent, fileName string, readFile func(string) ([]byte, error),
+) (ff data.FlowFile, src *data.FileSet, feedback string, err error) {
+	pd := gparselib.NewParseData(fileName, fileContent)
+	pFlow, err := parser.NewFlowParser()
+	if err != nil {
+		return data.FlowFile{}, nil, "", err
+	}
+	pd, _ = pFlow.ParseAnyFlowFile(pd, nil)
+
+	fb, err := parser.CheckFeedback(pd.Result)
+	if err != nil {
+		return data.FlowFile{}, nil, "", err
+	}
+
+	src = data.NewFileSet()
+	src.AddFile(fileName, fileContent)
+	ff = pd.Result.Value.(data.FlowFile)
+	im := newImporter(readFile, src, fileName)
+	for i, flow := range ff.Flows {
+		ff.Flows[i], err = im.resolve(flow, fileName)
+		if err != nil {
+			return data.FlowFile{}, nil, "", err
+		}
+	}
+	return ff, src, fb, nil
+}
+
+// SelectFlow returns the flow with the given name from the flow file.
+// The name can be empty if the file contains only a single flow.
+func SelectFlow(ff data.FlowFile, name string) (data.Flow, error) {
+	if name == "" {
+		if len(ff.Flows) == 1 {
+			return ff.Flows[0], nil
+		}
+		return data.Flow{}, fmt.Errorf(
+			"the flow file contains %d flows, please choose one of: %s",
+			len(ff.Flows), strings.Join(ff.Names(), ", "))
+	}
+	flow, ok := ff.Flow(name)
+	if !ok {
+		return data.Flow{}, fmt.Errorf(
+			"unknown flow '%s', please choose one of: %s",
+			name, strings.Join(ff.Names(), ", "))
+	}
+	return flow, nil
+}
+
+func flowToSVG(flow data.Flow, wh data2svg.Whereer, opts Options, subs *Subflows,
+) ([]byte, error) {
+	cfg := svg.DefaultLayoutConfig()
+	if opts.LayoutConfig != nil {
+		cfg = *opts.LayoutConfig
+	}
+	flow = flow.FilterAttributes(opts.Attributes)
+	if opts.JoinContinuations {
+		flow = flow.JoinContinuations()
+	}
+	if opts.Format == FormatMermaid || opts.Format == FormatPlantUML {
+		g, err := data2svg.ConvertToGraphWithCircles(flow, wh)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Format == FormatMermaid {
+			return svg.MermaidFromGraphData(g)
+		}
+		return svg.PlantUMLFromGraphData(g)
+	}
+	switch opts.Layout {
+	case LayoutRows:
+		sf, err := data2svg.Convert(flow, wh)
+		if err != nil {
+			return nil, err
+		}
+		if opts.SubflowMode != SubflowNone {
+			err = newSubflowApplier(subs, opts, flow).applyToFlow(sf, flow, "")
+			if err != nil {
+				return nil, err
+			}
+		}
+		//fmt.Fprintf(os.Stderr, "DEBUG: svgFlow=`%s`\n", spew.Sdump(sf))
+		switch opts.Format {
+		case FormatSVG:
+			return svg.FromFlowDataWithConfig(sf, cfg)
+		case FormatDrawIO:
+			return svg.DrawIOFromFlowData(sf, cfg)
+		case FormatText:
+			return svg.TextFromFlowData(sf, svg.TextUnicode)
+		case FormatASCII:
+			return svg.TextFromFlowData(sf, svg.TextASCII)
+		}
+	case LayoutLayered:
+		g, err := data2svg.ConvertToGraph(flow, wh)
+		if err != nil {
+			return nil, err
+		}
+		if opts.SubflowMode != SubflowNone {
+			err = newSubflowApplier(subs, opts, flow).applyToGraph(g, flow)
+			if err != nil {
+				return nil, err
+			}
+		}
+		switch opts.Format {
+		case FormatSVG:
+			return svg.FromGraphDataWithConfig(g, cfg)
+		case FormatDrawIO:
+			return svg.DrawIOFromGraphData(g, cfg)
+		case FormatText:
+			return svg.TextFromGraphData(g, svg.TextUnicode)
+		case FormatASCII:
+			return svg.TextFromGraphData(g, svg.TextASCII)
+		}
+	default:
+		return nil, fmt.Errorf("unknown layout: %d", opts.Layout)
+	}
+	return nil, fmt.Errorf("unknown format: %d", opts.Format)
 }
 
 func extractTypes(flow data.Flow) (compTypes []data.Type, dataTypes []data.Type) {
@@ -95,16 +361,18 @@ func addTypes(typeMap map[string]data.Type, types []data.Type) map[string]data.T
 	return typeMap
 }
 func addType(typeMap map[string]data.Type, typ data.Type) map[string]data.Type {
-	if typ.ListType != nil {
-		return addType(typeMap, *typ.ListType)
-	}
-	if typ.MapKeyType != nil {
-		typeMap = addType(typeMap, *typ.MapKeyType)
-		return addType(typeMap, *typ.MapValueType)
-	}
-	typeMap[typToString(typ)] = typ
+	data.Inspect(typ, func(n data.Node) bool {
+		if t, ok := n.(data.Type); ok && t.Named() {
+			t.TypeArgs = nil // the type arguments are added on their own
+			typeMap[typToString(t)] = t
+		}
+		return true
+	})
 	return typeMap
 }
 func typToString(t data.Type) string {
+	if t.ImportPath != "" { // the package name alone can be ambiguous
+		return t.ImportPath + "." + t.LocalType
+	}
 	return t.Package + "." + t.LocalType
 }
@@ -1,13 +1,19 @@
 package gflowparser_test
 
 import (
+	"encoding/xml"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/flowdev/gflowparser"
 	"github.com/flowdev/gflowparser/data"
+	"github.com/flowdev/gflowparser/svg"
 )
 
 func TestConvertFlowDSLToSVG(t *testing.T) {
@@ -45,10 +51,10 @@ func TestConvertFlowDSLToSVG(t *testing.T) {
 </svg>
 `,
 			expectedCompTypes: []data.Type{
-				{LocalType: "a", SrcPos: 13},
+				{LocalType: "a", SrcPos: 13, SrcEnd: 14},
 			},
 			expectedDataTypes: []data.Type{
-				{LocalType: "data", SrcPos: 4},
+				{LocalType: "data", SrcPos: 4, SrcEnd: 8},
 			},
 			expectedFeedback: "",
 			expectedError:    ``,
@@ -112,3 +118,423 @@ Literal '[' expected.
 		}
 	}
 }
+
+func TestConvertFlowDSLToSVGWithOptions(t *testing.T) {
+	expText := `      (data)   +--+
+   in--------->|  +--->out
+               |a |
+               +--+
+`
+	for _, layout := range []gflowparser.Layout{gflowparser.LayoutRows, gflowparser.LayoutLayered} {
+		gotText, _, _, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(
+			"in (data)-> [a] -> out", "text",
+			gflowparser.Options{Layout: layout, Format: gflowparser.FormatASCII})
+		if err != nil {
+			t.Fatalf("Expected no error but got: %s", err)
+		}
+		if string(gotText) != expText {
+			t.Errorf("Expected text diagram for layout %d:\n%s\nbut got:\n%s",
+				layout, expText, gotText)
+		}
+	}
+
+	_, _, _, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(
+		"in (data)-> [a] -> out", "unknown format",
+		gflowparser.Options{Format: gflowparser.Format(-1)})
+	if err == nil {
+		t.Error("Expected an error for an unknown format but didn't get one.")
+	}
+}
+
+func TestConvertFlowFileDSL(t *testing.T) {
+	fileContent := "// all flows\n/* of a\n package */\n" +
+		"flow first {\n  in (data)-> [a] -> out\n}\n" +
+		"flow second {\n  in (data)-> [b] -> out\n}\n"
+	opts := gflowparser.Options{Format: gflowparser.FormatASCII}
+
+	diagrams, _, err := gflowparser.ConvertFlowFileDSL(fileContent, "file", opts)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if len(diagrams) != 2 || diagrams[0].Name != "first" || diagrams[1].Name != "second" {
+		t.Fatalf("Expected diagrams of flows 'first' and 'second' but got: %#v", diagrams)
+	}
+
+	opts.FlowName = "second"
+	gotText, _, _, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(fileContent, "file", opts)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if !reflect.DeepEqual(gotText, diagrams[1].Diagram) {
+		t.Errorf("Expected diagram of second flow:\n%s\nbut got:\n%s", diagrams[1].Diagram, gotText)
+	}
+
+	opts.FlowName = "third"
+	_, _, _, _, err = gflowparser.ConvertFlowDSLToSVGWithOptions(fileContent, "file", opts)
+	if err == nil {
+		t.Error("Expected an error for an unknown flow but didn't get one.")
+	}
+	_, _, err = gflowparser.ParseFlowDSL(fileContent, "file")
+	if err == nil {
+		t.Error("Expected an error for multiple flows without name but didn't get one.")
+	}
+
+	flow, _, err := gflowparser.ParseFlowDSL("flow (data)-> [a] -> out", "port")
+	if err != nil {
+		t.Fatalf("Expected no error for a port named 'flow' but got: %s", err)
+	}
+	if flow.Name != "" || len(flow.Parts) != 1 {
+		t.Errorf("Expected a single flow line without name but got: %#v", flow)
+	}
+}
+
+func TestConvertSubflows(t *testing.T) {
+	fileContent := "flow order {\n  in (order)-> [check CheckOrder] -> [store Store] -> out\n}\n" +
+		"flow checkOrder {\n  in (order)-> [validate] -> [check CheckOrder] -> out\n}\n"
+	subs := gflowparser.NewSubflows()
+	err := subs.AddFlowFileDSL("db", "in -> [write] -> out", "dir/db/store.flow")
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	opts := gflowparser.Options{
+		Format:      gflowparser.FormatASCII,
+		FlowName:    "order",
+		SubflowMode: gflowparser.SubflowExpand,
+	}
+
+	gotText, _, _, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(fileContent, "file", opts)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if strings.Count(string(gotText), "|validate ") != 1 || strings.Contains(string(gotText), "|write ") {
+		t.Errorf("Expected exactly one expanded subflow but got:\n%s", gotText)
+	}
+
+	opts.Subflows = subs
+	opts.Layout = gflowparser.LayoutLayered
+	gotText, _, _, _, err = gflowparser.ConvertFlowDSLToSVGWithOptions(
+		strings.Replace(fileContent, "Store", "db.Store", 1), "file", opts)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if !strings.Contains(string(gotText), "|write ") {
+		t.Errorf("Expected subflow of other package to be expanded but got:\n%s", gotText)
+	}
+
+	opts.Format = gflowparser.FormatSVG
+	opts.SubflowMode = gflowparser.SubflowLink
+	opts.SubflowLink = func(pkg, name string) string {
+		return "/flows/" + pkg + "/" + name + ".html"
+	}
+	gotSVG, _, _, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(
+		strings.Replace(fileContent, "Store", "db.Store", 1), "file", opts)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if !strings.Contains(string(gotSVG), `href="/flows//checkOrder.html"`) ||
+		!strings.Contains(string(gotSVG), `href="/flows/db/store.html"`) {
+		t.Errorf("Expected links to both subflows but got:\n%s", gotSVG)
+	}
+}
+
+func TestConvertAttributes(t *testing.T) {
+	flow := "in (url){async}-> [fetch HttpGet {timeout: \"5s\", owner: team-a}] -> out\n"
+	specs := []struct {
+		name             string
+		givenAttributes  []string
+		expectedTexts    []string
+		notExpectedTexts []string
+	}{
+		{
+			name:             "none",
+			notExpectedTexts: []string{"{", "async", "timeout", "owner"},
+		}, {
+			name:             "selected",
+			givenAttributes:  []string{"async", "timeout"},
+			expectedTexts:    []string{"{async}", "{timeout: 5s}"},
+			notExpectedTexts: []string{"owner"},
+		},
+	}
+	for _, spec := range specs {
+		t.Run(spec.name, func(t *testing.T) {
+			opts := gflowparser.Options{Format: gflowparser.FormatASCII, Attributes: spec.givenAttributes}
+			got, _, _, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(flow, "attrs.flow", opts)
+			if err != nil {
+				t.Fatalf("Expected no error but got: %s", err)
+			}
+			for _, txt := range spec.expectedTexts {
+				if !strings.Contains(string(got), txt) {
+					t.Errorf("Expected %q in diagram but got:\n%s", txt, got)
+				}
+			}
+			for _, txt := range spec.notExpectedTexts {
+				if strings.Contains(string(got), txt) {
+					t.Errorf("Didn't expect %q in diagram but got:\n%s", txt, got)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertRichTypes(t *testing.T) {
+	flow := "in (*[]Order, chan<- res.Result[Item], func(Order) error)-> [b *Buffer] -> out\n"
+	got, compTypes, dataTypes, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(
+		flow, "types.flow", gflowparser.Options{Format: gflowparser.FormatASCII})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	for _, txt := range []string{"(*[]Order, chan<- res.Result[Item], func(Order) error)", "*Buffer"} {
+		if !strings.Contains(string(got), txt) {
+			t.Errorf("Expected %q in diagram but got:\n%s", txt, got)
+		}
+	}
+	if len(compTypes) != 1 || compTypes[0].LocalType != "Buffer" {
+		t.Errorf("Expected component type Buffer but got: %v", compTypes)
+	}
+	var names []string
+	for _, typ := range dataTypes {
+		if len(typ.TypeArgs) > 0 {
+			t.Errorf("Expected no type arguments but got: %v", typ)
+		}
+		names = append(names, typ.Package+"."+typ.LocalType)
+	}
+	sort.Strings(names)
+	expected := []string{".Item", ".Order", ".error", "res.Result"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected data types %q but got %q.", expected, names)
+	}
+}
+
+func TestConvertPackages(t *testing.T) {
+	flow := "package o \"github.com/acme/order\"\n" +
+		"in (o.Order, \"github.com/acme/item/v2\".Item)-> [b] -> out\n"
+	got, _, dataTypes, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(
+		flow, "packages.flow", gflowparser.Options{Format: gflowparser.FormatASCII})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if !strings.Contains(string(got), "(o.Order, item.Item)") {
+		t.Errorf("Expected short package names in diagram but got:\n%s", got)
+	}
+	var paths []string
+	for _, typ := range dataTypes {
+		paths = append(paths, typ.ImportPath+"."+typ.LocalType)
+	}
+	sort.Strings(paths)
+	expected := []string{"github.com/acme/item/v2.Item", "github.com/acme/order.Order"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Expected data types %q but got %q.", expected, paths)
+	}
+}
+
+func TestConvertUnicode(t *testing.T) {
+	flow := "flow überweisen {\n  in (Größe)-> [bank.Überweisung] -> 出力\n}\n"
+	got, compTypes, _, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(
+		flow, "unicode.flow", gflowparser.Options{Format: gflowparser.FormatASCII})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	for _, txt := range []string{"(Größe)", "überweisung", "bank.Überweisung", "出力"} {
+		if !strings.Contains(string(got), txt) {
+			t.Errorf("Expected %q in diagram but got:\n%s", txt, got)
+		}
+	}
+	if len(compTypes) != 1 || compTypes[0].LocalType != "Überweisung" {
+		t.Errorf("Expected component type Überweisung but got: %v", compTypes)
+	}
+}
+
+func TestConvertContinuations(t *testing.T) {
+	flow := "in (order)-> [check] -> ...retryPath\n" +
+		"...retryPath (order)-> [retry] -> out\n"
+
+	opts := gflowparser.Options{Format: gflowparser.FormatASCII, JoinContinuations: true}
+	got, _, _, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(flow, "conts.flow", opts)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	expected, _, _, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(
+		"in (order)-> [check] (order)-> [retry] -> out\n", "unwrapped.flow", opts)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if string(got) != string(expected) {
+		t.Errorf("Expected joined flow lines:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	cfg := svg.DefaultLayoutConfig()
+	cfg.ContinuationMarkers = true
+	got, _, _, _, err = gflowparser.ConvertFlowDSLToSVGWithOptions(flow, "conts.flow",
+		gflowparser.Options{LayoutConfig: &cfg})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if n := strings.Count(string(got), "<circle"); n != 2 {
+		t.Errorf("Expected 2 connector circles but got %d in:\n%s", n, got)
+	}
+	if n := strings.Count(string(got), "<title>...retryPath</title>"); n != 2 {
+		t.Errorf("Expected 2 connector tooltips but got %d in:\n%s", n, got)
+	}
+}
+
+func TestConvertPortRanges(t *testing.T) {
+	opts := gflowparser.Options{Format: gflowparser.FormatASCII}
+	got, _, _, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(
+		"in (data)-> [split] arrayOut:1..3 (data)-> arrayIn:1..3 [worker] -> out\n",
+		"ranges.flow", opts)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	expected, _, _, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(
+		"in (data)-> [split] arrayOut:1 (data)-> arrayIn:1 [worker] -> out\n"+
+			"[split] arrayOut:2 (data)-> arrayIn:2 [worker]\n"+
+			"[split] arrayOut:3 (data)-> arrayIn:3 [worker]\n",
+		"expanded.flow", opts)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if string(got) != string(expected) {
+		t.Errorf("Expected expanded port ranges:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	_, _, _, _, err = gflowparser.ConvertFlowDSLToSVGWithOptions(
+		"[split] arrayOut:1..3 (data)-> arrayIn:1..2 [worker]\n", "mismatch.flow", opts)
+	if err == nil || !strings.Contains(err.Error(), "different lengths (3 and 2)") {
+		t.Errorf("Expected an error about different range lengths but got: %v", err)
+	}
+}
+
+func TestConvertWellFormedSVG(t *testing.T) {
+	specs := []struct {
+		name      string
+		givenFlow string
+	}{
+		{
+			name:      "channel types",
+			givenFlow: "in (*[]Order, chan<- res.Result[Item])-> [a] out (<-chan int)-> out\n",
+		}, {
+			name:      "attributes",
+			givenFlow: "in (x){cond: \"a < b && c\"}-> [a {note: \"say \\\"hi\\\"\"}] -> out\n",
+		},
+	}
+	for _, spec := range specs {
+		for _, layout := range []gflowparser.Layout{gflowparser.LayoutRows, gflowparser.LayoutLayered} {
+			got, _, _, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(
+				spec.givenFlow, spec.name,
+				gflowparser.Options{Layout: layout, Attributes: []string{"cond", "note"}})
+			if err != nil {
+				t.Fatalf("%s: Expected no error but got: %s", spec.name, err)
+			}
+			dec := xml.NewDecoder(strings.NewReader(string(got)))
+			for err == nil {
+				_, err = dec.Token()
+			}
+			if err != io.EOF {
+				t.Errorf("%s: Expected well-formed XML but got error: %s\n%s", spec.name, err, got)
+			}
+		}
+	}
+}
+
+func TestConvertPlantUMLAttributes(t *testing.T) {
+	flow := "in (x){cond: \"a<b>\\\\c\"}-> [a {note: \"<b>x</b>\\nline\"}] -> out\n"
+	opts := gflowparser.Options{Format: gflowparser.FormatPlantUML, Attributes: []string{"cond", "note"}}
+	got, _, _, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(flow, "attrs.flow", opts)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	for _, expected := range []string{
+		`component "a\n{note: &#60;b&#62;x&#60;/b&#62; line}" as c_a`,
+		`p0 --> c_a : (x){cond: a&#60;b&#62;&#92;c}`,
+	} {
+		if !strings.Contains(string(got), expected) {
+			t.Errorf("Expected %q in PlantUML diagram but got:\n%s", expected, got)
+		}
+	}
+}
+
+func TestImports(t *testing.T) {
+	files := map[string]string{
+		"common/errors.flow": "import \"log.flow\"\n[check] err(error)-> [handle] -> error\n",
+		"common/log.flow":    "[handle] log(string)-> [logger Logger]\n",
+		"cycle/a.flow":       "import \"b.flow\"\nin (x)-> [a] -> out\n",
+		"cycle/b.flow":       "import \"a.flow\"\nin (x)-> [b] -> out\n",
+		"bad/named.flow":     "flow named {\n  in (x)-> [a] -> out\n}\n",
+		"bad/decl.flow":      "in (x)-> [logger Other] -> out\n",
+		"diamond/b.flow":     "import \"d.flow\"\n[b] out(string)-> [logger]\n",
+		"diamond/c.flow":     "import \"d.flow\"\n[c] out(string)-> [logger]\n",
+		"diamond/d.flow":     "[logger Logger] log(string)-> [sink Sink]\n",
+	}
+	opts := gflowparser.Options{
+		Format: gflowparser.FormatASCII,
+		ReadFile: func(name string) ([]byte, error) {
+			content, ok := files[filepath.ToSlash(name)]
+			if !ok {
+				return nil, os.ErrNotExist
+			}
+			return []byte(content), nil
+		},
+	}
+	mainFlow := "import \"common/errors.flow\"\nin (order)-> [check] -> out\n"
+
+	gotText, _, _, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(mainFlow, "main.flow", opts)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	for _, comp := range []string{"|check ", "|handle ", "|logger "} {
+		if !strings.Contains(string(gotText), comp) {
+			t.Errorf("Expected component %q in diagram but got:\n%s", comp, gotText)
+		}
+	}
+
+	diamondFlow := "import \"diamond/b.flow\"\nimport \"diamond/c.flow\"\nin (x)-> [b]\n[c] -> out\n"
+	gotText, _, _, _, err = gflowparser.ConvertFlowDSLToSVGWithOptions(diamondFlow, "main.flow", opts)
+	if err != nil {
+		t.Fatalf("Expected no error for shared imports but got: %s", err)
+	}
+	for _, comp := range []string{"|b ", "|c ", "|logger ", "|sink "} {
+		if !strings.Contains(string(gotText), comp) {
+			t.Errorf("Expected component %q in diagram but got:\n%s", comp, gotText)
+		}
+	}
+
+	specs := []struct {
+		name            string
+		givenFlow       string
+		expectedInError []string
+	}{
+		{
+			name:            "cycle",
+			givenFlow:       "import \"cycle/a.flow\"\nin (x)-> [c] -> out\n",
+			expectedInError: []string{"import cycle: cycle/a.flow -> cycle/b.flow -> cycle/a.flow"},
+		}, {
+			name:            "missing file",
+			givenFlow:       "import \"nix.flow\"\nin (x)-> [c] -> out\n",
+			expectedInError: []string{"Unable to import 'nix.flow'", "File 'main.flow', line 1, column 1:"},
+		}, {
+			name:            "named flows",
+			givenFlow:       "import \"bad/named.flow\"\nin (x)-> [c] -> out\n",
+			expectedInError: []string{"flow declaration"},
+		}, {
+			name:      "error in imported node",
+			givenFlow: "import \"bad/decl.flow\"\nimport \"common/log.flow\"\nin (x)-> [handle] -> out\n",
+			expectedInError: []string{
+				"File 'bad/decl.flow', line 1, column 10:",
+				"File 'common/log.flow', line 1, column 24:",
+			},
+		},
+	}
+	for _, spec := range specs {
+		t.Run(spec.name, func(t *testing.T) {
+			_, _, _, _, err := gflowparser.ConvertFlowDSLToSVGWithOptions(spec.givenFlow, "main.flow", opts)
+			if err == nil {
+				t.Fatal("Expected an error but didn't get one.")
+			}
+			for _, s := range spec.expectedInError {
+				if !strings.Contains(err.Error(), s) {
+					t.Errorf("Expected %q in error but got:\n%s", s, err)
+				}
+			}
+		})
+	}
+}
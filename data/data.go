@@ -1,28 +1,180 @@
 package data
 
+import "strconv"
+
 // ContinuationSignal signals that a port is really part of a wrapped arrow.
 const ContinuationSignal = "..."
 
 // Flow is the semantic representation of a complete flow.
+// Flows declared with 'flow name { ... }' have got a name.
 type Flow struct {
-	Parts [][]interface{}
+	Name     string        `json:"name,omitempty"`
+	Imports  []Import      `json:"imports,omitempty"`
+	Packages []PackageDecl `json:"packages,omitempty"`
+	Parts    [][]Part      `json:"parts"`
+}
+
+// Import is the semantic representation of an import statement:
+// import "path/to/file.flow"
+// The path is relative to the importing file.
+// The lines of the imported flow are put in front of the lines of the
+// importing flow when the import is resolved.
+type Import struct {
+	Path   string `json:"path"`
+	SrcPos int    `json:"srcPos"`
+	SrcEnd int    `json:"srcEnd"`
+}
+
+// PackageDecl is the semantic representation of a package declaration:
+// package order "github.com/acme/order"
+// Types of the package (e.g. order.Order) carry its import path.
+// Without name the last element of the path is used.
+type PackageDecl struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	SrcPos int    `json:"srcPos"`
+	SrcEnd int    `json:"srcEnd"`
+}
+
+// FlowFile is the semantic representation of a complete flow file.
+// It contains all flows of the file in source order.
+// A file without flow declarations contains a single flow without name.
+type FlowFile struct {
+	Flows []Flow `json:"flows"`
+}
+
+// FilterAttributes returns a copy of the flow that keeps only the attributes
+// of components and arrows with one of the given keys.
+func (f Flow) FilterAttributes(keys []string) Flow {
+	nf := Flow{
+		Name: f.Name, Imports: f.Imports, Packages: f.Packages,
+		Parts: make([][]Part, len(f.Parts)),
+	}
+	for i, parts := range f.Parts {
+		nf.Parts[i] = make([]Part, len(parts))
+		for j, part := range parts {
+			switch p := part.(type) {
+			case Arrow:
+				p.Attrs = p.Attrs.Filter(keys)
+				part = p
+			case Component:
+				p.Attrs = p.Attrs.Filter(keys)
+				part = p
+			}
+			nf.Parts[i][j] = part
+		}
+	}
+	return nf
+}
+
+// JoinContinuations returns a copy of the flow in which every flow line that
+// ends with a continuation is joined with the line starting with the same
+// continuation.
+// The two arrows at the continuation become a single arrow, so the flow looks
+// as if its lines had never been wrapped.
+func (f Flow) JoinContinuations() Flow {
+	nf := Flow{
+		Name: f.Name, Imports: f.Imports, Packages: f.Packages,
+		Parts: make([][]Part, 0, len(f.Parts)),
+	}
+	ends := make(map[string]int) // joined lines by continuation at their end
+	addEnd := func(i int) {
+		line := nf.Parts[i]
+		if arr, ok := line[len(line)-1].(Arrow); ok && arr.ToPort != nil && arr.ToPort.Continuation() {
+			ends[arr.ToPort.ContinuationKey()] = i
+		}
+	}
+	for _, parts := range f.Parts {
+		if len(parts) == 0 {
+			continue
+		}
+		if arr, ok := parts[0].(Arrow); ok && arr.FromPort != nil && arr.FromPort.Continuation() {
+			if i, ok := ends[arr.FromPort.ContinuationKey()]; ok {
+				delete(ends, arr.FromPort.ContinuationKey())
+				line := nf.Parts[i]
+				nf.Parts[i] = joinLines(line[:len(line)-1], line[len(line)-1].(Arrow), arr, parts[1:])
+				addEnd(i)
+				continue
+			}
+		}
+		nf.Parts = append(nf.Parts, parts)
+		addEnd(len(nf.Parts) - 1)
+	}
+	return nf
+}
+
+func joinLines(head []Part, end, start Arrow, tail []Part) []Part {
+	arr := start
+	arr.FromPort = end.FromPort
+	if len(arr.Data) == 0 {
+		arr.Data = end.Data
+	}
+	if len(end.Attrs) > 0 {
+		arr.Attrs = append(append(Attributes{}, end.Attrs...), start.Attrs...)
+	}
+	arr.SrcPos = end.SrcPos
+
+	line := make([]Part, 0, len(head)+1+len(tail))
+	line = append(line, head...)
+	line = append(line, arr)
+	return append(line, tail...)
+}
+
+// Flow returns the flow with the given name.
+// If the file doesn't contain such a flow, ok is false.
+func (ff FlowFile) Flow(name string) (f Flow, ok bool) {
+	for _, f = range ff.Flows {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Flow{}, false
+}
+
+// Names returns the names of all flows of the file in source order.
+func (ff FlowFile) Names() []string {
+	names := make([]string, len(ff.Flows))
+	for i, f := range ff.Flows {
+		names[i] = f.Name
+	}
+	return names
 }
 
+// Part is a single part of a flow line.
+// It is implemented by Arrow and Component only.
+type Part interface {
+	Node
+	isPart()
+}
+
+func (Arrow) isPart()     {}
+func (Component) isPart() {}
+
 // Arrow is the semantic representation of a flow arrow including data type and
 // ports.
 type Arrow struct {
-	FromPort *Port
-	ToPort   *Port
-	Data     []Type
-	SrcPos   int
+	FromPort *Port      `json:"fromPort,omitempty"`
+	ToPort   *Port      `json:"toPort,omitempty"`
+	Data     []Type     `json:"data,omitempty"`
+	Attrs    Attributes `json:"attrs,omitempty"`
+	SrcPos   int        `json:"srcPos"`
+	SrcEnd   int        `json:"srcEnd"`
 }
 
 // Port is the semantic representation of a port.
+// Continuations are identified by their Index ('...1') or Label
+// ('...retryPath').
+// Port ranges ('out:1..4') from Index to IndexEnd are only returned by the
+// port parser; flows contain one port per index instead.
 type Port struct {
-	Name     string
-	HasIndex bool
-	Index    int
-	SrcPos   int
+	Name     string `json:"name"`
+	HasIndex bool   `json:"hasIndex,omitempty"`
+	Index    int    `json:"index,omitempty"`
+	HasRange bool   `json:"hasRange,omitempty"`
+	IndexEnd int    `json:"indexEnd,omitempty"`
+	Label    string `json:"label,omitempty"`
+	SrcPos   int    `json:"srcPos"`
+	SrcEnd   int    `json:"srcEnd"`
 }
 
 // Continuation tells if the port is really part of a wrapped arrow.
@@ -30,45 +182,144 @@ func (p *Port) Continuation() bool {
 	return p.Name == ContinuationSignal
 }
 
+// ContinuationKey returns the label or number that pairs the two ends of
+// a continuation (e.g. 'retryPath' or '1').
+func (p *Port) ContinuationKey() string {
+	if p.Label != "" {
+		return p.Label
+	}
+	return strconv.Itoa(p.Index)
+}
+
 // Component is the semantic representation of a component.
 type Component struct {
-	Decl    CompDecl
-	Plugins []Plugin
-	SrcPos  int
+	Decl    CompDecl   `json:"decl"`
+	Plugins []Plugin   `json:"plugins,omitempty"`
+	Attrs   Attributes `json:"attrs,omitempty"`
+	SrcPos  int        `json:"srcPos"`
+	SrcEnd  int        `json:"srcEnd"`
+}
+
+// Attribute is the semantic representation of a single key/value attribute
+// of a component or arrow, e.g.: timeout: "5s"
+// Flag attributes like 'async' have got an empty value.
+type Attribute struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	SrcPos int    `json:"srcPos"`
+	SrcEnd int    `json:"srcEnd"`
+}
+
+// Attributes are the attributes of a component or arrow in source order.
+// Keys are unique.
+type Attributes []Attribute
+
+// Get returns the value of the attribute with the given key.
+// If there is no such attribute, ok is false.
+func (as Attributes) Get(key string) (value string, ok bool) {
+	for _, a := range as {
+		if a.Key == key {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// Has tells if there is an attribute with the given key.
+func (as Attributes) Has(key string) bool {
+	_, ok := as.Get(key)
+	return ok
+}
+
+// Filter returns only the attributes with one of the given keys.
+// The source order is kept.
+func (as Attributes) Filter(keys []string) Attributes {
+	var fas Attributes
+	for _, a := range as {
+		for _, k := range keys {
+			if a.Key == k {
+				fas = append(fas, a)
+				break
+			}
+		}
+	}
+	return fas
 }
 
 // Plugin is the semantic representation of a component plugin.
 type Plugin struct {
-	Name   string
-	Types  []Type
-	SrcPos int
+	Name   string `json:"name,omitempty"`
+	Types  []Type `json:"types"`
+	SrcPos int    `json:"srcPos"`
+	SrcEnd int    `json:"srcEnd"`
 }
 
 // CompDecl is the semantic representation of an component declaration.
 type CompDecl struct {
-	Name      string
-	Type      Type
-	VagueType bool
-	SrcPos    int
+	Name      string `json:"name"`
+	Type      Type   `json:"type"`
+	VagueType bool   `json:"vagueType,omitempty"`
+	SrcPos    int    `json:"srcPos"`
+	SrcEnd    int    `json:"srcEnd"`
 }
 
 // Type is the semantic representation of a type declaration.
+// Only one kind of type is set:
+// - list(T): ListType
+// - map(K, V): MapKeyType and MapValueType
+// - *T: PointerType
+// - []T: SliceType
+// - [N]T: ArrayType and ArrayLen
+// - chan T, chan<- T and <-chan T: ChanType and ChanDir
+// - func(P1, P2) (R1, R2): Func, Params and Results
+// - pkg.T[A1, A2]: Package, LocalType and TypeArgs (for generic types)
+// The ImportPath of named types is known if it is part of the type
+// ("github.com/acme/order".Order) or if the package is declared.
 type Type struct {
-	ListType     *Type
-	MapKeyType   *Type
-	MapValueType *Type
-	Package      string
-	LocalType    string
-	SrcPos       int
+	ListType     *Type   `json:"listType,omitempty"`
+	MapKeyType   *Type   `json:"mapKeyType,omitempty"`
+	MapValueType *Type   `json:"mapValueType,omitempty"`
+	PointerType  *Type   `json:"pointerType,omitempty"`
+	SliceType    *Type   `json:"sliceType,omitempty"`
+	ArrayType    *Type   `json:"arrayType,omitempty"`
+	ArrayLen     int     `json:"arrayLen,omitempty"`
+	ChanType     *Type   `json:"chanType,omitempty"`
+	ChanDir      ChanDir `json:"chanDir,omitempty"`
+	Func         bool    `json:"func,omitempty"`
+	Params       []Type  `json:"params,omitempty"`
+	Results      []Type  `json:"results,omitempty"`
+	Package      string  `json:"package,omitempty"`
+	ImportPath   string  `json:"importPath,omitempty"`
+	LocalType    string  `json:"localType,omitempty"`
+	TypeArgs     []Type  `json:"typeArgs,omitempty"`
+	SrcPos       int     `json:"srcPos"`
+	SrcEnd       int     `json:"srcEnd"`
+}
+
+// ChanDir is the direction of a channel type.
+type ChanDir string
+
+// Directions of channel types.
+const (
+	ChanBoth ChanDir = ""     // chan T
+	ChanSend ChanDir = "send" // chan<- T
+	ChanRecv ChanDir = "recv" // <-chan T
+)
+
+// Named tells if the type is a (possibly generic) named type like pkg.T or
+// T[A] instead of a composite type like []T.
+func (t Type) Named() bool {
+	return t.LocalType != "" && !t.Separator()
 }
 
 // SeparatorType is a special (impossible) type to indicate a separator instead
 // of a real type.
 var SeparatorType = Type{
-	Package: "", LocalType: "<SEPARATOR>", SrcPos: -1,
+	Package: "", LocalType: "<SEPARATOR>", SrcPos: -1, SrcEnd: -1,
 }
 
 // Separator tells if the type is really a separator instead.
 func (t Type) Separator() bool {
-	return t == SeparatorType
+	return t.LocalType == SeparatorType.LocalType && t.Package == "" &&
+		t.SrcPos == SeparatorType.SrcPos && t.SrcEnd == SeparatorType.SrcEnd
 }
@@ -0,0 +1,74 @@
+package data_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/flowdev/gflowparser/data"
+)
+
+func TestJoinContinuations(t *testing.T) {
+	comp := func(name string) data.Component {
+		return data.Component{Decl: data.CompDecl{
+			Name: name, Type: data.Type{LocalType: name}, VagueType: true,
+		}}
+	}
+	cont := func(label string) *data.Port {
+		return &data.Port{Name: data.ContinuationSignal, Label: label}
+	}
+	flow := data.Flow{
+		Name: "f",
+		Parts: [][]data.Part{
+			{
+				data.Arrow{FromPort: &data.Port{Name: "in"}, SrcPos: 0},
+				comp("a"),
+				data.Arrow{
+					ToPort: cont("retry"),
+					Attrs:  data.Attributes{{Key: "async"}},
+					SrcPos: 10,
+				},
+			}, {
+				data.Arrow{FromPort: &data.Port{Name: "in2"}},
+				comp("c"),
+			}, {
+				data.Arrow{
+					FromPort: cont("retry"),
+					Data:     []data.Type{{LocalType: "d"}},
+					ToPort:   cont("again"),
+					SrcPos:   30,
+					SrcEnd:   40,
+				},
+			}, {
+				data.Arrow{FromPort: cont("again"), ToPort: &data.Port{Name: "in"}, SrcEnd: 50},
+				comp("b"),
+			},
+		},
+	}
+	expected := data.Flow{
+		Name: "f",
+		Parts: [][]data.Part{
+			{
+				data.Arrow{FromPort: &data.Port{Name: "in"}, SrcPos: 0},
+				comp("a"),
+				data.Arrow{
+					Data:   []data.Type{{LocalType: "d"}},
+					ToPort: &data.Port{Name: "in"},
+					Attrs:  data.Attributes{{Key: "async"}},
+					SrcPos: 10,
+					SrcEnd: 50,
+				},
+				comp("b"),
+			}, {
+				data.Arrow{FromPort: &data.Port{Name: "in2"}},
+				comp("c"),
+			},
+		},
+	}
+	got := flow.JoinContinuations()
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected flow:\n%#v\nbut got:\n%#v", expected, got)
+	}
+	if len(flow.Parts) != 4 || len(flow.Parts[0]) != 3 {
+		t.Errorf("Expected the original flow to be unchanged.")
+	}
+}
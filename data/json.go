@@ -0,0 +1,118 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Kinds of the parts of a flow in JSON.
+const (
+	kindArrow     = "arrow"
+	kindComponent = "component"
+)
+
+type jsonArrow struct {
+	Kind string `json:"kind"`
+	Arrow
+}
+
+type jsonComponent struct {
+	Kind string `json:"kind"`
+	Component
+}
+
+type jsonFlow struct {
+	Name     string              `json:"name,omitempty"`
+	Imports  []Import            `json:"imports,omitempty"`
+	Packages []PackageDecl       `json:"packages,omitempty"`
+	Parts    [][]json.RawMessage `json:"parts"`
+}
+
+// EncodeJSON returns the (indented) JSON encoding of a flow.
+// The encoding is described by the JSON Schema in the file
+// 'data/flow.schema.json'.
+func EncodeJSON(f Flow) ([]byte, error) {
+	return json.MarshalIndent(f, "", "  ")
+}
+
+// DecodeJSON returns the flow encoded in JSON by EncodeJSON.
+func DecodeJSON(buf []byte) (Flow, error) {
+	f := Flow{}
+	err := json.Unmarshal(buf, &f)
+	return f, err
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// Every part of the flow gets a 'kind' field ('arrow' or 'component') so it
+// can be decoded again.
+func (f Flow) MarshalJSON() ([]byte, error) {
+	jf := jsonFlow{
+		Name: f.Name, Imports: f.Imports, Packages: f.Packages,
+		Parts: make([][]json.RawMessage, len(f.Parts)),
+	}
+	for i, partLine := range f.Parts {
+		jf.Parts[i] = make([]json.RawMessage, len(partLine))
+		for j, part := range partLine {
+			var v interface{}
+			switch p := part.(type) {
+			case Arrow:
+				v = jsonArrow{Kind: kindArrow, Arrow: p}
+			case Component:
+				v = jsonComponent{Kind: kindComponent, Component: p}
+			default:
+				return nil, fmt.Errorf(
+					"unsupported part type %T at line index %d and part index %d",
+					part, i, j)
+			}
+			buf, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			jf.Parts[i][j] = buf
+		}
+	}
+	return json.Marshal(jf)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (f *Flow) UnmarshalJSON(buf []byte) error {
+	jf := jsonFlow{}
+	err := json.Unmarshal(buf, &jf)
+	if err != nil {
+		return err
+	}
+	f.Name = jf.Name
+	f.Imports = jf.Imports
+	f.Packages = jf.Packages
+	f.Parts = make([][]Part, len(jf.Parts))
+	for i, jLine := range jf.Parts {
+		f.Parts[i] = make([]Part, len(jLine))
+		for j, jPart := range jLine {
+			kind := struct {
+				Kind string `json:"kind"`
+			}{}
+			err = json.Unmarshal(jPart, &kind)
+			if err != nil {
+				return err
+			}
+			switch kind.Kind {
+			case kindArrow:
+				a := jsonArrow{}
+				err = json.Unmarshal(jPart, &a)
+				f.Parts[i][j] = a.Arrow
+			case kindComponent:
+				c := jsonComponent{}
+				err = json.Unmarshal(jPart, &c)
+				f.Parts[i][j] = c.Component
+			default:
+				return fmt.Errorf(
+					"unsupported part kind '%s' at line index %d and part index %d",
+					kind.Kind, i, j)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
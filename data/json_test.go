@@ -0,0 +1,123 @@
+package data_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/flowdev/gflowparser/data"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	listType := &data.Type{LocalType: "Item", SrcPos: 5}
+	flow := data.Flow{Parts: [][]data.Part{
+		{
+			data.Arrow{
+				FromPort: &data.Port{Name: "in", SrcPos: 0},
+				Data: []data.Type{
+					{ListType: listType, SrcPos: 4},
+					data.SeparatorType,
+					{Package: "pkg", LocalType: "Data", SrcPos: 12},
+				},
+				SrcPos: 0,
+			},
+			data.Component{
+				Decl: data.CompDecl{
+					Name: "a", Type: data.Type{LocalType: "a", SrcPos: 23},
+					VagueType: true, SrcPos: 23,
+				},
+				Plugins: []data.Plugin{{
+					Name:   "p",
+					Types:  []data.Type{{LocalType: "P", SrcPos: 29}},
+					SrcPos: 25,
+				}},
+				Attrs:  data.Attributes{{Key: "timeout", Value: "5s", SrcPos: 31}},
+				SrcPos: 22,
+			},
+			data.Arrow{
+				FromPort: &data.Port{Name: "out", HasIndex: true, Index: 1, SrcPos: 33},
+				ToPort:   &data.Port{Name: data.ContinuationSignal, Label: "retry", SrcPos: 42},
+				Attrs:    data.Attributes{{Key: "async", SrcPos: 38}},
+				SrcPos:   33,
+			},
+		},
+		{},
+	}}
+
+	buf, err := data.EncodeJSON(flow)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if !strings.Contains(string(buf), `"kind": "component"`) {
+		t.Errorf("Expected kind of component in JSON but got:\n%s", buf)
+	}
+	got, err := data.DecodeJSON(buf)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if !reflect.DeepEqual(got, flow) {
+		t.Errorf("Expected flow:\n%#v\nbut got:\n%#v", flow, got)
+	}
+	if !got.Parts[0][0].(data.Arrow).Data[1].Separator() {
+		t.Error("Expected separator type to survive the round trip.")
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	_, err := data.EncodeJSON(data.Flow{Parts: [][]data.Part{{nil}}})
+	if err == nil {
+		t.Error("Expected an error for a missing part but didn't get one.")
+	}
+	_, err = data.DecodeJSON([]byte(`{"parts": [[{"kind": "wrong"}]]}`))
+	if err == nil {
+		t.Error("Expected an error for an unsupported part kind but didn't get one.")
+	}
+	_, err = data.DecodeJSON([]byte(`{"parts": [[{"kind": "arrow", "srcPos": "x"}]]}`))
+	if err == nil {
+		t.Error("Expected an error for an invalid arrow but didn't get one.")
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	buf, err := ioutil.ReadFile("flow.schema.json")
+	if err != nil {
+		t.Fatalf("Expected to read the schema but got: %s", err)
+	}
+	schema := struct {
+		Definitions map[string]struct {
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"definitions"`
+	}{}
+	err = json.Unmarshal(buf, &schema)
+	if err != nil {
+		t.Fatalf("Expected valid JSON schema but got: %s", err)
+	}
+
+	// all fields of the encoding have to be described by the schema
+	fields := map[string]interface{}{
+		"arrow":       data.Arrow{},
+		"port":        data.Port{},
+		"component":   data.Component{},
+		"compDecl":    data.CompDecl{},
+		"plugin":      data.Plugin{},
+		"type":        data.Type{},
+		"attribute":   data.Attribute{},
+		"packageDecl": data.PackageDecl{},
+	}
+	for name, v := range fields {
+		def, ok := schema.Definitions[name]
+		if !ok {
+			t.Errorf("Expected definition %q in schema.", name)
+			continue
+		}
+		typ := reflect.TypeOf(v)
+		for i := 0; i < typ.NumField(); i++ {
+			tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+			if _, ok := def.Properties[tag]; !ok {
+				t.Errorf("Expected property %q of definition %q in schema.", tag, name)
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package data
+
+import (
+	"path"
+	"strings"
+)
+
+// PackageName returns the default name of the package with the given import
+// path: the last element of the path without a major version suffix
+// (e.g. 'order' for 'github.com/acme/order/v2').
+func PackageName(importPath string) string {
+	name := path.Base(importPath)
+	if isMajorVersion(name) {
+		if dir := path.Dir(importPath); dir != "." {
+			name = path.Base(dir)
+		}
+	}
+	return name
+}
+
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	return strings.Trim(s[1:], "0123456789") == ""
+}
+
+// ResolvePackages returns a copy of the flow in which all types of declared
+// packages carry the import path of their package.
+// Types with an import path get the name of the declared package with the
+// same path.
+func (f Flow) ResolvePackages() Flow {
+	if len(f.Packages) == 0 {
+		return f
+	}
+	r := packageResolver{
+		paths: make(map[string]string, len(f.Packages)),
+		names: make(map[string]string, len(f.Packages)),
+	}
+	for _, pkg := range f.Packages {
+		r.paths[pkg.Name] = pkg.Path
+		r.names[pkg.Path] = pkg.Name
+	}
+
+	nf := Flow{
+		Name: f.Name, Imports: f.Imports, Packages: f.Packages,
+		Parts: make([][]Part, len(f.Parts)),
+	}
+	for i, parts := range f.Parts {
+		nf.Parts[i] = make([]Part, len(parts))
+		for j, part := range parts {
+			switch p := part.(type) {
+			case Arrow:
+				p.Data = r.types(p.Data)
+				part = p
+			case Component:
+				p.Decl.Type = r.typ(p.Decl.Type)
+				plugins := make([]Plugin, len(p.Plugins))
+				for k, plugin := range p.Plugins {
+					plugin.Types = r.types(plugin.Types)
+					plugins[k] = plugin
+				}
+				if p.Plugins != nil {
+					p.Plugins = plugins
+				}
+				part = p
+			}
+			nf.Parts[i][j] = part
+		}
+	}
+	return nf
+}
+
+type packageResolver struct {
+	paths map[string]string // import paths by package name
+	names map[string]string // package names by import path
+}
+
+func (r packageResolver) types(types []Type) []Type {
+	if types == nil {
+		return nil
+	}
+	nts := make([]Type, len(types))
+	for i, t := range types {
+		nts[i] = r.typ(t)
+	}
+	return nts
+}
+
+func (r packageResolver) typ(t Type) Type {
+	if t.Separator() {
+		return t
+	}
+	for _, st := range []**Type{
+		&t.ListType, &t.MapKeyType, &t.MapValueType,
+		&t.PointerType, &t.SliceType, &t.ArrayType, &t.ChanType,
+	} {
+		if *st != nil {
+			nt := r.typ(**st)
+			*st = &nt
+		}
+	}
+	t.Params = r.types(t.Params)
+	t.Results = r.types(t.Results)
+	t.TypeArgs = r.types(t.TypeArgs)
+	switch {
+	case t.ImportPath != "":
+		if name, ok := r.names[t.ImportPath]; ok {
+			t.Package = name
+		}
+	case t.Package != "":
+		t.ImportPath = r.paths[t.Package]
+	}
+	return t
+}
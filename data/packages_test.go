@@ -0,0 +1,77 @@
+package data_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/flowdev/gflowparser/data"
+)
+
+func TestPackageName(t *testing.T) {
+	specs := map[string]string{
+		"order":                     "order",
+		"github.com/acme/order":     "order",
+		"github.com/acme/order/v2":  "order",
+		"github.com/acme/vendor":    "vendor",
+		"v3":                        "v3",
+		"github.com/acme/order/v2x": "v2x",
+	}
+	for given, expected := range specs {
+		if got := data.PackageName(given); got != expected {
+			t.Errorf("Expected package name %q for %q but got %q.", expected, given, got)
+		}
+	}
+}
+
+func TestResolvePackages(t *testing.T) {
+	flow := data.Flow{
+		Packages: []data.PackageDecl{
+			{Name: "order", Path: "github.com/acme/order"},
+			{Name: "ord2", Path: "github.com/acme/order/v2"},
+		},
+		Parts: [][]data.Part{{
+			data.Arrow{
+				FromPort: &data.Port{Name: "in"},
+				Data: []data.Type{
+					{SliceType: &data.Type{Package: "order", LocalType: "Order"}},
+					data.SeparatorType,
+					{Package: "order", ImportPath: "github.com/acme/order/v2", LocalType: "Item"},
+				},
+			},
+			data.Component{
+				Decl: data.CompDecl{Name: "c", Type: data.Type{Package: "other", LocalType: "C"}},
+				Plugins: []data.Plugin{{
+					Types: []data.Type{{LocalType: "Res", TypeArgs: []data.Type{{Package: "order", LocalType: "Order"}}}},
+				}},
+			},
+		}},
+	}
+	expected := data.Flow{
+		Packages: flow.Packages,
+		Parts: [][]data.Part{{
+			data.Arrow{
+				FromPort: &data.Port{Name: "in"},
+				Data: []data.Type{
+					{SliceType: &data.Type{Package: "order", ImportPath: "github.com/acme/order", LocalType: "Order"}},
+					data.SeparatorType,
+					{Package: "ord2", ImportPath: "github.com/acme/order/v2", LocalType: "Item"},
+				},
+			},
+			data.Component{
+				Decl: data.CompDecl{Name: "c", Type: data.Type{Package: "other", LocalType: "C"}},
+				Plugins: []data.Plugin{{
+					Types: []data.Type{{LocalType: "Res", TypeArgs: []data.Type{
+						{Package: "order", ImportPath: "github.com/acme/order", LocalType: "Order"},
+					}}},
+				}},
+			},
+		}},
+	}
+	got := flow.ResolvePackages()
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected flow:\n%#v\nbut got:\n%#v", expected, got)
+	}
+	if flow.Parts[0][0].(data.Arrow).Data[0].SliceType.ImportPath != "" {
+		t.Errorf("Expected the original flow to be unchanged.")
+	}
+}
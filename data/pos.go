@@ -0,0 +1,391 @@
+package data
+
+import (
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Span is the range of byte offsets [Start, End) in the source of a flow
+// that a node has been parsed from.
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Len returns the length of the span in bytes.
+func (s Span) Len() int {
+	return s.End - s.Start
+}
+
+// Contains tells if the offset is inside of the span.
+func (s Span) Contains(offset int) bool {
+	return s.Start <= offset && offset < s.End
+}
+
+// Span returns the source range of the whole flow.
+// It is empty if the flow has got no parts.
+func (f Flow) Span() Span {
+	return partsSpan(f.Parts)
+}
+
+// Span returns the source range of all parts of the line.
+// It is empty if the line has got no parts.
+func (l Line) Span() Span {
+	return partsSpan([][]Part{l.Parts})
+}
+
+func partsSpan(lines [][]Part) Span {
+	s := Span{}
+	first := true
+	for _, parts := range lines {
+		for _, p := range parts {
+			if p == nil {
+				continue
+			}
+			ps := p.Span()
+			if first {
+				s = ps
+				first = false
+				continue
+			}
+			if ps.Start < s.Start {
+				s.Start = ps.Start
+			}
+			if ps.End > s.End {
+				s.End = ps.End
+			}
+		}
+	}
+	return s
+}
+
+// Span returns the source range of the arrow.
+func (a Arrow) Span() Span { return Span{Start: a.SrcPos, End: a.SrcEnd} }
+
+// Span returns the source range of the port.
+func (p Port) Span() Span { return Span{Start: p.SrcPos, End: p.SrcEnd} }
+
+// Span returns the source range of the component.
+func (c Component) Span() Span { return Span{Start: c.SrcPos, End: c.SrcEnd} }
+
+// Span returns the source range of the component declaration.
+func (d CompDecl) Span() Span { return Span{Start: d.SrcPos, End: d.SrcEnd} }
+
+// Span returns the source range of the plugin.
+func (p Plugin) Span() Span { return Span{Start: p.SrcPos, End: p.SrcEnd} }
+
+// Span returns the source range of the type.
+func (t Type) Span() Span { return Span{Start: t.SrcPos, End: t.SrcEnd} }
+
+// Span returns the source range of the attribute.
+func (a Attribute) Span() Span { return Span{Start: a.SrcPos, End: a.SrcEnd} }
+
+// Span returns the source range of the import statement.
+func (i Import) Span() Span { return Span{Start: i.SrcPos, End: i.SrcEnd} }
+
+// Span returns the source range of the package declaration.
+func (d PackageDecl) Span() Span { return Span{Start: d.SrcPos, End: d.SrcEnd} }
+
+// Shifted returns a copy of the flow with all source positions moved by
+// delta.
+// It is used for moving a flow into the offset range of its file in a
+// FileSet.
+func (f Flow) Shifted(delta int) Flow {
+	nf := Flow{Name: f.Name, Parts: make([][]Part, len(f.Parts))}
+	if f.Imports != nil {
+		nf.Imports = make([]Import, len(f.Imports))
+		for i, imp := range f.Imports {
+			imp.SrcPos += delta
+			imp.SrcEnd += delta
+			nf.Imports[i] = imp
+		}
+	}
+	if f.Packages != nil {
+		nf.Packages = make([]PackageDecl, len(f.Packages))
+		for i, pkg := range f.Packages {
+			pkg.SrcPos += delta
+			pkg.SrcEnd += delta
+			nf.Packages[i] = pkg
+		}
+	}
+	for i, parts := range f.Parts {
+		nf.Parts[i] = make([]Part, len(parts))
+		for j, part := range parts {
+			switch p := part.(type) {
+			case Arrow:
+				p.FromPort = shiftPort(p.FromPort, delta)
+				p.Data = shiftTypes(p.Data, delta)
+				p.ToPort = shiftPort(p.ToPort, delta)
+				p.Attrs = shiftAttrs(p.Attrs, delta)
+				p.SrcPos += delta
+				p.SrcEnd += delta
+				part = p
+			case Component:
+				p.Decl.Type = shiftType(p.Decl.Type, delta)
+				p.Decl.SrcPos += delta
+				p.Decl.SrcEnd += delta
+				plugins := make([]Plugin, len(p.Plugins))
+				for k, plugin := range p.Plugins {
+					plugin.Types = shiftTypes(plugin.Types, delta)
+					plugin.SrcPos += delta
+					plugin.SrcEnd += delta
+					plugins[k] = plugin
+				}
+				if p.Plugins != nil {
+					p.Plugins = plugins
+				}
+				p.Attrs = shiftAttrs(p.Attrs, delta)
+				p.SrcPos += delta
+				p.SrcEnd += delta
+				part = p
+			}
+			nf.Parts[i][j] = part
+		}
+	}
+	return nf
+}
+
+func shiftPort(p *Port, delta int) *Port {
+	if p == nil {
+		return nil
+	}
+	np := *p
+	np.SrcPos += delta
+	np.SrcEnd += delta
+	return &np
+}
+
+func shiftAttrs(attrs Attributes, delta int) Attributes {
+	if attrs == nil {
+		return nil
+	}
+	nattrs := make(Attributes, len(attrs))
+	for i, a := range attrs {
+		a.SrcPos += delta
+		a.SrcEnd += delta
+		nattrs[i] = a
+	}
+	return nattrs
+}
+
+func shiftTypes(types []Type, delta int) []Type {
+	if types == nil {
+		return nil
+	}
+	nts := make([]Type, len(types))
+	for i, t := range types {
+		nts[i] = shiftType(t, delta)
+	}
+	return nts
+}
+
+func shiftType(t Type, delta int) Type {
+	if t.Separator() {
+		return t
+	}
+	for _, st := range []**Type{
+		&t.ListType, &t.MapKeyType, &t.MapValueType,
+		&t.PointerType, &t.SliceType, &t.ArrayType, &t.ChanType,
+	} {
+		if *st != nil {
+			nt := shiftType(**st, delta)
+			*st = &nt
+		}
+	}
+	t.Params = shiftTypes(t.Params, delta)
+	t.Results = shiftTypes(t.Results, delta)
+	t.TypeArgs = shiftTypes(t.TypeArgs, delta)
+	t.SrcPos += delta
+	t.SrcEnd += delta
+	return t
+}
+
+// Position is a human readable source position.
+// Line and Column start at 1. The column counts runes (not bytes).
+type Position struct {
+	Filename string `json:"filename,omitempty"`
+	Offset   int    `json:"offset"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+// String returns the position in the usual 'file:line:column' form.
+func (p Position) String() string {
+	s := p.Filename
+	if s != "" {
+		s += ":"
+	}
+	return s + strconv.Itoa(p.Line) + ":" + strconv.Itoa(p.Column)
+}
+
+// File maps byte offsets of a single source file to positions.
+type File struct {
+	name       string
+	base       int
+	content    string
+	lineStarts []int
+}
+
+// NewFile creates a new File for the given name and content.
+func NewFile(name, content string) *File {
+	f := &File{name: name, content: content, lineStarts: []int{0}}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			f.lineStarts = append(f.lineStarts, i+1)
+		}
+	}
+	return f
+}
+
+// Name returns the name of the file.
+func (f *File) Name() string {
+	return f.name
+}
+
+// Base returns the offset of the start of the file in its FileSet.
+// It is 0 for files that aren't part of a FileSet.
+func (f *File) Base() int {
+	return f.base
+}
+
+// Size returns the length of the file in bytes.
+func (f *File) Size() int {
+	return len(f.content)
+}
+
+// LineCount returns the number of lines of the file.
+func (f *File) LineCount() int {
+	return len(f.lineStarts)
+}
+
+// Position returns the position of the byte offset in the file.
+// Offsets outside of the file are clamped to its start or end.
+func (f *File) Position(offset int) Position {
+	if offset < 0 {
+		offset = 0
+	} else if offset > len(f.content) {
+		offset = len(f.content)
+	}
+	i := sort.Search(len(f.lineStarts), func(i int) bool {
+		return f.lineStarts[i] > offset
+	}) - 1
+	return Position{
+		Filename: f.name,
+		Offset:   offset,
+		Line:     i + 1,
+		Column:   utf8.RuneCountInString(f.content[f.lineStarts[i]:offset]) + 1,
+	}
+}
+
+// SpanPositions returns the start and end positions of the span in the
+// file.
+func (f *File) SpanPositions(s Span) (start, end Position) {
+	return f.Position(s.Start), f.Position(s.End)
+}
+
+// Where describes the given byte offset in a human readable way including
+// the source line.
+// So a File can be used everywhere a gparselib.SourceData is used for this.
+func (f *File) Where(offset int) string {
+	p := f.Position(offset)
+	start := f.lineStarts[p.Line-1]
+	end := len(f.content)
+	if p.Line < len(f.lineStarts) {
+		end = f.lineStarts[p.Line] - 1
+	}
+	return "File '" + f.name + "', line " + strconv.Itoa(p.Line) +
+		", column " + strconv.Itoa(p.Column) + ":\n" + f.content[start:end] + "\n"
+}
+
+// FileSet is a set of source files.
+// Every file gets its own range of global positions starting at its base
+// (just like go/token.FileSet).
+// So a global position identifies the file and the offset in it.
+// The first file has got the base 0, so its positions and offsets are the
+// same.
+type FileSet struct {
+	files []*File
+	next  int
+}
+
+// NewFileSet creates a new, empty FileSet.
+func NewFileSet() *FileSet {
+	return &FileSet{}
+}
+
+// AddFile adds a new file to the set and returns it.
+// The file gets the next free range of global positions.
+// A file with the same name is replaced (the positions of the replaced file
+// aren't valid anymore).
+func (s *FileSet) AddFile(name, content string) *File {
+	f := NewFile(name, content)
+	f.base = s.next
+	s.next += len(content) + 1
+	for i, g := range s.files {
+		if g.name == name {
+			s.files[i] = f
+			return f
+		}
+	}
+	s.files = append(s.files, f)
+	return f
+}
+
+// File returns the file with the given name or nil if it isn't in the set.
+func (s *FileSet) File(name string) *File {
+	for _, f := range s.files {
+		if f.name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Files returns all files of the set in the order they have been added.
+func (s *FileSet) Files() []*File {
+	return s.files
+}
+
+// FileAt returns the file containing the global position or nil if no
+// file of the set contains it.
+func (s *FileSet) FileAt(pos int) *File {
+	for _, f := range s.files {
+		if pos >= f.base && pos <= f.base+len(f.content) {
+			return f
+		}
+	}
+	return nil
+}
+
+// PositionAt returns the position of the global position.
+// The position is invalid (Line == 0) if no file of the set contains it.
+func (s *FileSet) PositionAt(pos int) Position {
+	f := s.FileAt(pos)
+	if f == nil {
+		return Position{Offset: pos}
+	}
+	return f.Position(pos - f.base)
+}
+
+// Where describes the global position in a human readable way including the
+// source line just like File.Where.
+// So a FileSet can be used everywhere a gparselib.SourceData is used for
+// this.
+func (s *FileSet) Where(pos int) string {
+	f := s.FileAt(pos)
+	if f == nil {
+		return "Unknown position " + strconv.Itoa(pos) + ":\n"
+	}
+	return f.Where(pos - f.base)
+}
+
+// Position returns the position of the byte offset in the named file.
+// The position is invalid (Line == 0) if the file isn't in the set.
+func (s *FileSet) Position(name string, offset int) Position {
+	f := s.File(name)
+	if f == nil {
+		return Position{Filename: name, Offset: offset}
+	}
+	return f.Position(offset)
+}
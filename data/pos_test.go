@@ -0,0 +1,156 @@
+package data_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/gflowparser/data"
+)
+
+func TestFilePosition(t *testing.T) {
+	f := data.NewFile("test.flow", "in (Ä)-> [a]\n  [ö] -> out\n")
+	specs := []struct {
+		name           string
+		givenOffset    int
+		expectedString string
+	}{
+		{name: "start", givenOffset: 0, expectedString: "test.flow:1:1"},
+		{name: "behind umlaut", givenOffset: 6, expectedString: "test.flow:1:6"},
+		{name: "line end", givenOffset: 13, expectedString: "test.flow:1:13"},
+		{name: "second line", givenOffset: 16, expectedString: "test.flow:2:3"},
+		{name: "behind second umlaut", givenOffset: 19, expectedString: "test.flow:2:5"},
+		{name: "negative", givenOffset: -3, expectedString: "test.flow:1:1"},
+		{name: "too big", givenOffset: 300, expectedString: "test.flow:3:1"},
+	}
+	for _, spec := range specs {
+		t.Run(spec.name, func(t *testing.T) {
+			got := f.Position(spec.givenOffset).String()
+			if got != spec.expectedString {
+				t.Errorf("Expected position %q but got %q.", spec.expectedString, got)
+			}
+		})
+	}
+
+	where := f.Where(16)
+	expectedWhere := "File 'test.flow', line 2, column 3:\n  [ö] -> out\n"
+	if where != expectedWhere {
+		t.Errorf("Expected where %q but got %q.", expectedWhere, where)
+	}
+	if f.LineCount() != 3 {
+		t.Errorf("Expected 3 lines but got %d.", f.LineCount())
+	}
+}
+
+func TestFileSet(t *testing.T) {
+	fs := data.NewFileSet()
+	fs.AddFile("a.flow", "a\nb")
+	fs.AddFile("b.flow", "xyz")
+	fs.AddFile("a.flow", "\n\nc")
+
+	if len(fs.Files()) != 2 {
+		t.Fatalf("Expected 2 files but got %d.", len(fs.Files()))
+	}
+	if got := fs.Position("a.flow", 2).String(); got != "a.flow:3:1" {
+		t.Errorf("Expected position of replaced file but got %q.", got)
+	}
+	if got := fs.Position("b.flow", 2).String(); got != "b.flow:1:3" {
+		t.Errorf("Expected position in second file but got %q.", got)
+	}
+	if got := fs.Position("c.flow", 2); got.Line != 0 {
+		t.Errorf("Expected invalid position for unknown file but got %q.", got)
+	}
+}
+
+func TestSpan(t *testing.T) {
+	flow := data.Flow{Parts: [][]data.Part{
+		{
+			data.Arrow{SrcPos: 0, SrcEnd: 10},
+			data.Component{SrcPos: 10, SrcEnd: 15},
+		},
+		{},
+		{
+			data.Arrow{SrcPos: 20, SrcEnd: 27},
+		},
+	}}
+
+	expected := data.Span{Start: 0, End: 27}
+	if got := flow.Span(); got != expected {
+		t.Errorf("Expected flow span %v but got %v.", expected, got)
+	}
+	expected = data.Span{Start: 0, End: 15}
+	if got := (data.Line{Parts: flow.Parts[0]}).Span(); got != expected {
+		t.Errorf("Expected line span %v but got %v.", expected, got)
+	}
+	if got := (data.Line{}).Span(); got != (data.Span{}) {
+		t.Errorf("Expected empty span for empty line but got %v.", got)
+	}
+	s := data.Span{Start: 3, End: 5}
+	if s.Len() != 2 || !s.Contains(4) || s.Contains(5) {
+		t.Errorf("Expected length 2 and to contain only 3 and 4 for span %v.", s)
+	}
+}
+
+func TestFileSetGlobalPositions(t *testing.T) {
+	fs := data.NewFileSet()
+	a := fs.AddFile("a.flow", "ab\ncd")
+	b := fs.AddFile("b.flow", "xyz")
+
+	if a.Base() != 0 || b.Base() != 6 {
+		t.Fatalf("Expected bases 0 and 6 but got %d and %d.", a.Base(), b.Base())
+	}
+	specs := []struct {
+		givenPos       int
+		expectedString string
+	}{
+		{givenPos: 4, expectedString: "a.flow:2:2"},
+		{givenPos: 5, expectedString: "a.flow:2:3"},
+		{givenPos: 6, expectedString: "b.flow:1:1"},
+		{givenPos: 8, expectedString: "b.flow:1:3"},
+		{givenPos: 10, expectedString: "0:0"},
+	}
+	for _, spec := range specs {
+		if got := fs.PositionAt(spec.givenPos).String(); got != spec.expectedString {
+			t.Errorf("Expected position %q for %d but got %q.", spec.expectedString, spec.givenPos, got)
+		}
+	}
+	if got := fs.Where(7); got != "File 'b.flow', line 1, column 2:\nxyz\n" {
+		t.Errorf("Expected where of second file but got %q.", got)
+	}
+}
+
+func TestFlowShifted(t *testing.T) {
+	flow := data.Flow{
+		Imports: []data.Import{{Path: "x.flow", SrcPos: 0, SrcEnd: 15}},
+		Parts: [][]data.Part{{
+			data.Arrow{
+				FromPort: &data.Port{Name: "in", SrcPos: 16, SrcEnd: 18},
+				Data: []data.Type{
+					{ListType: &data.Type{LocalType: "a", SrcPos: 24, SrcEnd: 25}, SrcPos: 19, SrcEnd: 26},
+					data.SeparatorType,
+				},
+				SrcPos: 16, SrcEnd: 29,
+			},
+			data.Component{
+				Decl: data.CompDecl{
+					Name:   "b",
+					Type:   data.Type{LocalType: "B", SrcPos: 32, SrcEnd: 33},
+					SrcPos: 30, SrcEnd: 33,
+				},
+				Plugins: []data.Plugin{{Types: []data.Type{{LocalType: "c", SrcPos: 36, SrcEnd: 37}}, SrcPos: 34, SrcEnd: 37}},
+				SrcPos:  29, SrcEnd: 38,
+			},
+		}},
+	}
+
+	got := flow.Shifted(100)
+	arrow := got.Parts[0][0].(data.Arrow)
+	comp := got.Parts[0][1].(data.Component)
+	if got.Imports[0].SrcPos != 100 || arrow.FromPort.SrcPos != 116 ||
+		arrow.Data[0].ListType.SrcEnd != 125 || !arrow.Data[1].Separator() ||
+		comp.Decl.Type.SrcPos != 132 || comp.Plugins[0].Types[0].SrcEnd != 137 ||
+		got.Span() != (data.Span{Start: 116, End: 138}) {
+		t.Errorf("Expected all positions moved by 100 but got: %#v", got)
+	}
+	if flow.Parts[0][0].(data.Arrow).FromPort.SrcPos != 16 || flow.Imports[0].SrcPos != 0 {
+		t.Errorf("Expected the original flow to be unchanged but got: %#v", flow)
+	}
+}
@@ -0,0 +1,137 @@
+package data
+
+import (
+	"fmt"
+)
+
+// Node is any node of a flow that can be visited by Walk.
+// It is implemented by Flow, Line, Arrow, Port, Component, CompDecl, Plugin,
+// Type and Attribute.
+type Node interface {
+	Span() Span
+	isNode()
+}
+
+// Line is a single line of a flow as visited by Walk.
+type Line struct {
+	Index int
+	Parts []Part
+}
+
+func (Flow) isNode()      {}
+func (Line) isNode()      {}
+func (Arrow) isNode()     {}
+func (Port) isNode()      {}
+func (Component) isNode() {}
+func (CompDecl) isNode()  {}
+func (Plugin) isNode()    {}
+func (Type) isNode()      {}
+func (Attribute) isNode() {}
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses a flow in depth-first order (just like go/ast.Walk):
+// It starts by calling v.Visit(node); node must not be nil.
+// If the visitor w returned by v.Visit(node) is not nil, Walk is invoked
+// recursively with visitor w for each of the non-nil children of node,
+// followed by a call of w.Visit(nil).
+//
+// The children are visited in source order:
+// - Flow: its lines
+// - Line: its arrows and components
+// - Arrow: its from port, data types, attributes and to port
+// - Component: its declaration, plugins and attributes
+// - CompDecl: its type
+// - Plugin: its types
+// - Type: its element, key, value, parameter, result and type argument types
+//
+// Separators between lines of data types are visited as normal types.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case Flow:
+		for i, parts := range n.Parts {
+			Walk(v, Line{Index: i, Parts: parts})
+		}
+	case Line:
+		for _, p := range n.Parts {
+			if p != nil {
+				Walk(v, p)
+			}
+		}
+	case Arrow:
+		if n.FromPort != nil {
+			Walk(v, *n.FromPort)
+		}
+		walkTypes(v, n.Data)
+		walkAttrs(v, n.Attrs)
+		if n.ToPort != nil {
+			Walk(v, *n.ToPort)
+		}
+	case Port, Attribute:
+		// nothing to do
+	case Component:
+		Walk(v, n.Decl)
+		for _, p := range n.Plugins {
+			Walk(v, p)
+		}
+		walkAttrs(v, n.Attrs)
+	case CompDecl:
+		Walk(v, n.Type)
+	case Plugin:
+		walkTypes(v, n.Types)
+	case Type:
+		for _, t := range []*Type{
+			n.ListType, n.MapKeyType, n.MapValueType,
+			n.PointerType, n.SliceType, n.ArrayType, n.ChanType,
+		} {
+			if t != nil {
+				Walk(v, *t)
+			}
+		}
+		walkTypes(v, n.Params)
+		walkTypes(v, n.Results)
+		walkTypes(v, n.TypeArgs)
+	default:
+		panic(fmt.Sprintf("data.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+func walkTypes(v Visitor, types []Type) {
+	for _, t := range types {
+		Walk(v, t)
+	}
+}
+
+func walkAttrs(v Visitor, attrs Attributes) {
+	for _, a := range attrs {
+		Walk(v, a)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses a flow in depth-first order (just like go/ast.Inspect):
+// It starts by calling f(node); node must not be nil.
+// If f returns true, Inspect invokes f recursively for each of the non-nil
+// children of node, followed by a call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,118 @@
+package data_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/flowdev/gflowparser/data"
+)
+
+func TestInspect(t *testing.T) {
+	flow := data.Flow{Parts: [][]data.Part{
+		{
+			data.Arrow{
+				FromPort: &data.Port{Name: "in"},
+				Data: []data.Type{
+					{ListType: &data.Type{LocalType: "Item"}},
+					data.SeparatorType,
+					{
+						MapKeyType:   &data.Type{LocalType: "Key"},
+						MapValueType: &data.Type{Package: "pkg", LocalType: "Value"},
+					},
+				},
+			},
+			data.Component{
+				Decl: data.CompDecl{Name: "a", Type: data.Type{LocalType: "A"}},
+				Plugins: []data.Plugin{{
+					Name:  "p",
+					Types: []data.Type{{LocalType: "P"}},
+				}},
+				Attrs: data.Attributes{{Key: "timeout", Value: "5s"}},
+			},
+			data.Arrow{
+				FromPort: &data.Port{Name: "out"},
+				Attrs:    data.Attributes{{Key: "async"}},
+				ToPort:   &data.Port{Name: "..."},
+			},
+		},
+		{},
+	}}
+	expected := []string{
+		"Flow",
+		"Line 0",
+		"Arrow",
+		"Port in", "end",
+		"Type []", "Type Item", "end", "end",
+		"Type <SEPARATOR>", "end",
+		"Type map", "Type Key", "end", "Type pkg.Value", "end", "end",
+		"end",
+		"Component",
+		"CompDecl a", "Type A", "end", "end",
+		"Plugin p", "Type P", "end", "end",
+		"Attribute timeout", "end",
+		"end",
+		"Arrow", "Port out", "end", "Attribute async", "end", "Port ...", "end", "end",
+		"end",
+		"Line 1", "end",
+		"end",
+	}
+
+	var got []string
+	data.Inspect(flow, func(n data.Node) bool {
+		got = append(got, nodeString(n))
+		return true
+	})
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected nodes:\n%q\nbut got:\n%q", expected, got)
+	}
+
+	// don't descend into components
+	got = nil
+	data.Inspect(flow, func(n data.Node) bool {
+		if n != nil {
+			got = append(got, nodeString(n))
+		}
+		_, isComp := n.(data.Component)
+		return !isComp
+	})
+	for _, s := range got {
+		if s == "CompDecl a" || s == "Plugin p" {
+			t.Errorf("Expected no children of components but got: %q", s)
+		}
+	}
+}
+
+func nodeString(n data.Node) string {
+	switch v := n.(type) {
+	case nil:
+		return "end"
+	case data.Flow:
+		return "Flow"
+	case data.Line:
+		return fmt.Sprintf("Line %d", v.Index)
+	case data.Arrow:
+		return "Arrow"
+	case data.Port:
+		return "Port " + v.Name
+	case data.Component:
+		return "Component"
+	case data.CompDecl:
+		return "CompDecl " + v.Name
+	case data.Plugin:
+		return "Plugin " + v.Name
+	case data.Attribute:
+		return "Attribute " + v.Key
+	case data.Type:
+		switch {
+		case v.ListType != nil:
+			return "Type []"
+		case v.MapKeyType != nil:
+			return "Type map"
+		case v.Package != "":
+			return "Type " + v.Package + "." + v.LocalType
+		}
+		return "Type " + v.LocalType
+	}
+	return fmt.Sprintf("unknown %T", n)
+}
@@ -15,9 +15,11 @@ const (
 	errMsgPartType = "Found illegal flow part type '%T' at index [%d, %d]"
 	errMsgLoneComp = "Component reference with name '%s' without " +
 		"input or output found:\n%s"
+	errMsgContStart = "The continuation '%s' is missing its counter part:\n%s"
 )
 
 // Whereer can give a human readable description of a source position.
+// It is implemented by data.File, data.FileSet and gparselib.SourceData.
 type Whereer interface {
 	Where(pos int) string
 }
@@ -28,7 +30,13 @@ type decl struct {
 	i, j     int
 	svgOp    *svg.Op
 	svgMerge *svg.Merge
-	svgSplit *svg.Split
+	svgSplit *splitShapes
+}
+
+// splitShapes holds the shapes of a split until they are converted into a
+// svg.Split by cleanSVGData.
+type splitShapes struct {
+	Shapes [][]interface{}
 }
 
 type merge struct {
@@ -109,7 +117,7 @@ func parserPartsToSVGData(flowDat data.Flow, w Whereer,
 
 func arrowToSVGData(arr data.Arrow, hasSrcOp, hasDstOp bool) *svg.Arrow {
 	return &svg.Arrow{
-		DataType: arrDataToSVGData(arr.Data),
+		DataType: arrTextToSVGData(arr.Data, arr.Attrs),
 		HasSrcOp: hasSrcOp, SrcPort: portToSVGData(arr.FromPort),
 		HasDstOp: hasDstOp, DstPort: portToSVGData(arr.ToPort),
 	}
@@ -120,8 +128,12 @@ func compToSVGData(comp data.Component) *svg.Op {
 	for i, plug := range comp.Plugins {
 		plugs[i] = pluginToSVGData(plug)
 	}
+	text := compDeclToSVGData(comp.Decl)
+	if len(comp.Attrs) > 0 {
+		text = append(text, attrsToSVGData(comp.Attrs))
+	}
 	return &svg.Op{
-		Main:    &svg.Rect{Text: compDeclToSVGData(comp.Decl)},
+		Main:    &svg.Rect{Text: text},
 		Plugins: plugs,
 	}
 }
@@ -147,6 +159,35 @@ func pluginToSVGData(plug data.Plugin) *svg.Plugin {
 	}
 }
 
+// arrTextToSVGData returns the text of an arrow: its data types followed by
+// its attributes.
+func arrTextToSVGData(dat []data.Type, attrs data.Attributes) []string {
+	ret := arrDataToSVGData(dat)
+	if len(attrs) > 0 {
+		ret = append(ret, attrsToSVGData(attrs))
+	}
+	return ret
+}
+
+// attrsToSVGData returns all attributes in a single line:
+// {timeout: 5s, async}
+func attrsToSVGData(attrs data.Attributes) string {
+	b := strings.Builder{}
+	b.WriteString("{")
+	for i, a := range attrs {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(a.Key)
+		if a.Value != "" {
+			b.WriteString(": ")
+			b.WriteString(a.Value)
+		}
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
 func arrDataToSVGData(dat []data.Type) []string {
 	if len(dat) == 0 {
 		return nil
@@ -156,7 +197,7 @@ func arrDataToSVGData(dat []data.Type) []string {
 	b := strings.Builder{}
 	b.WriteString("(")
 	for _, typ := range dat {
-		if typ == data.SeparatorType { // we want a new string
+		if typ.Separator() { // we want a new string
 			b.WriteString(",")
 			ret = append(ret, b.String())
 			b.Reset()
@@ -178,7 +219,7 @@ func portToSVGData(port *data.Port) string {
 		return ""
 	}
 	if port.Continuation() {
-		return fmt.Sprintf("...%d", port.Index)
+		return port.Name + port.ContinuationKey()
 	}
 	if port.HasIndex {
 		return fmt.Sprintf("%s[%d]", port.Name, port.Index)
@@ -187,16 +228,51 @@ func portToSVGData(port *data.Port) string {
 }
 
 func typeToSVGData(typ data.Type) string {
-	if typ.ListType != nil {
+	switch {
+	case typ.ListType != nil:
 		return "list(" + typeToSVGData(*typ.ListType) + ")"
-	}
-	if typ.MapKeyType != nil {
+	case typ.MapKeyType != nil:
 		return "map(" + typeToSVGData(*typ.MapKeyType) + ", " + typeToSVGData(*typ.MapValueType) + ")"
+	case typ.PointerType != nil:
+		return "*" + typeToSVGData(*typ.PointerType)
+	case typ.SliceType != nil:
+		return "[]" + typeToSVGData(*typ.SliceType)
+	case typ.ArrayType != nil:
+		return fmt.Sprintf("[%d]%s", typ.ArrayLen, typeToSVGData(*typ.ArrayType))
+	case typ.ChanType != nil:
+		switch typ.ChanDir {
+		case data.ChanSend:
+			return "chan<- " + typeToSVGData(*typ.ChanType)
+		case data.ChanRecv:
+			return "<-chan " + typeToSVGData(*typ.ChanType)
+		}
+		return "chan " + typeToSVGData(*typ.ChanType)
+	case typ.Func:
+		s := "func(" + typesToSVGData(typ.Params) + ")"
+		switch len(typ.Results) {
+		case 0:
+			return s
+		case 1:
+			return s + " " + typeToSVGData(typ.Results[0])
+		}
+		return s + " (" + typesToSVGData(typ.Results) + ")"
 	}
+	s := typ.LocalType
 	if typ.Package != "" {
-		return typ.Package + "." + typ.LocalType
+		s = typ.Package + "." + s
+	}
+	if len(typ.TypeArgs) > 0 {
+		s += "[" + typesToSVGData(typ.TypeArgs) + "]"
 	}
-	return typ.LocalType
+	return s
+}
+
+func typesToSVGData(types []data.Type) string {
+	strs := make([]string, len(types))
+	for i, typ := range types {
+		strs[i] = typeToSVGData(typ)
+	}
+	return strings.Join(strs, ", ")
 }
 
 // handleSplits handles splits (and merges with splits).
@@ -242,9 +318,9 @@ func handleSplits(shapes [][]interface{}, decls map[string]*decl, clsts clusters
 	}
 	return shapes, clsts
 }
-func prependShapeLine(split *svg.Split, sl []interface{}) *svg.Split {
+func prependShapeLine(split *splitShapes, sl []interface{}) *splitShapes {
 	if split == nil || len(split.Shapes) == 0 {
-		return &svg.Split{Shapes: [][]interface{}{sl}}
+		return &splitShapes{Shapes: [][]interface{}{sl}}
 	}
 	split.Shapes = append([][]interface{}{copyShapeLine(sl)}, split.Shapes...)
 	return split
@@ -420,33 +496,35 @@ func insertEmptyShapeRow(shapes [][]interface{}, i int) [][]interface{} {
 }
 
 // cleanSVGData replaces all special data structures with pure SVG ones.
-// shapes itself doesn't change (only some of its rows might be appended to).
-func cleanSVGData(shapes [][]interface{}) {
+func cleanSVGData(shapes [][]interface{}) [][]svg.Shape {
+	svgShapes := make([][]svg.Shape, len(shapes))
 	for i, sl := range shapes {
 		var svgSplit *svg.Split
+		svgLine := make([]svg.Shape, 0, len(sl)+1)
 		for j, si := range sl {
 			switch s := si.(type) {
 			case *merge:
-				sl[j] = s.svg
+				svgLine = append(svgLine, s.svg)
 			case *decl:
-				sl[j] = s.svgOp
+				svgLine = append(svgLine, s.svgOp)
 				if s.svgSplit != nil && len(s.svgSplit.Shapes) > 0 {
-					cleanSVGData(s.svgSplit.Shapes)
 					if j < len(sl)-1 {
 						panic("decls with splits have to be at the end of their row!")
 					}
-					svgSplit = s.svgSplit
+					svgSplit = &svg.Split{Shapes: cleanSVGData(s.svgSplit.Shapes)}
 				}
 			case *svg.Merge, *svg.Rect, *svg.Arrow, *svg.Op:
-				// nothing to do
+				svgLine = append(svgLine, si.(svg.Shape))
 			default:
 				panic(fmt.Sprintf("found unexpected shape type: %T", si))
 			}
-			if svgSplit != nil {
-				shapes[i] = append(sl, svgSplit)
-			}
 		}
+		if svgSplit != nil {
+			svgLine = append(svgLine, svgSplit)
+		}
+		svgShapes[i] = svgLine
 	}
+	return svgShapes
 }
 
 // Convert converts a flow data structure (as generated by the parser) into a
@@ -475,7 +553,5 @@ func Convert(flow data.Flow, wh Whereer) (svg.Flow, error) {
 
 	shapes = addEmptyRows(shapes, clsts)
 
-	cleanSVGData(shapes)
-
-	return svg.Flow{Shapes: shapes}, nil
+	return svg.Flow{Shapes: cleanSVGData(shapes)}, nil
 }
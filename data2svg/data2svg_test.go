@@ -22,7 +22,7 @@ func TestConvert(t *testing.T) {
 		{
 			name: "simple",
 			given: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{
 						data.Arrow{
 							FromPort: &data.Port{Name: "a"},
@@ -39,7 +39,7 @@ func TestConvert(t *testing.T) {
 				},
 			},
 			expected: svg.Flow{
-				Shapes: [][]interface{}{
+				Shapes: [][]svg.Shape{
 					{
 						&svg.Arrow{
 							DataType: []string{"(b)"},
@@ -56,10 +56,51 @@ func TestConvert(t *testing.T) {
 				},
 			},
 			hasError: false,
+		}, {
+			name: "attributes",
+			given: data.Flow{
+				Parts: [][]data.Part{
+					{
+						data.Arrow{
+							FromPort: &data.Port{Name: "a"},
+							Data:     []data.Type{data.Type{LocalType: "b"}},
+							Attrs:    data.Attributes{{Key: "async"}},
+						},
+						data.Component{
+							Decl: data.CompDecl{
+								Name: "fetch",
+								Type: data.Type{LocalType: "HttpGet"},
+							},
+							Attrs: data.Attributes{
+								{Key: "timeout", Value: "5s"},
+								{Key: "owner", Value: "team-a"},
+							},
+						},
+					},
+				},
+			},
+			expected: svg.Flow{
+				Shapes: [][]svg.Shape{
+					{
+						&svg.Arrow{
+							DataType: []string{"(b)", "{async}"},
+							HasSrcOp: false, SrcPort: "a",
+							HasDstOp: true,
+						},
+						&svg.Op{
+							Main: &svg.Rect{
+								Text: []string{"fetch", "HttpGet", "{timeout: 5s, owner: team-a}"},
+							},
+							Plugins: []*svg.Plugin{},
+						},
+					},
+				},
+			},
+			hasError: false,
 		}, {
 			name: "full_arrows",
 			given: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{
 						data.Arrow{
 							FromPort: &data.Port{Name: "a"},
@@ -114,7 +155,7 @@ func TestConvert(t *testing.T) {
 				},
 			},
 			expected: svg.Flow{
-				Shapes: [][]interface{}{
+				Shapes: [][]svg.Shape{
 					{
 						&svg.Arrow{
 							DataType: []string{"(pack.b)"},
@@ -146,7 +187,7 @@ func TestConvert(t *testing.T) {
 		}, {
 			name: "continuations",
 			given: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{
 						data.Arrow{
 							FromPort: &data.Port{Name: "a"},
@@ -201,7 +242,7 @@ func TestConvert(t *testing.T) {
 				},
 			},
 			expected: svg.Flow{
-				Shapes: [][]interface{}{
+				Shapes: [][]svg.Shape{
 					{
 						&svg.Arrow{
 							DataType: []string{"(pack.b)"},
@@ -230,10 +271,60 @@ func TestConvert(t *testing.T) {
 				},
 			},
 			hasError: false,
+		}, {
+			name: "named_continuations",
+			given: data.Flow{
+				Parts: [][]data.Part{
+					{
+						data.Arrow{
+							FromPort: &data.Port{Name: "a"},
+						},
+						data.Component{
+							Decl: data.CompDecl{
+								Name:      "c",
+								Type:      data.Type{LocalType: "c"},
+								VagueType: true,
+							},
+						},
+						data.Arrow{
+							ToPort: &data.Port{Name: "...", Label: "retryPath"},
+						},
+					}, {
+						data.Arrow{
+							FromPort: &data.Port{Name: "...", Label: "retryPath"},
+							ToPort:   &data.Port{Name: "b"},
+						},
+					},
+				},
+			},
+			expected: svg.Flow{
+				Shapes: [][]svg.Shape{
+					{
+						&svg.Arrow{
+							HasSrcOp: false, SrcPort: "a",
+							HasDstOp: true, DstPort: "",
+						},
+						&svg.Op{
+							Main:    &svg.Rect{Text: []string{"c"}},
+							Plugins: []*svg.Plugin{},
+						},
+						&svg.Arrow{
+							HasSrcOp: true, SrcPort: "",
+							HasDstOp: false, DstPort: "...retryPath",
+						},
+					}, {}, {
+						&svg.Arrow{
+							HasSrcOp: false, SrcPort: "...retryPath",
+							HasDstOp: false, DstPort: "b",
+						},
+					},
+				},
+			},
+			hasError: false,
 		}, {
 			name: "full_components",
 			given: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{
 						data.Component{
 							Decl: data.CompDecl{
@@ -334,7 +425,7 @@ func TestConvert(t *testing.T) {
 				},
 			},
 			expected: svg.Flow{
-				Shapes: [][]interface{}{
+				Shapes: [][]svg.Shape{
 					{
 						&svg.Op{
 							Main: &svg.Rect{
@@ -443,7 +534,7 @@ func TestConvert(t *testing.T) {
 		}, {
 			name: "splits_n_merges",
 			given: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{ // [a A] (data)-> [b B] -> [c C]
 						data.Component{
 							Decl: data.CompDecl{
@@ -609,7 +700,7 @@ func TestConvert(t *testing.T) {
 				},
 			},
 			expected: svg.Flow{
-				Shapes: [][]interface{}{
+				Shapes: [][]svg.Shape{
 					{
 						&svg.Op{
 							Main: &svg.Rect{
@@ -651,7 +742,7 @@ func TestConvert(t *testing.T) {
 							Plugins: []*svg.Plugin{},
 						},
 						&svg.Split{
-							Shapes: [][]interface{}{
+							Shapes: [][]svg.Shape{
 								{
 									&svg.Arrow{
 										DataType: []string{"(data)"},
@@ -678,7 +769,7 @@ func TestConvert(t *testing.T) {
 										Plugins: []*svg.Plugin{},
 									},
 									&svg.Split{
-										Shapes: [][]interface{}{
+										Shapes: [][]svg.Shape{
 											{
 												&svg.Arrow{HasSrcOp: true, HasDstOp: true},
 												&svg.Merge{ID: "c", Size: 4},
@@ -758,7 +849,7 @@ func TestConvert(t *testing.T) {
 		}, {
 			name: "circles",
 			given: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{
 						data.Arrow{
 							FromPort: &data.Port{Name: "i"},
@@ -817,7 +908,7 @@ func TestConvert(t *testing.T) {
 				},
 			},
 			expected: svg.Flow{
-				Shapes: [][]interface{}{
+				Shapes: [][]svg.Shape{
 					{
 						&svg.Arrow{
 							DataType: []string{"(data)"}, SrcPort: "i",
@@ -837,7 +928,7 @@ func TestConvert(t *testing.T) {
 							Plugins: []*svg.Plugin{},
 						},
 						&svg.Split{
-							Shapes: [][]interface{}{
+							Shapes: [][]svg.Shape{
 								{
 									&svg.Arrow{HasSrcOp: true, HasDstOp: true},
 									&svg.Rect{
@@ -868,7 +959,7 @@ func TestConvert(t *testing.T) {
 		}, {
 			name: "empty_rows",
 			given: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{
 						data.Component{
 							Decl: data.CompDecl{
@@ -927,8 +1018,8 @@ func TestConvert(t *testing.T) {
 				},
 			},
 			expected: svg.Flow{
-				Shapes: [][]interface{}{
-					[]interface{}{
+				Shapes: [][]svg.Shape{
+					[]svg.Shape{
 						&svg.Op{
 							Main: &svg.Rect{
 								Text: []string{"a", "A"},
@@ -995,7 +1086,7 @@ func TestConvert(t *testing.T) {
 		}, {
 			name: "error",
 			given: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{
 						data.Arrow{
 							FromPort: &data.Port{Name: "a"},
@@ -1045,6 +1136,31 @@ func TestConvert(t *testing.T) {
 	}
 }
 
+func TestTypeToSVGData(t *testing.T) {
+	item := &data.Type{Package: "p", LocalType: "Item"}
+	specs := []struct {
+		given    data.Type
+		expected string
+	}{
+		{given: data.Type{ListType: item}, expected: "list(p.Item)"},
+		{given: data.Type{MapKeyType: &data.Type{LocalType: "string"}, MapValueType: item}, expected: "map(string, p.Item)"},
+		{given: data.Type{PointerType: &data.Type{SliceType: item}}, expected: "*[]p.Item"},
+		{given: data.Type{ArrayType: item, ArrayLen: 4}, expected: "[4]p.Item"},
+		{given: data.Type{ChanType: item}, expected: "chan p.Item"},
+		{given: data.Type{ChanType: item, ChanDir: data.ChanSend}, expected: "chan<- p.Item"},
+		{given: data.Type{ChanType: item, ChanDir: data.ChanRecv}, expected: "<-chan p.Item"},
+		{given: data.Type{Func: true}, expected: "func()"},
+		{given: data.Type{Func: true, Params: []data.Type{*item, *item}, Results: []data.Type{*item}}, expected: "func(p.Item, p.Item) p.Item"},
+		{given: data.Type{Func: true, Results: []data.Type{*item, {LocalType: "error"}}}, expected: "func() (p.Item, error)"},
+		{given: data.Type{LocalType: "Result", TypeArgs: []data.Type{*item, {LocalType: "error"}}}, expected: "Result[p.Item, error]"},
+	}
+	for _, spec := range specs {
+		if got := typeToSVGData(spec.given); got != spec.expected {
+			t.Errorf("Expected %q but got %q.", spec.expected, got)
+		}
+	}
+}
+
 func TestParserPartsToSVGData(t *testing.T) {
 	specs := []struct {
 		name           string
@@ -1057,7 +1173,7 @@ func TestParserPartsToSVGData(t *testing.T) {
 		{
 			name: "simple",
 			givenFlowDat: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{
 						data.Arrow{
 							FromPort: &data.Port{Name: "a"},
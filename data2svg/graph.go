@@ -0,0 +1,197 @@
+package data2svg
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/flowdev/gflowparser/data"
+	"github.com/flowdev/gflowparser/svg"
+)
+
+// graphBuilder collects the nodes and edges of a flow graph.
+type graphBuilder struct {
+	graph svg.Graph
+	comps map[string]*svg.Node
+	decls map[string]int // source position of the declaration
+	conts map[string]*svg.Edge
+	w     Whereer
+}
+
+// ConvertToGraph converts a flow data structure (as generated by the parser)
+// into a graph of SVG shapes that can be drawn with the layered layout engine.
+// Continuations are joined so a wrapped flow line becomes a single edge.
+// Circles are broken by replacing the component that completes the circle
+// with a back reference (just like Convert does).
+// If the flow is invalid an error and no graph is returned.
+//
+// flow:
+//     in (data.Flow, Whereer)-> [transformation partsToGraph] -> out
+//     in (svg.Graph)-> [breakGraphCircles] -> out (svg.Graph)-> out
+//     [transformation] error (error)-> error
+func ConvertToGraph(flow data.Flow, wh Whereer) (svg.Graph, error) {
+	g, err := ConvertToGraphWithCircles(flow, wh)
+	if err != nil {
+		return svg.Graph{}, err
+	}
+	breakGraphCircles(&g)
+	return g, nil
+}
+
+// ConvertToGraphWithCircles works just like ConvertToGraph but keeps circles.
+// This is useful for output formats that can handle circles themselves
+// (e.g. Mermaid).
+func ConvertToGraphWithCircles(flow data.Flow, wh Whereer) (svg.Graph, error) {
+	gb := &graphBuilder{
+		comps: make(map[string]*svg.Node),
+		decls: make(map[string]int),
+		conts: make(map[string]*svg.Edge),
+		w:     wh,
+	}
+	err := gb.partsToGraph(flow)
+	if err != nil {
+		return svg.Graph{}, err
+	}
+	return gb.graph, nil
+}
+
+func (gb *graphBuilder) partsToGraph(flow data.Flow) error {
+	for i, partLine := range flow.Parts {
+		m := len(partLine) - 1
+		var lastComp *svg.Node
+		var lastEdge *svg.Edge
+		for j, part := range partLine {
+			switch p := part.(type) {
+			case data.Arrow:
+				edge, err := gb.addArrow(p, lastComp, j < m)
+				if err != nil {
+					return err
+				}
+				lastEdge = edge
+				lastComp = nil
+			case data.Component:
+				comp, err := gb.addComponent(p)
+				if err != nil {
+					return err
+				}
+				if lastEdge != nil {
+					lastEdge.To = comp.ID
+				}
+				lastComp = comp
+				lastEdge = nil
+			default:
+				return fmt.Errorf(errMsgPartType, part, i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// addArrow adds the edge for an arrow.
+// The destination of the edge is only known for outer ports or
+// continuations (else it is set by addComponent).
+func (gb *graphBuilder) addArrow(arr data.Arrow, src *svg.Node, hasDstOp bool,
+) (*svg.Edge, error) {
+	if arr.FromPort != nil && arr.FromPort.Continuation() { // the wrapped arrow continues
+		e, ok := gb.conts[arr.FromPort.ContinuationKey()]
+		if !ok {
+			return nil, fmt.Errorf(errMsgContStart,
+				portToSVGData(arr.FromPort), gb.w.Where(arr.FromPort.SrcPos))
+		}
+		delete(gb.conts, arr.FromPort.ContinuationKey())
+		e.Arrow.DataType = arrTextToSVGData(arr.Data, arr.Attrs)
+		e.Arrow.HasDstOp = hasDstOp
+		e.Arrow.DstPort = portToSVGData(arr.ToPort)
+		if !hasDstOp {
+			e.To = gb.addPort(e.Arrow.DstPort).ID
+		}
+		return e, nil
+	}
+
+	e := &svg.Edge{Arrow: arrowToSVGData(arr, src != nil, hasDstOp)}
+	if src != nil {
+		e.From = src.ID
+	} else {
+		e.From = gb.addPort(e.Arrow.SrcPort).ID
+	}
+	if arr.ToPort != nil && arr.ToPort.Continuation() { // the arrow is wrapped
+		gb.conts[arr.ToPort.ContinuationKey()] = e
+		e.Arrow.DstPort = ""
+	} else if !hasDstOp {
+		e.To = gb.addPort(e.Arrow.DstPort).ID
+	}
+	gb.graph.Edges = append(gb.graph.Edges, e)
+	return e, nil
+}
+
+func (gb *graphBuilder) addPort(port string) *svg.Node {
+	n := &svg.Node{
+		ID:   "#" + strconv.Itoa(len(gb.graph.Nodes)),
+		Port: port,
+	}
+	gb.graph.Nodes = append(gb.graph.Nodes, n)
+	return n
+}
+
+func (gb *graphBuilder) addComponent(comp data.Component) (*svg.Node, error) {
+	name := comp.Decl.Name
+	if n, ok := gb.comps[name]; ok {
+		if !comp.Decl.VagueType { // prevent double declaration
+			return nil, fmt.Errorf(errMsg2Decls,
+				name, gb.w.Where(gb.decls[name]), gb.w.Where(comp.SrcPos))
+		}
+		return n, nil
+	}
+	n := &svg.Node{ID: name, Op: compToSVGData(comp)}
+	gb.comps[name] = n
+	gb.decls[name] = comp.SrcPos
+	gb.graph.Nodes = append(gb.graph.Nodes, n)
+	return n, nil
+}
+
+// breakGraphCircles replaces the destination of edges that complete a circle
+// with back references.
+// The flow is searched depth first starting at the nodes without incoming
+// edges in the order of the flow.
+func breakGraphCircles(g *svg.Graph) {
+	outs := make(map[string][]*svg.Edge, len(g.Nodes))
+	ins := make(map[string]int, len(g.Nodes))
+	for _, e := range g.Edges {
+		outs[e.From] = append(outs[e.From], e)
+		ins[e.To]++
+	}
+
+	const (
+		unseen = iota
+		active
+		done
+	)
+	state := make(map[string]int, len(g.Nodes))
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = active
+		for _, e := range outs[id] {
+			switch state[e.To] {
+			case unseen:
+				visit(e.To)
+			case active: // back reference
+				n := &svg.Node{
+					ID:   "#" + strconv.Itoa(len(g.Nodes)),
+					Rect: &svg.Rect{Text: []string{e.To}},
+				}
+				g.Nodes = append(g.Nodes, n)
+				e.To = n.ID
+			}
+		}
+		state[id] = done
+	}
+	for _, n := range g.Nodes {
+		if ins[n.ID] == 0 && state[n.ID] == unseen {
+			visit(n.ID)
+		}
+	}
+	for _, n := range g.Nodes { // circles without any entry
+		if state[n.ID] == unseen {
+			visit(n.ID)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package data2svg
+
+import (
+	"testing"
+
+	"github.com/flowdev/gflowparser/data"
+	"github.com/flowdev/gflowparser/svg"
+)
+
+func TestConvertToGraph(t *testing.T) {
+	compA := data.Component{
+		Decl: data.CompDecl{Name: "a", Type: data.Type{LocalType: "a"}, VagueType: true},
+	}
+	compB := data.Component{
+		Decl: data.CompDecl{Name: "b", Type: data.Type{LocalType: "B"}},
+	}
+	opA := &svg.Op{Main: &svg.Rect{Text: []string{"a"}}, Plugins: []*svg.Plugin{}}
+	opB := &svg.Op{Main: &svg.Rect{Text: []string{"b", "B"}}, Plugins: []*svg.Plugin{}}
+
+	specs := []struct {
+		name     string
+		given    data.Flow
+		expected svg.Graph
+		hasError bool
+	}{
+		{
+			name: "simple",
+			given: data.Flow{
+				Parts: [][]data.Part{
+					{
+						data.Arrow{
+							FromPort: &data.Port{Name: "in"},
+							Data:     []data.Type{{LocalType: "d"}},
+						},
+						compA,
+						data.Arrow{ToPort: &data.Port{Name: "out"}},
+					},
+				},
+			},
+			expected: svg.Graph{
+				Nodes: []*svg.Node{
+					{ID: "#0", Port: "in"},
+					{ID: "a", Op: opA},
+					{ID: "#2", Port: "out"},
+				},
+				Edges: []*svg.Edge{
+					{From: "#0", To: "a", Arrow: &svg.Arrow{
+						DataType: []string{"(d)"}, SrcPort: "in", HasDstOp: true,
+					}},
+					{From: "a", To: "#2", Arrow: &svg.Arrow{HasSrcOp: true, DstPort: "out"}},
+				},
+			},
+		}, {
+			name: "continuation",
+			given: data.Flow{
+				Parts: [][]data.Part{
+					{
+						data.Arrow{FromPort: &data.Port{Name: "in"}},
+						compA,
+						data.Arrow{
+							FromPort: &data.Port{Name: "x"},
+							ToPort:   &data.Port{Name: data.ContinuationSignal, Index: 1},
+						},
+					}, {
+						data.Arrow{
+							FromPort: &data.Port{Name: data.ContinuationSignal, Index: 1},
+							Data:     []data.Type{{LocalType: "d"}},
+							ToPort:   &data.Port{Name: "y"},
+						},
+						compB,
+					},
+				},
+			},
+			expected: svg.Graph{
+				Nodes: []*svg.Node{
+					{ID: "#0", Port: "in"},
+					{ID: "a", Op: opA},
+					{ID: "b", Op: opB},
+				},
+				Edges: []*svg.Edge{
+					{From: "#0", To: "a", Arrow: &svg.Arrow{SrcPort: "in", HasDstOp: true}},
+					{From: "a", To: "b", Arrow: &svg.Arrow{
+						DataType: []string{"(d)"},
+						HasSrcOp: true, SrcPort: "x",
+						HasDstOp: true, DstPort: "y",
+					}},
+				},
+			},
+		}, {
+			name: "named continuation",
+			given: data.Flow{
+				Parts: [][]data.Part{
+					{
+						data.Arrow{FromPort: &data.Port{Name: "in"}},
+						compA,
+						data.Arrow{ToPort: &data.Port{Name: data.ContinuationSignal, Label: "retry"}},
+					}, {
+						data.Arrow{
+							FromPort: &data.Port{Name: data.ContinuationSignal, Label: "retry"},
+							ToPort:   &data.Port{Name: "out"},
+						},
+					},
+				},
+			},
+			expected: svg.Graph{
+				Nodes: []*svg.Node{
+					{ID: "#0", Port: "in"},
+					{ID: "a", Op: opA},
+					{ID: "#2", Port: "out"},
+				},
+				Edges: []*svg.Edge{
+					{From: "#0", To: "a", Arrow: &svg.Arrow{SrcPort: "in", HasDstOp: true}},
+					{From: "a", To: "#2", Arrow: &svg.Arrow{HasSrcOp: true, DstPort: "out"}},
+				},
+			},
+		}, {
+			name: "circle",
+			given: data.Flow{
+				Parts: [][]data.Part{
+					{
+						data.Arrow{FromPort: &data.Port{Name: "in"}},
+						compA,
+						data.Arrow{},
+						compB,
+						data.Arrow{},
+						data.Component{Decl: data.CompDecl{
+							Name: "a", Type: data.Type{LocalType: "a"}, VagueType: true,
+						}},
+					},
+				},
+			},
+			expected: svg.Graph{
+				Nodes: []*svg.Node{
+					{ID: "#0", Port: "in"},
+					{ID: "a", Op: opA},
+					{ID: "b", Op: opB},
+					{ID: "#3", Rect: &svg.Rect{Text: []string{"a"}}},
+				},
+				Edges: []*svg.Edge{
+					{From: "#0", To: "a", Arrow: &svg.Arrow{SrcPort: "in", HasDstOp: true}},
+					{From: "a", To: "b", Arrow: &svg.Arrow{HasSrcOp: true, HasDstOp: true}},
+					{From: "b", To: "#3", Arrow: &svg.Arrow{HasSrcOp: true, HasDstOp: true}},
+				},
+			},
+		}, {
+			name: "double declaration",
+			given: data.Flow{
+				Parts: [][]data.Part{
+					{data.Arrow{FromPort: &data.Port{Name: "in"}}, compB},
+					{data.Arrow{FromPort: &data.Port{Name: "in"}}, compB},
+				},
+			},
+			hasError: true,
+		}, {
+			name: "continuation without counter part",
+			given: data.Flow{
+				Parts: [][]data.Part{
+					{data.Arrow{FromPort: &data.Port{Name: "...", Label: "retry"}}, compB},
+				},
+			},
+			hasError: true,
+		},
+	}
+
+	for _, spec := range specs {
+		t.Run(spec.name, func(t *testing.T) {
+			got, err := ConvertToGraph(spec.given, testWhereer{})
+			if spec.hasError && err != nil {
+				return
+			} else if spec.hasError && err == nil {
+				t.Error("Expected an error but didn't get one.")
+				return
+			} else if !spec.hasError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+				return
+			}
+			checkValue(spec.expected, got, spec.name+"_graph", t)
+		})
+	}
+}
+
+func TestConvertToGraphWithCircles(t *testing.T) {
+	compA := data.Component{
+		Decl: data.CompDecl{Name: "a", Type: data.Type{LocalType: "a"}, VagueType: true},
+	}
+	given := data.Flow{
+		Parts: [][]data.Part{
+			{data.Arrow{FromPort: &data.Port{Name: "in"}}, compA, data.Arrow{}, compA},
+		},
+	}
+	opA := &svg.Op{Main: &svg.Rect{Text: []string{"a"}}, Plugins: []*svg.Plugin{}}
+	expected := svg.Graph{
+		Nodes: []*svg.Node{
+			{ID: "#0", Port: "in"},
+			{ID: "a", Op: opA},
+		},
+		Edges: []*svg.Edge{
+			{From: "#0", To: "a", Arrow: &svg.Arrow{SrcPort: "in", HasDstOp: true}},
+			{From: "a", To: "a", Arrow: &svg.Arrow{HasSrcOp: true, HasDstOp: true}},
+		},
+	}
+	got, err := ConvertToGraphWithCircles(given, testWhereer{})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	checkValue(expected, got, "circles_graph", t)
+}
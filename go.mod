@@ -6,4 +6,5 @@ require (
 	github.com/davecgh/go-spew v1.1.1
 	github.com/flowdev/gparselib v0.0.0-20190826175941-49986cd3c0ee
 	github.com/sanity-io/litter v1.1.0
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,155 @@
+package gflowparser
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/flowdev/gflowparser/data"
+	"github.com/flowdev/gflowparser/parser"
+	"github.com/flowdev/gparselib"
+)
+
+// Error messages for imports.
+const (
+	errMsgImport      = "Unable to import '%s': %s\n%s"
+	errMsgImportedBy  = "... imported here:\n%s"
+	errMsgImportCycle = "import cycle: %s"
+	errMsgImportNamed = "the imported file has to contain flow lines without flow declaration"
+)
+
+// importer resolves the imports of flows by putting the lines of the
+// imported flows in front of the lines of the importing flow.
+// All imported files are added to a single FileSet, so the source positions
+// of all nodes point into the right file.
+// The lines of every file are added only once, even if it is imported by
+// multiple imported files (e.g. shared components).
+type importer struct {
+	readFile func(name string) ([]byte, error)
+	files    *data.FileSet
+	imported map[string]importedFile // parsed imported files by file name
+	stack    []string                // files being imported for finding cycles
+}
+
+// importedFile contains the own flow lines of an imported file and the names
+// of all files imported by it (directly or indirectly).
+type importedFile struct {
+	parts [][]data.Part
+	deps  []string // in the order their lines have to be added
+}
+
+func newImporter(readFile func(string) ([]byte, error), files *data.FileSet, fileName string,
+) *importer {
+	if readFile == nil {
+		readFile = ioutil.ReadFile
+	}
+	return &importer{
+		readFile: readFile,
+		files:    files,
+		imported: make(map[string]importedFile),
+		stack:    []string{filepath.Clean(fileName)},
+	}
+}
+
+// resolve resolves the imports of the flow from the given file.
+// Import paths are relative to the directory of the file.
+func (im *importer) resolve(flow data.Flow, fileName string) (data.Flow, error) {
+	if len(flow.Imports) == 0 {
+		return flow, nil
+	}
+	deps, err := im.importAll(flow, fileName)
+	if err != nil {
+		return data.Flow{}, err
+	}
+	var parts [][]data.Part
+	for _, dep := range deps {
+		parts = append(parts, im.imported[dep].parts...)
+	}
+	flow.Parts = append(parts, flow.Parts...)
+	return flow, nil
+}
+
+// importAll imports all files imported by the flow from the given file and
+// returns their names (each only once).
+func (im *importer) importAll(flow data.Flow, fileName string) ([]string, error) {
+	var deps []string
+	seen := make(map[string]bool)
+	for _, imp := range flow.Imports {
+		path := imp.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(fileName), path)
+		}
+		path = filepath.Clean(path)
+		f, err := im.importFile(path)
+		if ie, ok := err.(*importError); ok {
+			ie.msg += fmt.Sprintf(errMsgImportedBy, im.files.Where(imp.SrcPos))
+			return nil, ie
+		}
+		if err != nil {
+			return nil, &importError{
+				msg: fmt.Sprintf(errMsgImport, imp.Path, err, im.files.Where(imp.SrcPos)),
+			}
+		}
+		for _, dep := range append(f.deps, path) {
+			if !seen[dep] {
+				seen[dep] = true
+				deps = append(deps, dep)
+			}
+		}
+	}
+	return deps, nil
+}
+
+// importError is the error of a single import followed by the chain of
+// imports leading to it.
+type importError struct {
+	msg string
+}
+
+func (e *importError) Error() string {
+	return e.msg
+}
+
+func (im *importer) importFile(path string) (importedFile, error) {
+	for i, f := range im.stack {
+		if f == path {
+			cycle := append(append([]string{}, im.stack[i:]...), path)
+			return importedFile{}, fmt.Errorf(errMsgImportCycle, strings.Join(cycle, " -> "))
+		}
+	}
+	if f, ok := im.imported[path]; ok {
+		return f, nil
+	}
+
+	buf, err := im.readFile(path)
+	if err != nil {
+		return importedFile{}, err
+	}
+	content := string(buf)
+	pd := gparselib.NewParseData(path, content)
+	pFlow, err := parser.NewFlowParser()
+	if err != nil {
+		return importedFile{}, err
+	}
+	pd, _ = pFlow.ParseAnyFlowFile(pd, nil)
+	if _, err = parser.CheckFeedback(pd.Result); err != nil {
+		return importedFile{}, err
+	}
+	ff := pd.Result.Value.(data.FlowFile)
+	if ff.Flows[0].Name != "" {
+		return importedFile{}, fmt.Errorf(errMsgImportNamed)
+	}
+
+	f := im.files.AddFile(path, content)
+	flow := ff.Flows[0].Shifted(f.Base())
+	im.stack = append(im.stack, path)
+	deps, err := im.importAll(flow, path)
+	im.stack = im.stack[:len(im.stack)-1]
+	if err != nil {
+		return importedFile{}, err
+	}
+	imp := importedFile{parts: flow.Parts, deps: deps}
+	im.imported[path] = imp
+	return imp, nil
+}
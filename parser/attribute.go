@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"strconv"
+
+	"github.com/flowdev/gflowparser/data"
+	"github.com/flowdev/gparselib"
+)
+
+// AttributeParser is a parser for a single attribute of a component or
+// arrow.
+type AttributeParser struct {
+	pKey    *gparselib.RegexpParser
+	pString *gparselib.RegexpParser
+	pWord   *gparselib.RegexpParser
+}
+
+// NewAttributeParser creates a new parser for an attribute.
+// If any regular expression used by the subparsers is invalid an error is
+// returned.
+func NewAttributeParser() (*AttributeParser, error) {
+	pKey, err := gparselib.NewRegexpParser(`^[a-zA-Z][a-zA-Z0-9_-]*`)
+	if err != nil {
+		return nil, err
+	}
+	pString, err := gparselib.NewRegexpParser(`^"(?:[^"\\\n]|\\.)*"`)
+	if err != nil {
+		return nil, err
+	}
+	pWord, err := gparselib.NewRegexpParser(`^[^\s,{}"\[\]()]+`)
+	if err != nil {
+		return nil, err
+	}
+	return &AttributeParser{pKey: pKey, pString: pString, pWord: pWord}, nil
+}
+
+// ParseAttribute parses a key optionally followed by a colon and a value.
+// The value is a quoted string (with Go escapes) or a single word.
+// Attributes without value are flags.
+// * Semantic result: data.Attribute
+//
+// flow:
+//     in (gparselib.ParseData)-> [pKey gparselib.ParseRegexp] -> out
+//     in (gparselib.ParseData)-> [pString gparselib.ParseRegexp] -> out
+//     in (gparselib.ParseData)-> [pWord gparselib.ParseRegexp] -> out
+//     in (gparselib.ParseData)-> [pStrWord gparselib.ParseAny [pString, pWord]] -> out
+//     in (gparselib.ParseData)-> [pValue gparselib.ParseAll
+//                          [ ParseSpaceComment, gparselib.ParseLiteral,
+//                            ParseSpaceComment, pStrWord              ]
+//                      ] -> out
+//     in (gparselib.ParseData)-> [pOptValue gparselib.ParseOptional [pValue]] -> out
+//     in (gparselib.ParseData)-> [gparselib.ParseAll [pKey, pOptValue]] -> out
+func (p *AttributeParser) ParseAttribute(pd *gparselib.ParseData, ctx interface{},
+) (*gparselib.ParseData, interface{}) {
+	pKey := func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+		return p.pKey.ParseRegexp(pd2, ctx2, TextSemantic)
+	}
+	pString := func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+		return p.pString.ParseRegexp(pd2, ctx2, parseQuotedSemantic)
+	}
+	pWord := func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+		return p.pWord.ParseRegexp(pd2, ctx2, TextSemantic)
+	}
+	pValue := gparselib.NewParseAllPlugin(
+		[]gparselib.SubparserOp{
+			ParseSpaceComment, gparselib.NewParseLiteralPlugin(nil, `:`), ParseSpaceComment,
+			gparselib.NewParseAnyPlugin([]gparselib.SubparserOp{pString, pWord}, nil),
+		},
+		func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+			pd2.Result.Value = pd2.SubResults[3].Value
+			return pd2, ctx2
+		},
+	)
+	pOptValue := gparselib.NewParseOptionalPlugin(pValue, nil)
+	return gparselib.ParseAll(pd, ctx,
+		[]gparselib.SubparserOp{pKey, pOptValue},
+		parseAttributeSemantic,
+	)
+}
+func parseQuotedSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseData, interface{}) {
+	s, err := strconv.Unquote(pd.Result.Text)
+	if err != nil {
+		pd.AddError(pd.Result.Pos, "invalid string "+pd.Result.Text, err)
+		pd.Result.Value = nil
+		return pd, ctx
+	}
+	pd.Result.Value = s
+	return pd, ctx
+}
+func parseAttributeSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseData, interface{}) {
+	attr := data.Attribute{
+		Key:    (pd.SubResults[0].Value).(string),
+		SrcPos: pd.Result.Pos,
+		SrcEnd: srcEnd(pd),
+	}
+	if val1 := pd.SubResults[1].Value; val1 != nil {
+		attr.Value = val1.(string)
+	}
+	pd.Result.Value = attr
+	return pd, ctx
+}
+
+// AttributesParser is a parser for the attributes of a component or arrow
+// including '{' and '}'.
+type AttributesParser struct {
+	pa *AttributeParser
+}
+
+// NewAttributesParser creates a new parser for the attributes of a component
+// or arrow.
+// If any regular expression used by the subparsers is invalid an error is
+// returned.
+func NewAttributesParser() (*AttributesParser, error) {
+	pa, err := NewAttributeParser()
+	if err != nil {
+		return nil, err
+	}
+	return &AttributesParser{pa: pa}, nil
+}
+
+// ParseAttributes parses attributes separated by commas and enclosed in
+// curly braces: {timeout: "5s", owner: team-a, async}
+// Keys that are set twice are reported by the flow parser, so the
+// attributes of a component or arrow stay optional.
+// * Semantic result: data.Attributes
+//
+// flow:
+//     in (gparselib.ParseData)-> [pAdditionalAttr gparselib.ParseAll
+//                          [ ParseSpaceComment, gparselib.ParseLiteral,
+//                            ParseSpaceComment, ParseAttribute         ]
+//                      ] -> out
+//     in (gparselib.ParseData)-> [pAdditionalAttrs gparselib.ParseMulti0 [pAdditionalAttr]] -> out
+//     in (gparselib.ParseData)-> [gparselib.ParseAll
+//                          [ gparselib.ParseLiteral, ParseSpaceComment, ParseAttribute,
+//                            pAdditionalAttrs, ParseSpaceComment, gparselib.ParseLiteral ]
+//                      ] -> out
+func (p *AttributesParser) ParseAttributes(pd *gparselib.ParseData, ctx interface{},
+) (*gparselib.ParseData, interface{}) {
+	pComma := gparselib.NewParseLiteralPlugin(nil, `,`)
+	pAdditionalAttr := gparselib.NewParseAllPlugin(
+		[]gparselib.SubparserOp{ParseSpaceComment, pComma, ParseSpaceComment, p.pa.ParseAttribute},
+		func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+			pd2.Result.Value = pd2.SubResults[3].Value
+			return pd2, ctx2
+		},
+	)
+	pAdditionalAttrs := gparselib.NewParseMulti0Plugin(pAdditionalAttr, nil)
+	pOpen := gparselib.NewParseLiteralPlugin(nil, `{`)
+	pClose := gparselib.NewParseLiteralPlugin(nil, `}`)
+	return gparselib.ParseAll(pd, ctx,
+		[]gparselib.SubparserOp{
+			pOpen, ParseSpaceComment, p.pa.ParseAttribute,
+			pAdditionalAttrs, ParseSpaceComment, pClose,
+		},
+		parseAttributesSemantic,
+	)
+}
+func parseAttributesSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseData, interface{}) {
+	additionalAttrs := (pd.SubResults[3].Value).([]interface{})
+	attrs := make(data.Attributes, len(additionalAttrs)+1)
+	attrs[0] = (pd.SubResults[2].Value).(data.Attribute)
+	for i, attr := range additionalAttrs {
+		attrs[i+1] = attr.(data.Attribute)
+	}
+	pd.Result.Value = attrs
+	return pd, ctx
+}
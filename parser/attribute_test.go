@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/flowdev/gflowparser/data"
+)
+
+func TestParseAttributes(t *testing.T) {
+	p, err := NewAttributesParser()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	runTests(t, p.ParseAttributes, []parseTestData{
+		{
+			givenName:        "empty",
+			givenContent:     ``,
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "no attributes",
+			givenContent:     `{}`,
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "no key",
+			givenContent:     `{: a}`,
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "bad string",
+			givenContent:     `{a: "\q"}`,
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:    "flag",
+			givenContent: `{async}`,
+			expectedValue: data.Attributes{
+				{Key: "async", SrcPos: 1, SrcEnd: 6},
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "simple",
+			givenContent: `{timeout: "5s", owner: team-a}`,
+			expectedValue: data.Attributes{
+				{Key: "timeout", Value: "5s", SrcPos: 1, SrcEnd: 14},
+				{Key: "owner", Value: "team-a", SrcPos: 16, SrcEnd: 29},
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "complex",
+			givenContent: "{ // comment\n\tdoc :\"a \\\"b\\\"\" ,\n\tlevel:3, sync }",
+			expectedValue: data.Attributes{
+				{Key: "doc", Value: `a "b"`, SrcPos: 14, SrcEnd: 28},
+				{Key: "level", Value: "3", SrcPos: 32, SrcEnd: 39},
+				{Key: "sync", SrcPos: 41, SrcEnd: 45},
+			},
+			expectedErrCount: 0,
+		},
+	})
+}
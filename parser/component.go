@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/flowdev/gflowparser/data"
 	"github.com/flowdev/gparselib"
@@ -11,12 +14,22 @@ import (
 type TypeParser struct {
 	pLocalType *LocalTypeIdentParser
 	pPack      *PackageIdentParser
+	pPath      *gparselib.RegexpParser
+	pArrayLen  *gparselib.RegexpParser
+	typeArgs   bool
 }
 
 // NewTypeParser creates a new parser for a type declaration.
 // If any regular expression used by the subparsers is invalid an error is
 // returned.
 func NewTypeParser() (*TypeParser, error) {
+	return newTypeParser(true)
+}
+
+// newTypeParser creates a new parser for a type declaration.
+// Without typeArgs the outermost named type can't have got type arguments,
+// so it can be followed by the plugins of a component: [a B[c]]
+func newTypeParser(typeArgs bool) (*TypeParser, error) {
 	pPack, err := NewPackageIdentParser()
 	if err != nil {
 		return nil, err
@@ -25,23 +38,85 @@ func NewTypeParser() (*TypeParser, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &TypeParser{pPack: pPack, pLocalType: pLType}, nil
+	pPath, err := gparselib.NewRegexpParser(`^"[^"\s]+"\.`)
+	if err != nil {
+		return nil, err
+	}
+	pArrayLen, err := gparselib.NewRegexpParser(`^[0-9]+`)
+	if err != nil {
+		return nil, err
+	}
+	return &TypeParser{
+		pPack: pPack, pPath: pPath, pLocalType: pLType, pArrayLen: pArrayLen,
+		typeArgs: typeArgs,
+	}, nil
 }
 
 // ParseType parses a type declaration including optional package.
+// The package can be given by its full import path, too:
+// "github.com/acme/order".Order
+// Besides list(T) and map(K, V) the Go types *T, []T, [N]T, chan T,
+// chan<- T, <-chan T, func(P) R, func(P1, P2) (R1, R2) and generic types
+// like pkg.T[A1, A2] are supported.
 // * Semantic result: The optional package name and the local type name
-//   including possible subtypes in case of a map or a list (data.Type).
+//   including possible subtypes in case of composite types (data.Type).
 //
 // flow:
-//     in (gparselib.ParseData)-> [pOpt gparselib.ParseOptional [ParsePackageIdent]] -> out
-//     in (gparselib.ParseData)-> [gparselib.ParseAll [pOpt, ParseLocalTypeIdent]] -> out
+//     in (gparselib.ParseData)-> [pLiteral gparselib.ParseLiteral] -> out
+//     in (gparselib.ParseData)-> [pList gparselib.ParseAll [pLiteral, ParseType, pLiteral]] -> out
+//     in (gparselib.ParseData)-> [pMap gparselib.ParseAll
+//                          [pLiteral, ParseType, pLiteral, ParseType, pLiteral]
+//                      ] -> out
+//     in (gparselib.ParseData)-> [pPointer gparselib.ParseAll [pLiteral, ParseType]] -> out
+//     in (gparselib.ParseData)-> [pOptLen gparselib.ParseOptional [pArrayLen]] -> out
+//     in (gparselib.ParseData)-> [pSliceArray gparselib.ParseAll
+//                          [pLiteral, pOptLen, pLiteral, ParseType]
+//                      ] -> out
+//     in (gparselib.ParseData)-> [pOptRecv gparselib.ParseOptional [pLiteral]] -> out
+//     in (gparselib.ParseData)-> [pChan gparselib.ParseAll [pOptRecv, pLiteral, pDir, ParseType]] -> out
+//     in (gparselib.ParseData)-> [pParenList gparselib.ParseAll
+//                          [pLiteral, ParseTypeList, pLiteral]
+//                      ] -> out
+//     in (gparselib.ParseData)-> [pResults gparselib.ParseAny [pParenList, ParseType]] -> out
+//     in (gparselib.ParseData)-> [pOptParams gparselib.ParseOptional [ParseTypeList]] -> out
+//     in (gparselib.ParseData)-> [pOptResults gparselib.ParseOptional [pResults]] -> out
+//     in (gparselib.ParseData)-> [pFunc gparselib.ParseAll
+//                          [pLiteral, pLiteral, pOptParams, pLiteral, pOptResults]
+//                      ] -> out
+//     in (gparselib.ParseData)-> [pPath gparselib.ParseRegexp] -> out
+//     in (gparselib.ParseData)-> [pPack gparselib.ParseAny [pPath, ParsePackageIdent]] -> out
+//     in (gparselib.ParseData)-> [pOptPack gparselib.ParseOptional [pPack]] -> out
+//     in (gparselib.ParseData)-> [pArgs gparselib.ParseAll [pLiteral, ParseTypeList, pLiteral]] -> out
+//     in (gparselib.ParseData)-> [pOptArgs gparselib.ParseOptional [pArgs]] -> out
+//     in (gparselib.ParseData)-> [pSimpleType gparselib.ParseAll
+//                          [pOptPack, ParseLocalTypeIdent, pOptArgs]
+//                      ] -> out
+//     in (gparselib.ParseData)-> [gparselib.ParseAny
+//                          [pList, pMap, pPointer, pSliceArray, pChan, pFunc, pSimpleType]
+//                      ] -> out
 func (p *TypeParser) ParseType(pd *gparselib.ParseData, ctx interface{},
 ) (*gparselib.ParseData, interface{}) {
+	return p.parseType(pd, ctx, p.typeArgs)
+}
+func (p *TypeParser) parseAnyType(pd *gparselib.ParseData, ctx interface{},
+) (*gparselib.ParseData, interface{}) {
+	return p.parseType(pd, ctx, true)
+}
+func (p *TypeParser) parsePlainType(pd *gparselib.ParseData, ctx interface{},
+) (*gparselib.ParseData, interface{}) {
+	return p.parseType(pd, ctx, false)
+}
+func (p *TypeParser) parseType(pd *gparselib.ParseData, ctx interface{}, typeArgs bool,
+) (*gparselib.ParseData, interface{}) {
+	pElem := p.parsePlainType // the element type is the end of the type
+	if typeArgs {
+		pElem = p.parseAnyType
+	}
 	pCloseParen := gparselib.NewParseLiteralPlugin(TextSemantic, `)`)
 	pList := gparselib.NewParseAllPlugin(
 		[]gparselib.SubparserOp{
 			gparselib.NewParseLiteralPlugin(nil, `list(`), ParseSpaceComment,
-			p.ParseType, ParseSpaceComment,
+			p.parseAnyType, ParseSpaceComment,
 			pCloseParen,
 		},
 		func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
@@ -49,6 +124,7 @@ func (p *TypeParser) ParseType(pd *gparselib.ParseData, ctx interface{},
 			pd2.Result.Value = data.Type{
 				ListType: &t,
 				SrcPos:   pd.Result.Pos,
+				SrcEnd:   srcEnd(pd2),
 			}
 			return pd2, ctx2
 		},
@@ -57,9 +133,9 @@ func (p *TypeParser) ParseType(pd *gparselib.ParseData, ctx interface{},
 	pMap := gparselib.NewParseAllPlugin(
 		[]gparselib.SubparserOp{
 			gparselib.NewParseLiteralPlugin(TextSemantic, `map(`), ParseSpaceComment,
-			p.ParseType, ParseSpaceComment,
+			p.parseAnyType, ParseSpaceComment,
 			gparselib.NewParseLiteralPlugin(nil, `,`), ParseSpaceComment,
-			p.ParseType, ParseSpaceComment,
+			p.parseAnyType, ParseSpaceComment,
 			pCloseParen,
 		},
 		func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
@@ -69,44 +145,239 @@ func (p *TypeParser) ParseType(pd *gparselib.ParseData, ctx interface{},
 				MapKeyType:   &tKey,
 				MapValueType: &tValue,
 				SrcPos:       pd.Result.Pos,
+				SrcEnd:       srcEnd(pd2),
+			}
+			return pd2, ctx2
+		},
+	)
+
+	pPointer := gparselib.NewParseAllPlugin(
+		[]gparselib.SubparserOp{gparselib.NewParseLiteralPlugin(nil, `*`), pElem},
+		func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+			t := (pd2.SubResults[1].Value).(data.Type)
+			pd2.Result.Value = data.Type{
+				PointerType: &t,
+				SrcPos:      pd2.Result.Pos,
+				SrcEnd:      srcEnd(pd2),
+			}
+			return pd2, ctx2
+		},
+	)
+
+	pArrayLen := func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+		return p.pArrayLen.ParseRegexp(pd2, ctx2, TextSemantic)
+	}
+	pSliceArray := gparselib.NewParseAllPlugin(
+		[]gparselib.SubparserOp{
+			gparselib.NewParseLiteralPlugin(nil, `[`),
+			gparselib.NewParseOptionalPlugin(pArrayLen, nil),
+			gparselib.NewParseLiteralPlugin(nil, `]`),
+			pElem,
+		},
+		parseSliceArraySemantic,
+	)
+
+	pDir := gparselib.NewParseAnyPlugin(
+		[]gparselib.SubparserOp{
+			gparselib.NewParseAllPlugin(
+				[]gparselib.SubparserOp{
+					ParseOptSpc, gparselib.NewParseLiteralPlugin(nil, `<-`), ParseOptSpc,
+				},
+				nil,
+			),
+			ParseASpc,
+		},
+		nil,
+	)
+	pChan := gparselib.NewParseAllPlugin(
+		[]gparselib.SubparserOp{
+			gparselib.NewParseOptionalPlugin(gparselib.NewParseLiteralPlugin(nil, `<-`), nil),
+			gparselib.NewParseLiteralPlugin(nil, `chan`),
+			pDir,
+			pElem,
+		},
+		parseChanSemantic,
+	)
+
+	pTypeList := func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+		return p.parseTypeList(pd2, ctx2)
+	}
+	pParenList := gparselib.NewParseAllPlugin(
+		[]gparselib.SubparserOp{
+			gparselib.NewParseLiteralPlugin(nil, `(`), ParseSpaceComment,
+			pTypeList, ParseSpaceComment,
+			gparselib.NewParseLiteralPlugin(nil, `)`),
+		},
+		func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+			pd2.Result.Value = pd2.SubResults[2].Value
+			return pd2, ctx2
+		},
+	)
+	pResults := gparselib.NewParseAllPlugin(
+		[]gparselib.SubparserOp{
+			ParseOptSpc,
+			gparselib.NewParseAnyPlugin([]gparselib.SubparserOp{pParenList, pElem}, nil),
+		},
+		func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+			if t, ok := pd2.SubResults[1].Value.(data.Type); ok {
+				pd2.Result.Value = []data.Type{t}
+			} else {
+				pd2.Result.Value = pd2.SubResults[1].Value
 			}
 			return pd2, ctx2
 		},
 	)
+	pFunc := gparselib.NewParseAllPlugin(
+		[]gparselib.SubparserOp{
+			gparselib.NewParseLiteralPlugin(nil, `func`), ParseOptSpc,
+			gparselib.NewParseLiteralPlugin(nil, `(`), ParseSpaceComment,
+			gparselib.NewParseOptionalPlugin(pTypeList, nil), ParseSpaceComment,
+			gparselib.NewParseLiteralPlugin(nil, `)`),
+			gparselib.NewParseOptionalPlugin(pResults, nil),
+		},
+		parseFuncSemantic,
+	)
 
-	pOptPack := gparselib.NewParseOptionalPlugin(p.pPack.ParsePackageIdent, nil)
-	pSimpleType := gparselib.NewParseAllPlugin(
-		[]gparselib.SubparserOp{pOptPack, p.pLocalType.ParseLocalTypeIdent},
-		parseSimpleTypeSemantic,
+	pPath := func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+		return p.pPath.ParseRegexp(pd2, ctx2, parseImportPathSemantic)
+	}
+	pOptPack := gparselib.NewParseOptionalPlugin(
+		gparselib.NewParseAnyPlugin([]gparselib.SubparserOp{pPath, p.pPack.ParsePackageIdent}, nil),
+		nil,
 	)
+	pSimple := []gparselib.SubparserOp{pOptPack, p.pLocalType.ParseLocalTypeIdent}
+	if typeArgs {
+		pArgs := gparselib.NewParseAllPlugin(
+			[]gparselib.SubparserOp{
+				gparselib.NewParseLiteralPlugin(nil, `[`), ParseSpaceComment,
+				pTypeList, ParseSpaceComment,
+				gparselib.NewParseLiteralPlugin(nil, `]`),
+			},
+			func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+				pd2.Result.Value = pd2.SubResults[2].Value
+				return pd2, ctx2
+			},
+		)
+		pSimple = append(pSimple, gparselib.NewParseOptionalPlugin(pArgs, nil))
+	}
+	pSimpleType := gparselib.NewParseAllPlugin(pSimple, parseSimpleTypeSemantic)
 
 	return gparselib.ParseAny(
 		pd, ctx,
-		[]gparselib.SubparserOp{pList, pMap, pSimpleType},
+		[]gparselib.SubparserOp{pList, pMap, pPointer, pSliceArray, pChan, pFunc, pSimpleType},
 		nil,
 	)
 }
+
+// parseTypeList parses types separated by commas.
+// It can't use the TypeListParser because that uses the TypeParser itself.
+func (p *TypeParser) parseTypeList(pd *gparselib.ParseData, ctx interface{},
+) (*gparselib.ParseData, interface{}) {
+	pAdditionalType := gparselib.NewParseAllPlugin(
+		[]gparselib.SubparserOp{
+			ParseSpaceComment, gparselib.NewParseLiteralPlugin(nil, `,`),
+			ParseSpaceComment, p.parseAnyType,
+		},
+		func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+			pd2.Result.Value = pd2.SubResults[3].Value
+			return pd2, ctx2
+		},
+	)
+	return gparselib.ParseAll(
+		pd, ctx,
+		[]gparselib.SubparserOp{p.parseAnyType, gparselib.NewParseMulti0Plugin(pAdditionalType, nil)},
+		parseTypeListSemantic,
+	)
+}
+func parseSliceArraySemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseData, interface{}) {
+	t := (pd.SubResults[3].Value).(data.Type)
+	typ := data.Type{SrcPos: pd.Result.Pos, SrcEnd: srcEnd(pd)}
+	if l := pd.SubResults[1].Text; l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil {
+			pd.AddError(pd.SubResults[1].Pos, "invalid array length "+l, err)
+			pd.Result.Value = nil
+			return pd, ctx
+		}
+		typ.ArrayType = &t
+		typ.ArrayLen = n
+	} else {
+		typ.SliceType = &t
+	}
+	pd.Result.Value = typ
+	return pd, ctx
+}
+func parseChanSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseData, interface{}) {
+	recv := pd.SubResults[0].Text != ""
+	send := strings.Contains(pd.SubResults[2].Text, "<-")
+	if recv && send {
+		pd.AddError(pd.Result.Pos, "a channel can't be receive and send only", nil)
+		pd.Result.Value = nil
+		return pd, ctx
+	}
+	t := (pd.SubResults[3].Value).(data.Type)
+	typ := data.Type{ChanType: &t, SrcPos: pd.Result.Pos, SrcEnd: srcEnd(pd)}
+	switch {
+	case recv:
+		typ.ChanDir = data.ChanRecv
+	case send:
+		typ.ChanDir = data.ChanSend
+	}
+	pd.Result.Value = typ
+	return pd, ctx
+}
+func parseFuncSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseData, interface{}) {
+	typ := data.Type{Func: true, SrcPos: pd.Result.Pos, SrcEnd: srcEnd(pd)}
+	if params := pd.SubResults[4].Value; params != nil {
+		typ.Params = params.([]data.Type)
+	}
+	if results := pd.SubResults[7].Value; results != nil {
+		typ.Results = results.([]data.Type)
+	}
+	pd.Result.Value = typ
+	return pd, ctx
+}
+// importPath is the semantic value of a quoted import path in a type.
+type importPath string
+
+func parseImportPathSemantic(pd *gparselib.ParseData, ctx interface{},
+) (*gparselib.ParseData, interface{}) {
+	pd.Result.Value = importPath(pd.Result.Text[1 : len(pd.Result.Text)-2]) // without '"' and '".'
+	return pd, ctx
+}
 func parseSimpleTypeSemantic(pd *gparselib.ParseData, ctx interface{},
 ) (*gparselib.ParseData, interface{}) {
-	val0 := pd.SubResults[0].Value
-	pack := ""
-	if val0 != nil {
-		pack = (val0).(string)
+	pack, path := "", ""
+	switch val0 := pd.SubResults[0].Value.(type) {
+	case string:
+		pack = val0
+	case importPath:
+		path = string(val0)
+		pack = data.PackageName(path)
 	}
 	lType := (pd.SubResults[1].Value).(string)
-	if pack == "" && (lType == "list" || lType == "map") {
+	if pack == "" && (lType == "list" || lType == "map" || lType == "chan" || lType == "func") {
 		pd.AddError(pd.Result.Pos, "keyword '"+lType+"' not allowed as type", nil)
 		pd.Result.Value = nil
 		return pd, ctx
 	}
-	pd.Result.Value = data.Type{
-		Package:   pack,
-		LocalType: lType,
-		SrcPos:    pd.Result.Pos,
+	typ := data.Type{
+		Package:    pack,
+		ImportPath: path,
+		LocalType:  lType,
+		SrcPos:     pd.Result.Pos,
+		SrcEnd:     srcEnd(pd),
+	}
+	if len(pd.SubResults) > 2 && pd.SubResults[2].Value != nil {
+		typ.TypeArgs = (pd.SubResults[2].Value).([]data.Type)
 	}
+	pd.Result.Value = typ
 	return pd, ctx
 }
 
+// errMsgCompName is the error message for a missing component name.
+const errMsgCompName = "a component with a composite type needs a name"
+
 // CompDeclParser is a parser for a component declaration.
 type CompDeclParser struct {
 	pName *NameIdentParser
@@ -121,7 +392,7 @@ func NewCompDeclParser() (*CompDeclParser, error) {
 	if err != nil {
 		return nil, err
 	}
-	pType, err := NewTypeParser()
+	pType, err := newTypeParser(false)
 	if err != nil {
 		return nil, err
 	}
@@ -145,12 +416,18 @@ func (p *CompDeclParser) ParseCompDecl(pd *gparselib.ParseData, ctx interface{})
 		[]gparselib.SubparserOp{pLong, p.pType.ParseType},
 		func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
 			if typ, ok := pd2.Result.Value.(data.Type); ok {
+				if !typ.Named() {
+					pd2.AddError(pd2.Result.Pos, errMsgCompName, nil)
+					pd2.Result.Value = nil
+					return pd2, ctx2
+				}
 				name := nameFromType(typ.LocalType)
 				pd2.Result.Value = data.CompDecl{
 					Name:      name,
 					Type:      typ,
 					VagueType: name == typ.LocalType && typ.Package == "",
 					SrcPos:    pd.Result.Pos,
+					SrcEnd:    typ.SrcEnd,
 				}
 			}
 			return pd2, ctx2
@@ -165,11 +442,13 @@ func parseCompDeclSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib
 		Type:      typeVal,
 		VagueType: false,
 		SrcPos:    pd.Result.Pos,
+		SrcEnd:    srcEnd(pd),
 	}
 	return pd, ctx
 }
 func nameFromType(localType string) string {
-	return strings.ToLower(localType[:1]) + localType[1:]
+	r, n := utf8.DecodeRuneInString(localType)
+	return string(unicode.ToLower(r)) + localType[n:]
 }
 
 // TypeListParser is a parser for types separated by commas.
@@ -277,6 +556,7 @@ func (p *PluginParser) ParsePlugin(pd *gparselib.ParseData, ctx interface{},
 				Name:   val0.(string),
 				Types:  val4.([]data.Type),
 				SrcPos: pd.Result.Pos,
+				SrcEnd: srcEnd(pd2),
 			}
 			return pd2, ctx2
 		},
@@ -289,6 +569,7 @@ func (p *PluginParser) ParsePlugin(pd *gparselib.ParseData, ctx interface{},
 				pd2.Result.Value = data.Plugin{
 					Types:  []data.Type{typ},
 					SrcPos: pd.Result.Pos,
+					SrcEnd: typ.SrcEnd,
 				}
 			}
 			return pd2, ctx2
@@ -406,7 +687,7 @@ func parseFullPluginsSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparse
 	list := pd.SubResults[2].Value
 	if v, ok := list.([](data.Type)); ok {
 		pd.Result.Value = [](data.Plugin){
-			data.Plugin{Name: "", Types: v, SrcPos: v[0].SrcPos},
+			data.Plugin{Name: "", Types: v, SrcPos: v[0].SrcPos, SrcEnd: v[len(v)-1].SrcEnd},
 		}
 	} else {
 		pd.Result.Value = list
@@ -415,10 +696,12 @@ func parseFullPluginsSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparse
 	return pd, ctx
 }
 
-// ComponentParser is a parser for a component including declaration and plugins.
+// ComponentParser is a parser for a component including declaration, plugins
+// and attributes.
 type ComponentParser struct {
 	pcd *CompDeclParser
 	pfp *FullPluginsParser
+	pas *AttributesParser
 }
 
 // NewParseComponent creates a new parser for a complete component.
@@ -433,10 +716,15 @@ func NewParseComponent() (*ComponentParser, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &ComponentParser{pcd: pcd, pfp: pfp}, nil
+	pas, err := NewAttributesParser()
+	if err != nil {
+		return nil, err
+	}
+	return &ComponentParser{pcd: pcd, pfp: pfp, pas: pas}, nil
 }
 
-// ParseComponent parses a component including declaration and plugins.
+// ParseComponent parses a component including declaration, plugins and
+// attributes.
 // * Semantic result: A data.Component.
 //
 // flow:
@@ -444,9 +732,13 @@ func NewParseComponent() (*ComponentParser, error) {
 //                          [ParseSpaceComment, ParseFullPlugins]
 //                      ] -> out
 //     in (gparselib.ParseData)-> [pOpt gparselib.ParseOptional [pPlugins]] -> out
+//     in (gparselib.ParseData)-> [pAttrs gparselib.ParseAll
+//                          [ParseSpaceComment, ParseAttributes]
+//                      ] -> out
+//     in (gparselib.ParseData)-> [pOptAttrs gparselib.ParseOptional [pAttrs]] -> out
 //     in (gparselib.ParseData)-> [gparselib.ParseAll
 //                          [ gparselib.ParseLiteral, ParseSpaceComment, ParseCompDecl,
-//                            pOpt, ParseSpaceComment, gparselib.ParseLiteral          ]
+//                            pOpt, pOptAttrs, ParseSpaceComment, gparselib.ParseLiteral ]
 //                      ] -> out
 func (p *ComponentParser) ParseComponent(pd *gparselib.ParseData, ctx interface{},
 ) (*gparselib.ParseData, interface{}) {
@@ -458,11 +750,22 @@ func (p *ComponentParser) ParseComponent(pd *gparselib.ParseData, ctx interface{
 		},
 	)
 	pOpt := gparselib.NewParseOptionalPlugin(pPlugins, nil)
+	pAttrs := gparselib.NewParseAllPlugin(
+		[]gparselib.SubparserOp{ParseSpaceComment, p.pas.ParseAttributes},
+		func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+			pd2.Result.Value = pd2.SubResults[1].Value
+			return pd2, ctx2
+		},
+	)
+	pOptAttrs := gparselib.NewParseOptionalPlugin(pAttrs, nil)
 	pOpen := gparselib.NewParseLiteralPlugin(nil, `[`)
 	pClose := gparselib.NewParseLiteralPlugin(nil, `]`)
 	return gparselib.ParseAll(
 		pd, ctx,
-		[]gparselib.SubparserOp{pOpen, ParseSpaceComment, p.pcd.ParseCompDecl, pOpt, ParseSpaceComment, pClose},
+		[]gparselib.SubparserOp{
+			pOpen, ParseSpaceComment, p.pcd.ParseCompDecl,
+			pOpt, pOptAttrs, ParseSpaceComment, pClose,
+		},
 		parseComponentSemantic,
 	)
 }
@@ -470,10 +773,14 @@ func parseComponentSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparseli
 	semVal := data.Component{
 		Decl:   (pd.SubResults[2].Value).(data.CompDecl),
 		SrcPos: pd.Result.Pos,
+		SrcEnd: srcEnd(pd),
 	}
 	if pd.SubResults[3].Value != nil {
 		semVal.Plugins = (pd.SubResults[3].Value).([]data.Plugin)
 	}
+	if pd.SubResults[4].Value != nil {
+		semVal.Attrs = (pd.SubResults[4].Value).(data.Attributes)
+	}
 	pd.Result.Value = semVal
 	return pd, ctx
 }
@@ -16,62 +16,63 @@ func TestParseType(t *testing.T) {
 			givenName:        "empty",
 			givenContent:     ``,
 			expectedValue:    nil,
-			expectedErrCount: 4,
+			expectedErrCount: 8,
 		}, {
 			givenName:        "no match 1",
 			givenContent:     `1A`,
 			expectedValue:    nil,
-			expectedErrCount: 4,
+			expectedErrCount: 8,
 		}, {
 			givenName:        "no match 2",
-			givenContent:     `_A`,
+			givenContent:     `.A`,
 			expectedValue:    nil,
-			expectedErrCount: 4,
+			expectedErrCount: 8,
 		}, {
 			givenName:        "no match list",
-			givenContent:     `list(_A)`,
+			givenContent:     `list(.A)`,
 			expectedValue:    nil,
-			expectedErrCount: 7,
+			expectedErrCount: 15,
 		}, {
 			givenName:        "no match map",
 			givenContent:     `map(A, 1B)`,
 			expectedValue:    nil,
-			expectedErrCount: 7,
+			expectedErrCount: 15,
 		}, {
 			givenName:        "simple 1",
 			givenContent:     `Ab`,
-			expectedValue:    data.Type{Package: "", LocalType: "Ab"},
+			expectedValue:    data.Type{Package: "", LocalType: "Ab", SrcEnd: 2},
 			expectedErrCount: 0,
 		}, {
 			givenName:        "simple 2",
 			givenContent:     `a0`,
-			expectedValue:    data.Type{Package: "", LocalType: "a0"},
+			expectedValue:    data.Type{Package: "", LocalType: "a0", SrcEnd: 2},
 			expectedErrCount: 0,
 		}, {
 			givenName:        "simple 3",
 			givenContent:     `Ab_cd`,
-			expectedValue:    data.Type{Package: "", LocalType: "Ab"},
+			expectedValue:    data.Type{Package: "", LocalType: "Ab_cd", SrcEnd: 5},
 			expectedErrCount: 0,
 		}, {
 			givenName:        "simple 4",
 			givenContent:     `abcDef`,
-			expectedValue:    data.Type{Package: "", LocalType: "abcDef"},
+			expectedValue:    data.Type{Package: "", LocalType: "abcDef", SrcEnd: 6},
 			expectedErrCount: 0,
 		}, {
 			givenName:        "complex 1",
 			givenContent:     `p.Ab1Cd`,
-			expectedValue:    data.Type{Package: "p", LocalType: "Ab1Cd"},
+			expectedValue:    data.Type{Package: "p", LocalType: "Ab1Cd", SrcEnd: 7},
 			expectedErrCount: 0,
 		}, {
 			givenName:        "complex 2",
 			givenContent:     `pack.a1Bc_d`,
-			expectedValue:    data.Type{Package: "pack", LocalType: "a1Bc"},
+			expectedValue:    data.Type{Package: "pack", LocalType: "a1Bc_d", SrcEnd: 11},
 			expectedErrCount: 0,
 		}, {
 			givenName:    "simple list",
 			givenContent: "list( \n p.Ab \t )",
 			expectedValue: data.Type{
-				ListType: &data.Type{Package: "p", LocalType: "Ab", SrcPos: 8},
+				ListType: &data.Type{Package: "p", LocalType: "Ab", SrcPos: 8, SrcEnd: 12},
+				SrcEnd:   16,
 			},
 			expectedErrCount: 0,
 		}, {
@@ -79,31 +80,126 @@ func TestParseType(t *testing.T) {
 			givenContent: "list(list(p.Ab))",
 			expectedValue: data.Type{
 				ListType: &data.Type{
-					ListType: &data.Type{Package: "p", LocalType: "Ab", SrcPos: 10},
+					ListType: &data.Type{Package: "p", LocalType: "Ab", SrcPos: 10, SrcEnd: 14},
 					SrcPos:   5,
+					SrcEnd:   15,
 				},
+				SrcEnd: 16,
 			},
 			expectedErrCount: 0,
 		}, {
 			givenName:    "simple map",
 			givenContent: "map( \n p.Ab \t , \t abcDef \n )",
 			expectedValue: data.Type{
-				MapKeyType:   &data.Type{Package: "p", LocalType: "Ab", SrcPos: 7},
-				MapValueType: &data.Type{LocalType: "abcDef", SrcPos: 18},
+				MapKeyType:   &data.Type{Package: "p", LocalType: "Ab", SrcPos: 7, SrcEnd: 11},
+				MapValueType: &data.Type{LocalType: "abcDef", SrcPos: 18, SrcEnd: 24},
+				SrcEnd:       28,
 			},
 			expectedErrCount: 0,
 		}, {
 			givenName:    "recursive map",
 			givenContent: "map(Abc,map(abcDef,a0))",
 			expectedValue: data.Type{
-				MapKeyType: &data.Type{LocalType: "Abc", SrcPos: 4},
+				MapKeyType: &data.Type{LocalType: "Abc", SrcPos: 4, SrcEnd: 7},
 				MapValueType: &data.Type{
-					MapKeyType:   &data.Type{LocalType: "abcDef", SrcPos: 12},
-					MapValueType: &data.Type{LocalType: "a0", SrcPos: 19},
+					MapKeyType:   &data.Type{LocalType: "abcDef", SrcPos: 12, SrcEnd: 18},
+					MapValueType: &data.Type{LocalType: "a0", SrcPos: 19, SrcEnd: 21},
 					SrcPos:       8,
+					SrcEnd:       22,
 				},
+				SrcEnd: 23,
 			},
 			expectedErrCount: 0,
+		}, {
+			givenName:    "pointer and slice",
+			givenContent: "*[]p.Item",
+			expectedValue: data.Type{
+				PointerType: &data.Type{
+					SliceType: &data.Type{Package: "p", LocalType: "Item", SrcPos: 3, SrcEnd: 9},
+					SrcPos:    1,
+					SrcEnd:    9,
+				},
+				SrcEnd: 9,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "array",
+			givenContent: "[16]byte",
+			expectedValue: data.Type{
+				ArrayType: &data.Type{LocalType: "byte", SrcPos: 4, SrcEnd: 8},
+				ArrayLen:  16,
+				SrcEnd:    8,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "channels",
+			givenContent: "<-chan chan<- Order",
+			expectedValue: data.Type{
+				ChanType: &data.Type{
+					ChanType: &data.Type{LocalType: "Order", SrcPos: 14, SrcEnd: 19},
+					ChanDir:  data.ChanSend,
+					SrcPos:   7,
+					SrcEnd:   19,
+				},
+				ChanDir: data.ChanRecv,
+				SrcEnd:  19,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "function",
+			givenContent: "func(Order, *Item) (Result, error)",
+			expectedValue: data.Type{
+				Func: true,
+				Params: []data.Type{
+					{LocalType: "Order", SrcPos: 5, SrcEnd: 10},
+					{PointerType: &data.Type{LocalType: "Item", SrcPos: 13, SrcEnd: 17}, SrcPos: 12, SrcEnd: 17},
+				},
+				Results: []data.Type{
+					{LocalType: "Result", SrcPos: 20, SrcEnd: 26},
+					{LocalType: "error", SrcPos: 28, SrcEnd: 33},
+				},
+				SrcEnd: 34,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "function without results",
+			givenContent: "func()",
+			expectedValue: data.Type{
+				Func:   true,
+				SrcEnd: 6,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "generic",
+			givenContent: "res.Result[map(string, Order), []Item]",
+			expectedValue: data.Type{
+				Package:   "res",
+				LocalType: "Result",
+				TypeArgs: []data.Type{
+					{
+						MapKeyType:   &data.Type{LocalType: "string", SrcPos: 15, SrcEnd: 21},
+						MapValueType: &data.Type{LocalType: "Order", SrcPos: 23, SrcEnd: 28},
+						SrcPos:       11,
+						SrcEnd:       29,
+					},
+					{SliceType: &data.Type{LocalType: "Item", SrcPos: 33, SrcEnd: 37}, SrcPos: 31, SrcEnd: 37},
+				},
+				SrcEnd: 38,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "import path",
+			givenContent: `"github.com/acme/order/v2".Order`,
+			expectedValue: data.Type{
+				Package: "order", ImportPath: "github.com/acme/order/v2", LocalType: "Order",
+				SrcEnd: 32,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:        "keyword chan",
+			givenContent:     "chan",
+			expectedValue:    nil,
+			expectedErrCount: 10,
 		},
 	})
 }
@@ -118,23 +214,24 @@ func TestParseCompDecl(t *testing.T) {
 			givenName:        "empty",
 			givenContent:     ``,
 			expectedValue:    nil,
-			expectedErrCount: 6,
+			expectedErrCount: 10,
 		}, {
 			givenName:        "no match 1",
 			givenContent:     `1A`,
 			expectedValue:    nil,
-			expectedErrCount: 6,
+			expectedErrCount: 10,
 		}, {
 			givenName:        "no match 2",
-			givenContent:     `_A`,
+			givenContent:     `.A`,
 			expectedValue:    nil,
-			expectedErrCount: 6,
+			expectedErrCount: 10,
 		}, {
 			givenName:    "simple 1",
 			givenContent: `A`,
 			expectedValue: data.CompDecl{
-				Name: "a",
-				Type: data.Type{LocalType: "A"},
+				Name:   "a",
+				Type:   data.Type{LocalType: "A", SrcEnd: 1},
+				SrcEnd: 1,
 			},
 			expectedErrCount: 0,
 		}, {
@@ -142,16 +239,18 @@ func TestParseCompDecl(t *testing.T) {
 			givenContent: `a0`,
 			expectedValue: data.CompDecl{
 				Name:      "a0",
-				Type:      data.Type{LocalType: "a0"},
+				Type:      data.Type{LocalType: "a0", SrcEnd: 2},
 				VagueType: true,
+				SrcEnd:    2,
 			},
 			expectedErrCount: 0,
 		}, {
 			givenName:    "simple 3",
 			givenContent: `p.Ab_cd`,
 			expectedValue: data.CompDecl{
-				Name: "ab",
-				Type: data.Type{Package: "p", LocalType: "Ab"},
+				Name:   "ab_cd",
+				Type:   data.Type{Package: "p", LocalType: "Ab_cd", SrcEnd: 7},
+				SrcEnd: 7,
 			},
 			expectedErrCount: 0,
 		}, {
@@ -159,26 +258,56 @@ func TestParseCompDecl(t *testing.T) {
 			givenContent: `abcDef`,
 			expectedValue: data.CompDecl{
 				Name:      "abcDef",
-				Type:      data.Type{LocalType: "abcDef"},
+				Type:      data.Type{LocalType: "abcDef", SrcEnd: 6},
 				VagueType: true,
+				SrcEnd:    6,
 			},
 			expectedErrCount: 0,
 		}, {
 			givenName:    "complex 1",
 			givenContent: `n p.Ab1Cd`,
 			expectedValue: data.CompDecl{
-				Name: "n",
-				Type: data.Type{Package: "p", LocalType: "Ab1Cd", SrcPos: 2},
+				Name:   "n",
+				Type:   data.Type{Package: "p", LocalType: "Ab1Cd", SrcPos: 2, SrcEnd: 9},
+				SrcEnd: 9,
 			},
 			expectedErrCount: 0,
 		}, {
 			givenName:    "complex 2",
 			givenContent: "nam \t pack.a1Bc_d",
 			expectedValue: data.CompDecl{
-				Name: "nam",
-				Type: data.Type{Package: "pack", LocalType: "a1Bc", SrcPos: 6},
+				Name:   "nam",
+				Type:   data.Type{Package: "pack", LocalType: "a1Bc_d", SrcPos: 6, SrcEnd: 17},
+				SrcEnd: 17,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "unicode",
+			givenContent: `bank.Überweisung`,
+			expectedValue: data.CompDecl{
+				Name:   "überweisung",
+				Type:   data.Type{Package: "bank", LocalType: "Überweisung", SrcEnd: 17},
+				SrcEnd: 17,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "pointer type",
+			givenContent: "b *Buffer",
+			expectedValue: data.CompDecl{
+				Name: "b",
+				Type: data.Type{
+					PointerType: &data.Type{LocalType: "Buffer", SrcPos: 3, SrcEnd: 9},
+					SrcPos:      2,
+					SrcEnd:      9,
+				},
+				SrcEnd: 9,
 			},
 			expectedErrCount: 0,
+		}, {
+			givenName:        "composite type without name",
+			givenContent:     "*Buffer",
+			expectedValue:    nil,
+			expectedErrCount: 1,
 		},
 	})
 }
@@ -193,48 +322,48 @@ func TestParseTypeList(t *testing.T) {
 			givenName:        "empty",
 			givenContent:     ``,
 			expectedValue:    nil,
-			expectedErrCount: 4,
+			expectedErrCount: 8,
 		}, {
 			givenName:        "no match 1",
 			givenContent:     `1A`,
 			expectedValue:    nil,
-			expectedErrCount: 4,
+			expectedErrCount: 8,
 		}, {
 			givenName:        "no match 2",
-			givenContent:     `_A`,
+			givenContent:     `.A`,
 			expectedValue:    nil,
-			expectedErrCount: 4,
+			expectedErrCount: 8,
 		}, {
 			givenName:    "simple 1",
 			givenContent: `A`,
 			expectedValue: []data.Type{
-				data.Type{LocalType: "A"},
+				data.Type{LocalType: "A", SrcEnd: 1},
 			},
 			expectedErrCount: 0,
 		}, {
 			givenName:    "simple 2",
 			givenContent: `a,b`,
 			expectedValue: []data.Type{
-				data.Type{LocalType: "a"},
-				data.Type{LocalType: "b", SrcPos: 2},
+				data.Type{LocalType: "a", SrcEnd: 1},
+				data.Type{LocalType: "b", SrcPos: 2, SrcEnd: 3},
 			},
 			expectedErrCount: 0,
 		}, {
 			givenName:    "simple 3",
 			givenContent: `p.A , q.B`,
 			expectedValue: []data.Type{
-				data.Type{Package: "p", LocalType: "A"},
-				data.Type{Package: "q", LocalType: "B", SrcPos: 6},
+				data.Type{Package: "p", LocalType: "A", SrcEnd: 3},
+				data.Type{Package: "q", LocalType: "B", SrcPos: 6, SrcEnd: 9},
 			},
 			expectedErrCount: 0,
 		}, {
 			givenName:    "complex",
 			givenContent: "a, B \t \n, /* comment */ p.C, q.D",
 			expectedValue: []data.Type{
-				data.Type{LocalType: "a"},
-				data.Type{LocalType: "B", SrcPos: 3},
-				data.Type{Package: "p", LocalType: "C", SrcPos: 24},
-				data.Type{Package: "q", LocalType: "D", SrcPos: 29},
+				data.Type{LocalType: "a", SrcEnd: 1},
+				data.Type{LocalType: "B", SrcPos: 3, SrcEnd: 4},
+				data.Type{Package: "p", LocalType: "C", SrcPos: 24, SrcEnd: 27},
+				data.Type{Package: "q", LocalType: "D", SrcPos: 29, SrcEnd: 32},
 			},
 			expectedErrCount: 0,
 		},
@@ -251,25 +380,26 @@ func TestParsePlugin(t *testing.T) {
 			givenName:        "empty",
 			givenContent:     ``,
 			expectedValue:    nil,
-			expectedErrCount: 6,
+			expectedErrCount: 10,
 		}, {
 			givenName:        "no match 1",
 			givenContent:     `1A=bla`,
 			expectedValue:    nil,
-			expectedErrCount: 6,
+			expectedErrCount: 10,
 		}, {
 			givenName:        "no match 2",
 			givenContent:     `=b`,
 			expectedValue:    nil,
-			expectedErrCount: 6,
+			expectedErrCount: 10,
 		}, {
 			givenName:    "simple 1",
 			givenContent: `a=A`,
 			expectedValue: data.Plugin{
 				Name: "a",
 				Types: []data.Type{
-					data.Type{LocalType: "A", SrcPos: 2},
+					data.Type{LocalType: "A", SrcPos: 2, SrcEnd: 3},
 				},
+				SrcEnd: 3,
 			},
 			expectedErrCount: 0,
 		}, {
@@ -277,8 +407,9 @@ func TestParsePlugin(t *testing.T) {
 			givenContent: `a`,
 			expectedValue: data.Plugin{
 				Types: []data.Type{
-					data.Type{LocalType: "a", SrcPos: 0},
+					data.Type{LocalType: "a", SrcPos: 0, SrcEnd: 1},
 				},
+				SrcEnd: 1,
 			},
 			expectedErrCount: 0,
 		}, {
@@ -287,9 +418,10 @@ func TestParsePlugin(t *testing.T) {
 			expectedValue: data.Plugin{
 				Name: "a",
 				Types: []data.Type{
-					data.Type{LocalType: "b", SrcPos: 2},
-					data.Type{LocalType: "C", SrcPos: 5},
+					data.Type{LocalType: "b", SrcPos: 2, SrcEnd: 3},
+					data.Type{LocalType: "C", SrcPos: 5, SrcEnd: 6},
 				},
+				SrcEnd: 6,
 			},
 			expectedErrCount: 0,
 		}, {
@@ -298,9 +430,10 @@ func TestParsePlugin(t *testing.T) {
 			expectedValue: data.Plugin{
 				Name: "tiTle",
 				Types: []data.Type{
-					data.Type{Package: "p", LocalType: "A", SrcPos: 8},
-					data.Type{Package: "q", LocalType: "B", SrcPos: 13},
+					data.Type{Package: "p", LocalType: "A", SrcPos: 8, SrcEnd: 11},
+					data.Type{Package: "q", LocalType: "B", SrcPos: 13, SrcEnd: 16},
 				},
+				SrcEnd: 16,
 			},
 			expectedErrCount: 0,
 		}, {
@@ -309,9 +442,10 @@ func TestParsePlugin(t *testing.T) {
 			expectedValue: data.Plugin{
 				Name: "t",
 				Types: []data.Type{
-					data.Type{Package: "p", LocalType: "C", SrcPos: 21},
-					data.Type{Package: "q", LocalType: "D", SrcPos: 26},
+					data.Type{Package: "p", LocalType: "C", SrcPos: 21, SrcEnd: 24},
+					data.Type{Package: "q", LocalType: "D", SrcPos: 26, SrcEnd: 29},
 				},
+				SrcEnd: 29,
 			},
 			expectedErrCount: 0,
 		},
@@ -328,19 +462,20 @@ func TestParsePluginList(t *testing.T) {
 			givenName:        "empty",
 			givenContent:     ``,
 			expectedValue:    nil,
-			expectedErrCount: 6,
+			expectedErrCount: 10,
 		}, {
 			givenName:        "no match",
 			givenContent:     `|a=b`,
 			expectedValue:    nil,
-			expectedErrCount: 6,
+			expectedErrCount: 10,
 		}, {
 			givenName:    "simple 1",
 			givenContent: `a=A`,
 			expectedValue: []data.Plugin{
 				data.Plugin{
-					Name:  "a",
-					Types: []data.Type{data.Type{LocalType: "A", SrcPos: 2}},
+					Name:   "a",
+					Types:  []data.Type{data.Type{LocalType: "A", SrcPos: 2, SrcEnd: 3}},
+					SrcEnd: 3,
 				},
 			},
 			expectedErrCount: 0,
@@ -349,13 +484,15 @@ func TestParsePluginList(t *testing.T) {
 			givenContent: `a=b|c=D`,
 			expectedValue: []data.Plugin{
 				data.Plugin{
-					Name:  "a",
-					Types: []data.Type{data.Type{LocalType: "b", SrcPos: 2}},
+					Name:   "a",
+					Types:  []data.Type{data.Type{LocalType: "b", SrcPos: 2, SrcEnd: 3}},
+					SrcEnd: 3,
 				},
 				data.Plugin{
 					Name:   "c",
-					Types:  []data.Type{data.Type{LocalType: "D", SrcPos: 6}},
+					Types:  []data.Type{data.Type{LocalType: "D", SrcPos: 6, SrcEnd: 7}},
 					SrcPos: 4,
+					SrcEnd: 7,
 				},
 			},
 			expectedErrCount: 0,
@@ -364,13 +501,15 @@ func TestParsePluginList(t *testing.T) {
 			givenContent: `a=b | c=D`,
 			expectedValue: []data.Plugin{
 				data.Plugin{
-					Name:  "a",
-					Types: []data.Type{data.Type{LocalType: "b", SrcPos: 2}},
+					Name:   "a",
+					Types:  []data.Type{data.Type{LocalType: "b", SrcPos: 2, SrcEnd: 3}},
+					SrcEnd: 3,
 				},
 				data.Plugin{
 					Name:   "c",
-					Types:  []data.Type{data.Type{LocalType: "D", SrcPos: 8}},
+					Types:  []data.Type{data.Type{LocalType: "D", SrcPos: 8, SrcEnd: 9}},
 					SrcPos: 6,
+					SrcEnd: 9,
 				},
 			},
 			expectedErrCount: 0,
@@ -379,19 +518,23 @@ func TestParsePluginList(t *testing.T) {
 			givenContent: `a | b|c|d`,
 			expectedValue: []data.Plugin{
 				data.Plugin{
-					Types: []data.Type{data.Type{LocalType: "a", SrcPos: 0}},
+					Types:  []data.Type{data.Type{LocalType: "a", SrcPos: 0, SrcEnd: 1}},
+					SrcEnd: 1,
 				},
 				data.Plugin{
-					Types:  []data.Type{data.Type{LocalType: "b", SrcPos: 4}},
+					Types:  []data.Type{data.Type{LocalType: "b", SrcPos: 4, SrcEnd: 5}},
 					SrcPos: 4,
+					SrcEnd: 5,
 				},
 				data.Plugin{
-					Types:  []data.Type{data.Type{LocalType: "c", SrcPos: 6}},
+					Types:  []data.Type{data.Type{LocalType: "c", SrcPos: 6, SrcEnd: 7}},
 					SrcPos: 6,
+					SrcEnd: 7,
 				},
 				data.Plugin{
-					Types:  []data.Type{data.Type{LocalType: "d", SrcPos: 8}},
+					Types:  []data.Type{data.Type{LocalType: "d", SrcPos: 8, SrcEnd: 9}},
 					SrcPos: 8,
+					SrcEnd: 9,
 				},
 			},
 			expectedErrCount: 0,
@@ -400,13 +543,15 @@ func TestParsePluginList(t *testing.T) {
 			givenContent: "a=b \t \n| /* comment */ c=D",
 			expectedValue: []data.Plugin{
 				data.Plugin{
-					Name:  "a",
-					Types: []data.Type{data.Type{LocalType: "b", SrcPos: 2}},
+					Name:   "a",
+					Types:  []data.Type{data.Type{LocalType: "b", SrcPos: 2, SrcEnd: 3}},
+					SrcEnd: 3,
 				},
 				data.Plugin{
 					Name:   "c",
-					Types:  []data.Type{data.Type{LocalType: "D", SrcPos: 25}},
+					Types:  []data.Type{data.Type{LocalType: "D", SrcPos: 25, SrcEnd: 26}},
 					SrcPos: 23,
+					SrcEnd: 26,
 				},
 			},
 			expectedErrCount: 0,
@@ -436,8 +581,9 @@ func TestParseFullPlugins(t *testing.T) {
 			expectedValue: []data.Plugin{
 				data.Plugin{
 					Name:   "a",
-					Types:  []data.Type{data.Type{LocalType: "A", SrcPos: 3}},
+					Types:  []data.Type{data.Type{LocalType: "A", SrcPos: 3, SrcEnd: 4}},
 					SrcPos: 1,
+					SrcEnd: 4,
 				},
 			},
 			expectedErrCount: 0,
@@ -446,8 +592,9 @@ func TestParseFullPlugins(t *testing.T) {
 			givenContent: `[a]`,
 			expectedValue: []data.Plugin{
 				data.Plugin{
-					Types:  []data.Type{data.Type{LocalType: "a", SrcPos: 1}},
+					Types:  []data.Type{data.Type{LocalType: "a", SrcPos: 1, SrcEnd: 2}},
 					SrcPos: 1,
+					SrcEnd: 2,
 				},
 			},
 			expectedErrCount: 0,
@@ -458,10 +605,11 @@ func TestParseFullPlugins(t *testing.T) {
 				data.Plugin{
 					Name: "a",
 					Types: []data.Type{
-						data.Type{LocalType: "b", SrcPos: 4},
-						data.Type{LocalType: "D", SrcPos: 6},
+						data.Type{LocalType: "b", SrcPos: 4, SrcEnd: 5},
+						data.Type{LocalType: "D", SrcPos: 6, SrcEnd: 7},
 					},
 					SrcPos: 2,
+					SrcEnd: 7,
 				},
 			},
 			expectedErrCount: 0,
@@ -470,16 +618,19 @@ func TestParseFullPlugins(t *testing.T) {
 			givenContent: `[ a|B|c ]`,
 			expectedValue: []data.Plugin{
 				data.Plugin{
-					Types:  []data.Type{data.Type{LocalType: "a", SrcPos: 2}},
+					Types:  []data.Type{data.Type{LocalType: "a", SrcPos: 2, SrcEnd: 3}},
 					SrcPos: 2,
+					SrcEnd: 3,
 				},
 				data.Plugin{
-					Types:  []data.Type{data.Type{LocalType: "B", SrcPos: 4}},
+					Types:  []data.Type{data.Type{LocalType: "B", SrcPos: 4, SrcEnd: 5}},
 					SrcPos: 4,
+					SrcEnd: 5,
 				},
 				data.Plugin{
-					Types:  []data.Type{data.Type{LocalType: "c", SrcPos: 6}},
+					Types:  []data.Type{data.Type{LocalType: "c", SrcPos: 6, SrcEnd: 7}},
 					SrcPos: 6,
+					SrcEnd: 7,
 				},
 			},
 			expectedErrCount: 0,
@@ -489,12 +640,13 @@ func TestParseFullPlugins(t *testing.T) {
 			expectedValue: []data.Plugin{
 				data.Plugin{
 					Types: []data.Type{
-						data.Type{LocalType: "a", SrcPos: 2},
-						data.Type{LocalType: "B", SrcPos: 4},
-						data.Type{LocalType: "c", SrcPos: 6},
-						data.Type{LocalType: "D", SrcPos: 8},
+						data.Type{LocalType: "a", SrcPos: 2, SrcEnd: 3},
+						data.Type{LocalType: "B", SrcPos: 4, SrcEnd: 5},
+						data.Type{LocalType: "c", SrcPos: 6, SrcEnd: 7},
+						data.Type{LocalType: "D", SrcPos: 8, SrcEnd: 9},
 					},
 					SrcPos: 2,
+					SrcEnd: 9,
 				},
 			},
 			expectedErrCount: 0,
@@ -504,13 +656,15 @@ func TestParseFullPlugins(t *testing.T) {
 			expectedValue: []data.Plugin{
 				data.Plugin{
 					Name:   "a",
-					Types:  []data.Type{data.Type{LocalType: "b", SrcPos: 7}},
+					Types:  []data.Type{data.Type{LocalType: "b", SrcPos: 7, SrcEnd: 8}},
 					SrcPos: 5,
+					SrcEnd: 8,
 				},
 				data.Plugin{
 					Name:   "c",
-					Types:  []data.Type{data.Type{LocalType: "D", SrcPos: 11}},
+					Types:  []data.Type{data.Type{LocalType: "D", SrcPos: 11, SrcEnd: 12}},
 					SrcPos: 9,
+					SrcEnd: 12,
 				},
 			},
 			expectedErrCount: 0,
@@ -539,10 +693,12 @@ func TestParseComponent(t *testing.T) {
 			givenContent: `[a A]`,
 			expectedValue: data.Component{
 				Decl: data.CompDecl{
-					Name: "a", Type: data.Type{LocalType: "A", SrcPos: 3},
+					Name: "a", Type: data.Type{LocalType: "A", SrcPos: 3, SrcEnd: 4},
 					SrcPos: 1,
+					SrcEnd: 4,
 				},
 				Plugins: nil,
+				SrcEnd:  5,
 			},
 			expectedErrCount: 0,
 		}, {
@@ -550,35 +706,77 @@ func TestParseComponent(t *testing.T) {
 			givenContent: `[a B[c,D]]`,
 			expectedValue: data.Component{
 				Decl: data.CompDecl{
-					Name: "a", Type: data.Type{LocalType: "B", SrcPos: 3},
+					Name: "a", Type: data.Type{LocalType: "B", SrcPos: 3, SrcEnd: 4},
 					SrcPos: 1,
+					SrcEnd: 4,
 				},
 				Plugins: []data.Plugin{
 					data.Plugin{
 						Name: "",
 						Types: []data.Type{
-							data.Type{LocalType: "c", SrcPos: 5},
-							data.Type{LocalType: "D", SrcPos: 7},
+							data.Type{LocalType: "c", SrcPos: 5, SrcEnd: 6},
+							data.Type{LocalType: "D", SrcPos: 7, SrcEnd: 8},
 						},
 						SrcPos: 5,
+						SrcEnd: 8,
 					},
 				},
+				SrcEnd: 10,
 			},
 		}, {
 			givenName:    "simple 3",
 			givenContent: `[ a B [c=D] ]`,
 			expectedValue: data.Component{
 				Decl: data.CompDecl{
-					Name: "a", Type: data.Type{LocalType: "B", SrcPos: 4},
+					Name: "a", Type: data.Type{LocalType: "B", SrcPos: 4, SrcEnd: 5},
 					SrcPos: 2,
+					SrcEnd: 5,
 				},
 				Plugins: []data.Plugin{
 					data.Plugin{
 						Name:   "c",
-						Types:  []data.Type{data.Type{LocalType: "D", SrcPos: 9}},
+						Types:  []data.Type{data.Type{LocalType: "D", SrcPos: 9, SrcEnd: 10}},
 						SrcPos: 7,
+						SrcEnd: 10,
+					},
+				},
+				SrcEnd: 13,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "attributes",
+			givenContent: `[fetch HttpGet {timeout: "5s", owner: team-a}]`,
+			expectedValue: data.Component{
+				Decl: data.CompDecl{
+					Name: "fetch", Type: data.Type{LocalType: "HttpGet", SrcPos: 7, SrcEnd: 14},
+					SrcPos: 1,
+					SrcEnd: 14,
+				},
+				Attrs: data.Attributes{
+					{Key: "timeout", Value: "5s", SrcPos: 16, SrcEnd: 29},
+					{Key: "owner", Value: "team-a", SrcPos: 31, SrcEnd: 44},
+				},
+				SrcEnd: 46,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "plugins and attributes",
+			givenContent: `[a B [C] {async}]`,
+			expectedValue: data.Component{
+				Decl: data.CompDecl{
+					Name: "a", Type: data.Type{LocalType: "B", SrcPos: 3, SrcEnd: 4},
+					SrcPos: 1,
+					SrcEnd: 4,
+				},
+				Plugins: []data.Plugin{
+					data.Plugin{
+						Types:  []data.Type{data.Type{LocalType: "C", SrcPos: 6, SrcEnd: 7}},
+						SrcPos: 6,
+						SrcEnd: 7,
 					},
 				},
+				Attrs:  data.Attributes{{Key: "async", SrcPos: 10, SrcEnd: 15}},
+				SrcEnd: 17,
 			},
 			expectedErrCount: 0,
 		}, {
@@ -586,16 +784,19 @@ func TestParseComponent(t *testing.T) {
 			givenContent: "[ \t \na B /* comment 1 */ [c=D] // comment 2\n ]",
 			expectedValue: data.Component{
 				Decl: data.CompDecl{
-					Name: "a", Type: data.Type{LocalType: "B", SrcPos: 7},
+					Name: "a", Type: data.Type{LocalType: "B", SrcPos: 7, SrcEnd: 8},
 					SrcPos: 5,
+					SrcEnd: 8,
 				},
 				Plugins: []data.Plugin{
 					data.Plugin{
 						Name:   "c",
-						Types:  []data.Type{data.Type{LocalType: "D", SrcPos: 28}},
+						Types:  []data.Type{data.Type{LocalType: "D", SrcPos: 28, SrcEnd: 29}},
 						SrcPos: 26,
+						SrcEnd: 29,
 					},
 				},
+				SrcEnd: 46,
 			},
 			expectedErrCount: 0,
 		}, {
@@ -603,27 +804,31 @@ func TestParseComponent(t *testing.T) {
 			givenContent: "[a B [c=D,E|f=G,H]]",
 			expectedValue: data.Component{
 				Decl: data.CompDecl{
-					Name: "a", Type: data.Type{LocalType: "B", SrcPos: 3},
+					Name: "a", Type: data.Type{LocalType: "B", SrcPos: 3, SrcEnd: 4},
 					SrcPos: 1,
+					SrcEnd: 4,
 				},
 				Plugins: []data.Plugin{
 					data.Plugin{
 						Name: "c",
 						Types: []data.Type{
-							data.Type{LocalType: "D", SrcPos: 8},
-							data.Type{LocalType: "E", SrcPos: 10},
+							data.Type{LocalType: "D", SrcPos: 8, SrcEnd: 9},
+							data.Type{LocalType: "E", SrcPos: 10, SrcEnd: 11},
 						},
 						SrcPos: 6,
+						SrcEnd: 11,
 					},
 					data.Plugin{
 						Name: "f",
 						Types: []data.Type{
-							data.Type{LocalType: "G", SrcPos: 14},
-							data.Type{LocalType: "H", SrcPos: 16},
+							data.Type{LocalType: "G", SrcPos: 14, SrcEnd: 15},
+							data.Type{LocalType: "H", SrcPos: 16, SrcEnd: 17},
 						},
 						SrcPos: 12,
+						SrcEnd: 17,
 					},
 				},
+				SrcEnd: 19,
 			},
 			expectedErrCount: 0,
 		}, {
@@ -631,19 +836,23 @@ func TestParseComponent(t *testing.T) {
 			givenContent: "[a B [c|d]]",
 			expectedValue: data.Component{
 				Decl: data.CompDecl{
-					Name: "a", Type: data.Type{LocalType: "B", SrcPos: 3},
+					Name: "a", Type: data.Type{LocalType: "B", SrcPos: 3, SrcEnd: 4},
 					SrcPos: 1,
+					SrcEnd: 4,
 				},
 				Plugins: []data.Plugin{
 					data.Plugin{
-						Types:  []data.Type{data.Type{LocalType: "c", SrcPos: 6}},
+						Types:  []data.Type{data.Type{LocalType: "c", SrcPos: 6, SrcEnd: 7}},
 						SrcPos: 6,
+						SrcEnd: 7,
 					},
 					data.Plugin{
-						Types:  []data.Type{data.Type{LocalType: "d", SrcPos: 8}},
+						Types:  []data.Type{data.Type{LocalType: "d", SrcPos: 8, SrcEnd: 9}},
 						SrcPos: 8,
+						SrcEnd: 9,
 					},
 				},
+				SrcEnd: 11,
 			},
 			expectedErrCount: 0,
 		}, {
@@ -651,19 +860,22 @@ func TestParseComponent(t *testing.T) {
 			givenContent: "[a [b,C]]",
 			expectedValue: data.Component{
 				Decl: data.CompDecl{
-					Name: "a", Type: data.Type{LocalType: "a", SrcPos: 1},
+					Name: "a", Type: data.Type{LocalType: "a", SrcPos: 1, SrcEnd: 2},
 					VagueType: true,
 					SrcPos:    1,
+					SrcEnd:    2,
 				},
 				Plugins: []data.Plugin{
 					data.Plugin{
 						Types: []data.Type{
-							data.Type{LocalType: "b", SrcPos: 4},
-							data.Type{LocalType: "C", SrcPos: 6},
+							data.Type{LocalType: "b", SrcPos: 4, SrcEnd: 5},
+							data.Type{LocalType: "C", SrcPos: 6, SrcEnd: 7},
 						},
 						SrcPos: 4,
+						SrcEnd: 7,
 					},
 				},
+				SrcEnd: 9,
 			},
 			expectedErrCount: 0,
 		},
@@ -24,15 +24,19 @@ func NewPortParser() (*PortParser, error) {
 	return &PortParser{pName: pName}, nil
 }
 
-// ParsePort parses a port including optional index.
+// ParsePort parses a port including optional index or index range
+// ('out:1..4').
 // * Semantic result: data.Port
 //
 // flow:
-//     in (gparselib.ParseData)-> [pIndex gparselib.ParseAll [gparselib.ParseLiteral, gparselib.ParseNatural]] -> out
+//     in (gparselib.ParseData)-> [pRange gparselib.ParseAll [gparselib.ParseLiteral, gparselib.ParseNatural]] -> out
+//     in (gparselib.ParseData)-> [pOptRange gparselib.ParseOptional [pRange]] -> out
+//     in (gparselib.ParseData)-> [pIndex gparselib.ParseAll [gparselib.ParseLiteral, gparselib.ParseNatural, pOptRange]] -> out
 //     in (gparselib.ParseData)-> [pOptIdx gparselib.ParseOptional [pIndex]] -> out
 //     in (gparselib.ParseData)-> [pNormPort gparselib.ParseAll [ParseNameIdent, pOptIdx]] -> out
 //     in (gparselib.ParseData)-> [pDots gparselib.ParseLiteral] -> out
-//     in (gparselib.ParseData)-> [pContinuation gparselib.ParseAll [pDots, gparselib.ParseNatural]] -> out
+//     in (gparselib.ParseData)-> [pContID gparselib.ParseAny [gparselib.ParseNatural, ParseNameIdent]] -> out
+//     in (gparselib.ParseData)-> [pContinuation gparselib.ParseAll [pDots, pContID]] -> out
 //     in (gparselib.ParseData)-> [gparselib.ParseAll [pContinuation, pNormPort]] -> out
 func (p *PortParser) ParsePort(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseData, interface{}) {
 	pColon := gparselib.NewParseLiteralPlugin(nil, `:`)
@@ -40,17 +44,31 @@ func (p *PortParser) ParsePort(pd *gparselib.ParseData, ctx interface{}) (*gpars
 	if err != nil {
 		panic(err)
 	}
-	pIndex := gparselib.NewParseAllPlugin([]gparselib.SubparserOp{pColon, pNumber},
+	pRange := gparselib.NewParseAllPlugin(
+		[]gparselib.SubparserOp{gparselib.NewParseLiteralPlugin(nil, `..`), pNumber},
 		func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
 			pd2.Result.Value = pd2.SubResults[1].Value
 			return pd2, ctx2
 		},
 	)
+	pOptRange := gparselib.NewParseOptionalPlugin(pRange, nil)
+	pIndex := gparselib.NewParseAllPlugin([]gparselib.SubparserOp{pColon, pNumber, pOptRange},
+		func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+			idx := portIndex{start: int(pd2.SubResults[1].Value.(uint64))}
+			if end := pd2.SubResults[2].Value; end != nil {
+				idx.isRange = true
+				idx.end = int(end.(uint64))
+			}
+			pd2.Result.Value = idx
+			return pd2, ctx2
+		},
+	)
 	pOptIdx := gparselib.NewParseOptionalPlugin(pIndex, nil)
 	pNormPort := gparselib.NewParseAllPlugin([]gparselib.SubparserOp{p.pName.ParseNameIdent, pOptIdx}, parsePortSemantic)
 
 	pDots := gparselib.NewParseLiteralPlugin(nil, `...`)
-	pContinuation := gparselib.NewParseAllPlugin([]gparselib.SubparserOp{pDots, pNumber}, parseContinuationPortSemantic)
+	pContID := gparselib.NewParseAnyPlugin([]gparselib.SubparserOp{pNumber, p.pName.ParseNameIdent}, nil)
+	pContinuation := gparselib.NewParseAllPlugin([]gparselib.SubparserOp{pDots, pContID}, parseContinuationPortSemantic)
 
 	return gparselib.ParseAny(pd, ctx, []gparselib.SubparserOp{pContinuation, pNormPort}, nil)
 }
@@ -59,21 +77,38 @@ func parsePortSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.Par
 	port := data.Port{
 		Name:   (pd.SubResults[0].Value).(string),
 		SrcPos: pd.Result.Pos,
+		SrcEnd: srcEnd(pd),
 	}
 	if val1 != nil {
+		idx := val1.(portIndex)
 		port.HasIndex = true
-		port.Index = int((val1).(uint64))
+		port.Index = idx.start
+		port.HasRange = idx.isRange
+		port.IndexEnd = idx.end
 	}
 	pd.Result.Value = port
 	return pd, ctx
 }
+
+// portIndex is the index or index range of a port.
+type portIndex struct {
+	start, end int
+	isRange    bool
+}
+
 func parseContinuationPortSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseData, interface{}) {
-	val1 := pd.SubResults[1].Value.(uint64)
-	pd.Result.Value = data.Port{
+	port := data.Port{
 		Name:   data.ContinuationSignal,
-		Index:  int(val1),
 		SrcPos: pd.Result.Pos,
+		SrcEnd: srcEnd(pd),
 	}
+	switch val1 := pd.SubResults[1].Value.(type) {
+	case uint64:
+		port.Index = int(val1)
+	case string:
+		port.Label = val1
+	}
+	pd.Result.Value = port
 	return pd, ctx
 }
 
@@ -138,8 +173,9 @@ func parseMultiTypeListSemantic(pd *gparselib.ParseData, ctx interface{}) (*gpar
 
 // ArrowParser is a parser for a flow arrow including ports and data types.
 type ArrowParser struct {
-	pPort *PortParser
-	pData *MultiTypeListParser
+	pPort  *PortParser
+	pData  *MultiTypeListParser
+	pAttrs *AttributesParser
 }
 
 // NewArrowParser creates a new parser for a flow arrow.
@@ -154,10 +190,14 @@ func NewArrowParser() (*ArrowParser, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &ArrowParser{pPort: pPort, pData: pData}, nil
+	pAttrs, err := NewAttributesParser()
+	if err != nil {
+		return nil, err
+	}
+	return &ArrowParser{pPort: pPort, pData: pData, pAttrs: pAttrs}, nil
 }
 
-// ParseArrow parses a flow arrow including ports and data types.
+// ParseArrow parses a flow arrow including ports, data types and attributes.
 // * Semantic result: data.Arrow
 //
 // flow:
@@ -172,8 +212,12 @@ func NewArrowParser() (*ArrowParser, error) {
 //                          ]
 //                      ] -> out
 //     in (gparselib.ParseData)-> [pOptData gparselib.ParseOptional [pData]] -> out
+//     in (gparselib.ParseData)-> [pAttrs gparselib.ParseAll
+//                          [ParseAttributes, ParseOptSpc]
+//                      ] -> out
+//     in (gparselib.ParseData)-> [pOptAttrs gparselib.ParseOptional [pAttrs]] -> out
 //     in (gparselib.ParseData)-> [gparselib.ParseAll
-//                          [pOptPort, ParseOptSpc, pOptData,
+//                          [pOptPort, ParseOptSpc, pOptData, pOptAttrs,
 //                           pArrow, ParseOptSpc, pOptPort
 //                          ]
 //                      ] -> out
@@ -194,10 +238,18 @@ func (p *ArrowParser) ParseArrow(pd *gparselib.ParseData, ctx interface{}) (*gpa
 		},
 	)
 	pOptData := gparselib.NewParseOptionalPlugin(pData, nil)
+	pAttrs := gparselib.NewParseAllPlugin(
+		[]gparselib.SubparserOp{p.pAttrs.ParseAttributes, ParseOptSpc},
+		func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+			pd2.Result.Value = pd2.SubResults[0].Value
+			return pd2, ctx2
+		},
+	)
+	pOptAttrs := gparselib.NewParseOptionalPlugin(pAttrs, nil)
 
 	return gparselib.ParseAll(pd, ctx,
 		[]gparselib.SubparserOp{
-			pOptPort, ParseOptSpc, pOptData,
+			pOptPort, ParseOptSpc, pOptData, pOptAttrs,
 			pArrow, ParseOptSpc, pOptPort,
 		},
 		parseArrowSemantic,
@@ -206,8 +258,9 @@ func (p *ArrowParser) ParseArrow(pd *gparselib.ParseData, ctx interface{}) (*gpa
 func parseArrowSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseData, interface{}) {
 	val0 := pd.SubResults[0].Value
 	val2 := pd.SubResults[2].Value
-	val5 := pd.SubResults[5].Value
-	arrow := data.Arrow{SrcPos: pd.Result.Pos}
+	val3 := pd.SubResults[3].Value
+	val6 := pd.SubResults[6].Value
+	arrow := data.Arrow{SrcPos: pd.Result.Pos, SrcEnd: srcEnd(pd)}
 	if val0 != nil {
 		port := (val0).(data.Port)
 		arrow.FromPort = &port
@@ -215,18 +268,144 @@ func parseArrowSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.Pa
 	if val2 != nil {
 		arrow.Data = (val2).([]data.Type)
 	}
-	if val5 != nil {
-		port := (val5).(data.Port)
+	if val3 != nil {
+		arrow.Attrs = (val3).(data.Attributes)
+	}
+	if val6 != nil {
+		port := (val6).(data.Port)
 		arrow.ToPort = &port
 	}
 	pd.Result.Value = arrow
 	return pd, ctx
 }
 
-// FlowParser is a parser for a complete flow.
+// ImportParser is a parser for an import statement.
+type ImportParser struct {
+	pPath *gparselib.RegexpParser
+}
+
+// NewImportParser creates a new parser for an import statement.
+// If any regular expression used by the subparsers is invalid an error is
+// returned.
+func NewImportParser() (*ImportParser, error) {
+	pPath, err := gparselib.NewRegexpParser(`^"[^"\n]+"`)
+	if err != nil {
+		return nil, err
+	}
+	return &ImportParser{pPath: pPath}, nil
+}
+
+// ParseImport parses an import statement: import "path/to/file.flow"
+// * Semantic result: data.Import
+//
+// flow:
+//     in (gparselib.ParseData)-> [pKeyword gparselib.ParseLiteral] -> out
+//     in (gparselib.ParseData)-> [pPath gparselib.ParseRegexp] -> out
+//     in (gparselib.ParseData)-> [gparselib.ParseAll
+//                          [pKeyword, ParseASpc, pPath, ParseStatementEnd]
+//                      ] -> out
+func (p *ImportParser) ParseImport(pd *gparselib.ParseData, ctx interface{},
+) (*gparselib.ParseData, interface{}) {
+	pKeyword := gparselib.NewParseLiteralPlugin(nil, `import`)
+	pPath := func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+		return p.pPath.ParseRegexp(pd2, ctx2, TextSemantic)
+	}
+	return gparselib.ParseAll(pd, ctx,
+		[]gparselib.SubparserOp{pKeyword, ParseASpc, pPath, ParseStatementEnd},
+		parseImportSemantic,
+	)
+}
+func parseImportSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseData, interface{}) {
+	path := pd.SubResults[2]
+	pd.Result.Value = data.Import{
+		Path:   path.Text[1 : len(path.Text)-1],
+		SrcPos: pd.Result.Pos,
+		SrcEnd: path.Pos + len(path.Text),
+	}
+	return pd, ctx
+}
+
+// PackageDeclParser is a parser for a package declaration.
+type PackageDeclParser struct {
+	pName *gparselib.RegexpParser
+	pPath *gparselib.RegexpParser
+}
+
+// NewPackageDeclParser creates a new parser for a package declaration.
+// If any regular expression used by the subparsers is invalid an error is
+// returned.
+func NewPackageDeclParser() (*PackageDeclParser, error) {
+	pName, err := gparselib.NewRegexpParser(`^` + packageIdent)
+	if err != nil {
+		return nil, err
+	}
+	pPath, err := gparselib.NewRegexpParser(`^"[^"\s]+"`)
+	if err != nil {
+		return nil, err
+	}
+	return &PackageDeclParser{pName: pName, pPath: pPath}, nil
+}
+
+// ParsePackageDecl parses a package declaration with optional name:
+// package order "github.com/acme/order"
+// * Semantic result: data.PackageDecl
+//
+// flow:
+//     in (gparselib.ParseData)-> [pKeyword gparselib.ParseLiteral] -> out
+//     in (gparselib.ParseData)-> [pName gparselib.ParseRegexp] -> out
+//     in (gparselib.ParseData)-> [pPath gparselib.ParseRegexp] -> out
+//     in (gparselib.ParseData)-> [pNameSpc gparselib.ParseAll [pName, ParseASpc]] -> out
+//     in (gparselib.ParseData)-> [pOptName gparselib.ParseOptional [pNameSpc]] -> out
+//     in (gparselib.ParseData)-> [gparselib.ParseAll
+//                          [pKeyword, ParseASpc, pOptName, pPath, ParseStatementEnd]
+//                      ] -> out
+func (p *PackageDeclParser) ParsePackageDecl(pd *gparselib.ParseData, ctx interface{},
+) (*gparselib.ParseData, interface{}) {
+	pKeyword := gparselib.NewParseLiteralPlugin(nil, `package`)
+	pName := func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+		return p.pName.ParseRegexp(pd2, ctx2, TextSemantic)
+	}
+	pPath := func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+		return p.pPath.ParseRegexp(pd2, ctx2, TextSemantic)
+	}
+	pOptName := gparselib.NewParseOptionalPlugin(
+		gparselib.NewParseAllPlugin(
+			[]gparselib.SubparserOp{pName, ParseASpc},
+			func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+				pd2.Result.Value = pd2.SubResults[0].Value
+				return pd2, ctx2
+			},
+		),
+		nil,
+	)
+	return gparselib.ParseAll(pd, ctx,
+		[]gparselib.SubparserOp{pKeyword, ParseASpc, pOptName, pPath, ParseStatementEnd},
+		parsePackageDeclSemantic,
+	)
+}
+func parsePackageDeclSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseData, interface{}) {
+	path := pd.SubResults[3]
+	decl := data.PackageDecl{
+		Path:   path.Text[1 : len(path.Text)-1],
+		SrcPos: pd.Result.Pos,
+		SrcEnd: path.Pos + len(path.Text),
+	}
+	if name := pd.SubResults[2].Value; name != nil {
+		decl.Name = name.(string)
+	} else {
+		decl.Name = data.PackageName(decl.Path)
+	}
+	pd.Result.Value = decl
+	return pd, ctx
+}
+
+// FlowParser is a parser for a complete flow or flow file.
 type FlowParser struct {
-	pArrow *ArrowParser
-	pComp  *ComponentParser
+	pArrow  *ArrowParser
+	pComp   *ComponentParser
+	pName   *NameIdentParser
+	pImport *ImportParser
+	pPack   *PackageDeclParser
 }
 
 // Error messages for semantic errors.
@@ -246,6 +425,13 @@ const (
 	errMsgContStart   = "The continuation at the very start of flow line %d can't exists before its counter part"
 	errMsg2ContEnd    = "The continuation at the very end of flow line %d is doubled at flow line %d"
 	errMsgContData    = "The continuation at the very end of flow line %d has got an invalid data annotation"
+	errMsg2Flows      = "The flow '%s' is declared twice"
+	errMsg2Attrs      = "The attribute '%s' is set twice"
+	errMsg2Packages   = "The package '%s' is declared twice"
+	errMsgRangeDesc   = "The port range %d..%d of port '%s' is descending"
+	errMsgRangeLens   = "The port ranges of this arrow have got different lengths (%d and %d)"
+	errMsgRangeIndex  = "The index %d of the port range '%s:%d..%d' is used more than once"
+	errMsgRangeCont   = "A port range can't be combined with a continuation"
 )
 
 // NewFlowParser creates a new parser for a flow.
@@ -260,22 +446,57 @@ func NewFlowParser() (*FlowParser, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &FlowParser{pArrow: pArrow, pComp: pComp}, nil
+	pName, err := NewNameIdentParser()
+	if err != nil {
+		return nil, err
+	}
+	pImport, err := NewImportParser()
+	if err != nil {
+		return nil, err
+	}
+	pPack, err := NewPackageDeclParser()
+	if err != nil {
+		return nil, err
+	}
+	return &FlowParser{
+		pArrow: pArrow, pComp: pComp, pName: pName, pImport: pImport, pPack: pPack,
+	}, nil
 }
 
 // ParseFlow parses a complete flow.
 // * Semantic result: data.Flow
 //
 // flow:
+//     in (gparselib.ParseData)-> [gparselib.ParseAll [ParseSpaceComment, ParseFlowLines, gparselib.ParseEOF]] -> out
+func (p *FlowParser) ParseFlow(pd *gparselib.ParseData, ctx interface{},
+) (*gparselib.ParseData, interface{}) {
+	pEOF := gparselib.NewParseEOFPlugin(nil)
+	return gparselib.ParseAll(pd, ctx,
+		[]gparselib.SubparserOp{ParseSpaceComment, p.ParseFlowLines, pEOF},
+		func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
+			pd2.Result.Value = pd2.SubResults[1].Value
+			return pd2, ctx2
+		},
+	)
+}
+
+// ParseFlowLines parses the lines of a flow including optional imports and
+// package declarations at the start.
+// The types of declared packages carry the import path of their package.
+// * Semantic result: data.Flow
+//
+// flow:
+//     in (gparselib.ParseData)-> [pHeader gparselib.ParseAny [ParseImport, ParsePackageDecl]] -> out
+//     in (gparselib.ParseData)-> [pHeaders gparselib.ParseMulti0 [pHeader]] -> out
 //     in (gparselib.ParseData)-> [pAnyPart gparselib.ParseAny [ParseArrow, ParseComponent]] -> out
 //     in (gparselib.ParseData)-> [pFullPart gparselib.ParseAll [pAnyPart, ParseOptSpc]] -> out
 //     in (gparselib.ParseData)-> [pPartSequence gparselib.ParseMulti [pFullPart]] -> out
 //     in (gparselib.ParseData)-> [pPartLine gparselib.ParseAll
 //                          [pPartSequence, ParseStatementEnd]
 //                      ] -> out
-//     in (gparselib.ParseData)-> [pLines gparselib.ParseMulti1 [pPartLine]] -> out
-//     in (gparselib.ParseData)-> [gparselib.ParseAll [ParseSpaceComment, pLines, gparselib.ParseEOF]] -> out
-func (p *FlowParser) ParseFlow(pd *gparselib.ParseData, ctx interface{},
+//     in (gparselib.ParseData)-> [pPartLines gparselib.ParseMulti1 [pPartLine]] -> out
+//     in (gparselib.ParseData)-> [gparselib.ParseAll [pHeaders, pPartLines]] -> out
+func (p *FlowParser) ParseFlowLines(pd *gparselib.ParseData, ctx interface{},
 ) (*gparselib.ParseData, interface{}) {
 	pAnyPart := gparselib.NewParseAnyPlugin(
 		[]gparselib.SubparserOp{p.pArrow.ParseArrow, p.pComp.ParseComponent},
@@ -293,23 +514,147 @@ func (p *FlowParser) ParseFlow(pd *gparselib.ParseData, ctx interface{},
 		[]gparselib.SubparserOp{pPartSequence, ParseStatementEnd},
 		parsePartLineSemantic,
 	)
-	pLines := gparselib.NewParseMulti1Plugin(pPartLine, parseFlowSemantic)
+	pPartLines := gparselib.NewParseMulti1Plugin(pPartLine, parseFlowSemantic)
+	pHeaders := gparselib.NewParseMulti0Plugin(
+		gparselib.NewParseAnyPlugin(
+			[]gparselib.SubparserOp{p.pImport.ParseImport, p.pPack.ParsePackageDecl},
+			nil,
+		),
+		nil,
+	)
+	return gparselib.ParseAll(pd, ctx,
+		[]gparselib.SubparserOp{pHeaders, pPartLines},
+		parseHeadersSemantic,
+	)
+}
+func parseHeadersSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseData, interface{}) {
+	flow := pd.SubResults[1].Value.(data.Flow)
+	names := make(map[string]bool)
+	for _, header := range pd.SubResults[0].Value.([]interface{}) {
+		switch h := header.(type) {
+		case data.Import:
+			flow.Imports = append(flow.Imports, h)
+		case data.PackageDecl:
+			if names[h.Name] {
+				pd.AddError(h.SrcPos, fmt.Sprintf(errMsg2Packages, h.Name), nil)
+			}
+			names[h.Name] = true
+			flow.Packages = append(flow.Packages, h)
+		}
+	}
+	if !pd.Result.HasError() {
+		pd.Result.Value = flow.ResolvePackages()
+	} else {
+		pd.Result.Value = nil
+		pd.ResetSourcePos(-1)
+	}
+	return pd, ctx
+}
+
+// ParseFlowFile parses a complete flow file with named flows:
+// 'flow name { ... }'
+// Files without flow declarations have to be parsed with ParseFlow
+// (or ParseAnyFlowFile).
+// * Semantic result: data.FlowFile
+//
+// flow:
+//     in (gparselib.ParseData)-> [pKeyword gparselib.ParseLiteral] -> out
+//     in (gparselib.ParseData)-> [pOpen gparselib.ParseLiteral] -> out
+//     in (gparselib.ParseData)-> [pClose gparselib.ParseLiteral] -> out
+//     in (gparselib.ParseData)-> [pNamedFlow gparselib.ParseAll
+//                          [pKeyword, ParseASpc, ParseNameIdent, ParseOptSpc, pOpen,
+//                           ParseSpaceComment, ParseFlowLines, pClose, ParseStatementEnd
+//                          ]
+//                      ] -> out
+//     in (gparselib.ParseData)-> [pNamedFlows gparselib.ParseMulti1 [pNamedFlow]] -> out
+//     in (gparselib.ParseData)-> [gparselib.ParseAll [ParseSpaceComment, pNamedFlows, gparselib.ParseEOF]] -> out
+func (p *FlowParser) ParseFlowFile(pd *gparselib.ParseData, ctx interface{},
+) (*gparselib.ParseData, interface{}) {
+	pKeyword := gparselib.NewParseLiteralPlugin(nil, `flow`)
+	pOpen := gparselib.NewParseLiteralPlugin(nil, `{`)
+	pClose := gparselib.NewParseLiteralPlugin(nil, `}`)
+	pNamedFlow := gparselib.NewParseAllPlugin(
+		[]gparselib.SubparserOp{
+			pKeyword, ParseASpc, p.pName.ParseNameIdent, ParseOptSpc, pOpen,
+			ParseSpaceComment, p.ParseFlowLines, pClose, ParseStatementEnd,
+		},
+		func(pd3 *gparselib.ParseData, ctx3 interface{}) (*gparselib.ParseData, interface{}) {
+			flow := pd3.SubResults[6].Value.(data.Flow)
+			flow.Name = pd3.SubResults[2].Value.(string)
+			pd3.Result.Value = flow
+			return pd3, ctx3
+		},
+	)
+	pNamedFlows := gparselib.NewParseMulti1Plugin(pNamedFlow, parseNamedFlowsSemantic)
 	pEOF := gparselib.NewParseEOFPlugin(nil)
 	return gparselib.ParseAll(pd, ctx,
-		[]gparselib.SubparserOp{ParseSpaceComment, pLines, pEOF},
+		[]gparselib.SubparserOp{ParseSpaceComment, pNamedFlows, pEOF},
 		func(pd2 *gparselib.ParseData, ctx2 interface{}) (*gparselib.ParseData, interface{}) {
 			pd2.Result.Value = pd2.SubResults[1].Value
 			return pd2, ctx2
 		},
 	)
 }
+
+// ParseAnyFlowFile parses a flow file with named flows or a single unnamed
+// flow.
+// Named flows are parsed if the file starts with a flow declaration
+// ('flow name {'), so only the errors of the matching grammar are reported.
+// * Semantic result: data.FlowFile
+//
+// flow:
+//     in (gparselib.ParseData)-> [pDecl gparselib.ParseAll
+//                          [ParseSpaceComment, pKeyword, ParseASpc, ParseNameIdent, ParseOptSpc, pOpen]
+//                      ] -> out
+//     in (gparselib.ParseData)-> [ParseFlowFile] -> out
+//     in (gparselib.ParseData)-> [ParseFlow] -> out
+func (p *FlowParser) ParseAnyFlowFile(pd *gparselib.ParseData, ctx interface{},
+) (*gparselib.ParseData, interface{}) {
+	pKeyword := gparselib.NewParseLiteralPlugin(nil, `flow`)
+	pOpen := gparselib.NewParseLiteralPlugin(nil, `{`)
+	decl := &gparselib.ParseData{Source: pd.Source} // look ahead only
+	decl, _ = gparselib.ParseAll(decl, nil,
+		[]gparselib.SubparserOp{
+			ParseSpaceComment, pKeyword, ParseASpc, p.pName.ParseNameIdent, ParseOptSpc, pOpen,
+		},
+		nil,
+	)
+	if !decl.Result.HasError() {
+		return p.ParseFlowFile(pd, ctx)
+	}
+	pd, ctx = p.ParseFlow(pd, ctx)
+	if !pd.Result.HasError() {
+		pd.Result.Value = data.FlowFile{Flows: []data.Flow{pd.Result.Value.(data.Flow)}}
+	}
+	return pd, ctx
+}
+func parseNamedFlowsSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseData, interface{}) {
+	flows := make([]data.Flow, len(pd.SubResults))
+	names := make(map[string]bool, len(pd.SubResults))
+	for i, subResult := range pd.SubResults {
+		flow := subResult.Value.(data.Flow)
+		if names[flow.Name] {
+			pd.AddError(subResult.Pos, fmt.Sprintf(errMsg2Flows, flow.Name), nil)
+		}
+		names[flow.Name] = true
+		flows[i] = flow
+	}
+	if !pd.Result.HasError() {
+		pd.Result.Value = data.FlowFile{Flows: flows}
+	} else {
+		pd.Result.Value = nil
+		pd.ResetSourcePos(-1)
+	}
+	return pd, ctx
+}
 func parsePartLineSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseData, interface{}) {
-	partLine := pd.SubResults[0].Value.([]interface{})
-	n := len(partLine)
+	values := pd.SubResults[0].Value.([]interface{})
+	n := len(values)
+	partLine := make([]data.Part, n)
 
 	var lastIsArrow, lastIsComp bool
-	for i, part := range partLine {
-		switch v := part.(type) {
+	for i, value := range values {
+		switch v := value.(type) {
 		case data.Arrow:
 			if lastIsArrow {
 				pd.AddError(v.SrcPos, fmt.Sprintf(errMsg2Arrows, i+1), nil)
@@ -321,17 +666,21 @@ func parsePartLineSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib
 				pd.AddError(v.SrcPos, fmt.Sprintf(errMsgContInMidLine, i+1), nil)
 				return pd, ctx
 			}
+			checkAttributes(v.Attrs, pd)
 			lastIsArrow = true
 			lastIsComp = false
+			partLine[i] = v
 		case data.Component:
 			if lastIsComp {
 				pd.AddError(v.SrcPos, fmt.Sprintf(errMsg2Comps, i+1), nil)
 				return pd, ctx
 			}
+			checkAttributes(v.Attrs, pd)
 			lastIsComp = true
 			lastIsArrow = false
+			partLine[i] = v
 		default:
-			pd.AddError(pd.Result.Pos, fmt.Sprintf(errMsgPartType, part, i+1), nil)
+			pd.AddError(pd.Result.Pos, fmt.Sprintf(errMsgPartType, value, i+1), nil)
 			return pd, ctx
 		}
 	}
@@ -361,13 +710,23 @@ func parsePartLineSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib
 	}
 	return pd, ctx
 }
+func checkAttributes(attrs data.Attributes, pd *gparselib.ParseData) {
+	keys := make(map[string]bool, len(attrs))
+	for _, a := range attrs {
+		if keys[a.Key] {
+			pd.AddError(a.SrcPos, fmt.Sprintf(errMsg2Attrs, a.Key), nil)
+		}
+		keys[a.Key] = true
+	}
+}
 func parseFlowSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseData, interface{}) {
-	lines := make([][]interface{}, len(pd.SubResults))
+	lines := make([][]data.Part, len(pd.SubResults))
 	for i, subResult := range pd.SubResults {
-		line := subResult.Value.([]interface{})
+		line := subResult.Value.([]data.Part)
 		lines[i] = line
 	}
 	pd = checkContinuations(lines, pd)
+	lines = expandPortRanges(lines, pd)
 	if !pd.Result.HasError() {
 		pd.Result.Value = data.Flow{
 			Parts: lines,
@@ -378,13 +737,13 @@ func parseFlowSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.Par
 	}
 	return pd, ctx
 }
-func checkContinuations(lines [][]interface{}, pd *gparselib.ParseData) *gparselib.ParseData {
-	endConts := make(map[int]int, 64)
+func checkContinuations(lines [][]data.Part, pd *gparselib.ParseData) *gparselib.ParseData {
+	endConts := make(map[string]int, 64)
 	for i, line := range lines {
 		if v, ok := line[0].(data.Arrow); ok {
 			if v.FromPort.Continuation() {
-				if _, ok := endConts[v.FromPort.Index]; ok {
-					delete(endConts, v.FromPort.Index)
+				if _, ok := endConts[v.FromPort.ContinuationKey()]; ok {
+					delete(endConts, v.FromPort.ContinuationKey())
 				} else {
 					pd.AddError(v.FromPort.SrcPos, fmt.Sprintf(errMsgContStart, i+1), nil)
 				}
@@ -396,10 +755,10 @@ func checkContinuations(lines [][]interface{}, pd *gparselib.ParseData) *gparsel
 				if len(v.Data) > 0 {
 					pd.AddError(v.Data[0].SrcPos, fmt.Sprintf(errMsgContData, i+1), nil)
 				}
-				if j, ok := endConts[v.ToPort.Index]; ok {
+				if j, ok := endConts[v.ToPort.ContinuationKey()]; ok {
 					pd.AddError(v.ToPort.SrcPos, fmt.Sprintf(errMsg2ContEnd, j+1, i+1), nil)
 				} else {
-					endConts[v.ToPort.Index] = i
+					endConts[v.ToPort.ContinuationKey()] = i
 				}
 			}
 		}
@@ -413,3 +772,155 @@ func checkContinuations(lines [][]interface{}, pd *gparselib.ParseData) *gparsel
 
 	return pd
 }
+
+// portKey identifies an indexed port of a component (or of the flow itself
+// if comp is empty).
+type portKey struct {
+	comp  string
+	port  string
+	index int
+	out   bool
+}
+
+// expandPortRanges replaces every arrow with a port range by one arrow per
+// index.
+// The first index stays in the original flow line and every further index
+// gets its own flow line directly after it.
+// Ranges have to be ascending, of equal length at both ends of an arrow and
+// mustn't collide with explicitly written indexes.
+func expandPortRanges(lines [][]data.Part, pd *gparselib.ParseData) [][]data.Part {
+	used := make(map[portKey]bool, 64)
+	forEachPort(lines, func(key portKey, p *data.Port) {
+		if p.HasIndex && !p.HasRange {
+			key.index = p.Index
+			used[key] = true
+		}
+	})
+	forEachPort(lines, func(key portKey, p *data.Port) {
+		if !p.HasRange {
+			return
+		}
+		if p.IndexEnd < p.Index {
+			pd.AddError(p.SrcPos, fmt.Sprintf(errMsgRangeDesc, p.Index, p.IndexEnd, p.Name), nil)
+			return
+		}
+		for key.index = p.Index; key.index <= p.IndexEnd; key.index++ {
+			if used[key] {
+				pd.AddError(p.SrcPos, fmt.Sprintf(errMsgRangeIndex, key.index, p.Name, p.Index, p.IndexEnd), nil)
+			}
+			used[key] = true
+		}
+	})
+
+	expanded := make([][]data.Part, 0, len(lines))
+	for _, line := range lines {
+		var extra [][]data.Part
+		for k, part := range line {
+			arr, ok := part.(data.Arrow)
+			if !ok || !rangedPort(arr.FromPort) && !rangedPort(arr.ToPort) {
+				continue
+			}
+			n, ok := rangeLen(arr, pd)
+			if !ok {
+				continue
+			}
+			for j := 1; j < n; j++ {
+				var l []data.Part
+				if k > 0 {
+					l = append(l, compRef(line[k-1].(data.Component)))
+				}
+				l = append(l, indexedArrow(arr, j))
+				if k < len(line)-1 {
+					l = append(l, compRef(line[k+1].(data.Component)))
+				}
+				extra = append(extra, l)
+			}
+			line[k] = indexedArrow(arr, 0)
+		}
+		expanded = append(expanded, line)
+		expanded = append(expanded, extra...)
+	}
+	return expanded
+}
+func forEachPort(lines [][]data.Part, f func(key portKey, p *data.Port)) {
+	for _, line := range lines {
+		for k, part := range line {
+			arr, ok := part.(data.Arrow)
+			if !ok {
+				continue
+			}
+			if arr.FromPort != nil && !arr.FromPort.Continuation() {
+				key := portKey{port: arr.FromPort.Name, out: true}
+				if k > 0 {
+					key.comp = line[k-1].(data.Component).Decl.Name
+				}
+				f(key, arr.FromPort)
+			}
+			if arr.ToPort != nil && !arr.ToPort.Continuation() {
+				key := portKey{port: arr.ToPort.Name}
+				if k < len(line)-1 {
+					key.comp = line[k+1].(data.Component).Decl.Name
+				}
+				f(key, arr.ToPort)
+			}
+		}
+	}
+}
+func rangedPort(p *data.Port) bool {
+	return p != nil && p.HasRange
+}
+func rangeLen(arr data.Arrow, pd *gparselib.ParseData) (int, bool) {
+	from, to := arr.FromPort, arr.ToPort
+	if from != nil && from.Continuation() || to != nil && to.Continuation() {
+		pd.AddError(arr.SrcPos, errMsgRangeCont, nil)
+		return 0, false
+	}
+	if rangedPort(from) && from.IndexEnd < from.Index ||
+		rangedPort(to) && to.IndexEnd < to.Index {
+		return 0, false // already reported as descending
+	}
+	if rangedPort(from) && rangedPort(to) {
+		n1, n2 := from.IndexEnd-from.Index+1, to.IndexEnd-to.Index+1
+		if n1 != n2 {
+			pd.AddError(arr.SrcPos, fmt.Sprintf(errMsgRangeLens, n1, n2), nil)
+			return 0, false
+		}
+		return n1, true
+	}
+	if rangedPort(from) {
+		return from.IndexEnd - from.Index + 1, true
+	}
+	return to.IndexEnd - to.Index + 1, true
+}
+func indexedArrow(arr data.Arrow, offset int) data.Arrow {
+	arr.FromPort = indexedPort(arr.FromPort, offset)
+	arr.ToPort = indexedPort(arr.ToPort, offset)
+	return arr
+}
+func indexedPort(p *data.Port, offset int) *data.Port {
+	if !rangedPort(p) {
+		return p
+	}
+	ip := *p
+	ip.Index += offset
+	ip.HasRange = false
+	ip.IndexEnd = 0
+	return &ip
+}
+
+// compRef returns a plain reference ('[name]') to an already declared
+// component.
+func compRef(comp data.Component) data.Component {
+	decl := comp.Decl
+	return data.Component{
+		Decl: data.CompDecl{
+			Name:      decl.Name,
+			Type:      data.Type{LocalType: decl.Name, SrcPos: decl.SrcPos, SrcEnd: decl.SrcEnd},
+			VagueType: true,
+			SrcPos:    decl.SrcPos,
+			SrcEnd:    decl.SrcEnd,
+		},
+		SrcPos: comp.SrcPos,
+		SrcEnd: comp.SrcEnd,
+	}
+}
@@ -24,14 +24,14 @@ func TestParsePort(t *testing.T) {
 			expectedErrCount: 3,
 		}, {
 			givenName:        "no match 2",
-			givenContent:     `_a`,
+			givenContent:     `.a`,
 			expectedValue:    nil,
 			expectedErrCount: 3,
 		}, {
 			givenName:        "no match 3",
 			givenContent:     `... 1`,
 			expectedValue:    nil,
-			expectedErrCount: 3,
+			expectedErrCount: 5,
 		}, {
 			givenName:        "no match 4",
 			givenContent:     `..1`,
@@ -40,37 +40,61 @@ func TestParsePort(t *testing.T) {
 		}, {
 			givenName:        "simple 1",
 			givenContent:     `aB`,
-			expectedValue:    data.Port{Name: "aB"},
+			expectedValue:    data.Port{Name: "aB", SrcEnd: 2},
 			expectedErrCount: 0,
 		}, {
 			givenName:        "simple 2",
 			givenContent:     `a0`,
-			expectedValue:    data.Port{Name: "a0"},
+			expectedValue:    data.Port{Name: "a0", SrcEnd: 2},
 			expectedErrCount: 0,
 		}, {
 			givenName:        "simple 3",
 			givenContent:     `aB_cd`,
-			expectedValue:    data.Port{Name: "aB"},
+			expectedValue:    data.Port{Name: "aB_cd", SrcEnd: 5},
 			expectedErrCount: 0,
 		}, {
 			givenName:        "simple 4",
 			givenContent:     `abcDef`,
-			expectedValue:    data.Port{Name: "abcDef"},
+			expectedValue:    data.Port{Name: "abcDef", SrcEnd: 6},
 			expectedErrCount: 0,
 		}, {
 			givenName:        "continuation",
 			givenContent:     `...5`,
-			expectedValue:    data.Port{Name: "...", Index: 5},
+			expectedValue:    data.Port{Name: "...", Index: 5, SrcEnd: 4},
+			expectedErrCount: 0,
+		}, {
+			givenName:        "named continuation",
+			givenContent:     `...retryPath`,
+			expectedValue:    data.Port{Name: "...", Label: "retryPath", SrcEnd: 12},
 			expectedErrCount: 0,
 		}, {
 			givenName:        "complex 1",
 			givenContent:     `ab1Cd:1`,
-			expectedValue:    data.Port{Name: "ab1Cd", HasIndex: true, Index: 1},
+			expectedValue:    data.Port{Name: "ab1Cd", HasIndex: true, Index: 1, SrcEnd: 7},
 			expectedErrCount: 0,
 		}, {
 			givenName:        "complex 2",
 			givenContent:     `a1Bc:003`,
-			expectedValue:    data.Port{Name: "a1Bc", HasIndex: true, Index: 3},
+			expectedValue:    data.Port{Name: "a1Bc", HasIndex: true, Index: 3, SrcEnd: 8},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "range",
+			givenContent: `out:1..4`,
+			expectedValue: data.Port{
+				Name: "out", HasIndex: true, Index: 1, HasRange: true, IndexEnd: 4, SrcEnd: 8,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "descending range",
+			givenContent: `out:4..1`,
+			expectedValue: data.Port{
+				Name: "out", HasIndex: true, Index: 4, HasRange: true, IndexEnd: 1, SrcEnd: 8,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:        "incomplete range",
+			givenContent:     `out:1..`,
+			expectedValue:    data.Port{Name: "out", HasIndex: true, Index: 1, SrcEnd: 5},
 			expectedErrCount: 0,
 		},
 	})
@@ -100,57 +124,82 @@ func TestParseArrow(t *testing.T) {
 		}, {
 			givenName:        "simple 1",
 			givenContent:     `->`,
-			expectedValue:    data.Arrow{},
+			expectedValue:    data.Arrow{SrcEnd: 2},
 			expectedErrCount: 0,
 		}, {
 			givenName:    "simple 2",
 			givenContent: `aPort->bPort`,
 			expectedValue: data.Arrow{
-				FromPort: &data.Port{Name: "aPort"},
-				ToPort:   &data.Port{Name: "bPort", SrcPos: 7},
+				FromPort: &data.Port{Name: "aPort", SrcEnd: 5},
+				ToPort:   &data.Port{Name: "bPort", SrcPos: 7, SrcEnd: 12},
+				SrcEnd:   12,
 			},
 			expectedErrCount: 0,
 		}, {
 			givenName:    "simple 3",
 			givenContent: `(Data)->`,
 			expectedValue: data.Arrow{
-				Data: []data.Type{data.Type{LocalType: "Data", SrcPos: 1}},
+				Data:   []data.Type{data.Type{LocalType: "Data", SrcPos: 1, SrcEnd: 5}},
+				SrcEnd: 8,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "attributes",
+			givenContent: `(Data){async}-> bPort`,
+			expectedValue: data.Arrow{
+				Data:   []data.Type{data.Type{LocalType: "Data", SrcPos: 1, SrcEnd: 5}},
+				Attrs:  data.Attributes{{Key: "async", SrcPos: 7, SrcEnd: 12}},
+				ToPort: &data.Port{Name: "bPort", SrcPos: 16, SrcEnd: 21},
+				SrcEnd: 21,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "attributes without data",
+			givenContent: `aPort {queue: 10} -> bPort`,
+			expectedValue: data.Arrow{
+				FromPort: &data.Port{Name: "aPort", SrcEnd: 5},
+				Attrs:    data.Attributes{{Key: "queue", Value: "10", SrcPos: 7, SrcEnd: 16}},
+				ToPort:   &data.Port{Name: "bPort", SrcPos: 21, SrcEnd: 26},
+				SrcEnd:   26,
 			},
 			expectedErrCount: 0,
 		}, {
 			givenName:    "complex 1",
 			givenContent: "aPort \t ( // comment1\n Data // comment2\n ) \t -> \t bPort",
 			expectedValue: data.Arrow{
-				FromPort: &data.Port{Name: "aPort"},
-				Data:     []data.Type{data.Type{LocalType: "Data", SrcPos: 23}},
-				ToPort:   &data.Port{Name: "bPort", SrcPos: 50},
+				FromPort: &data.Port{Name: "aPort", SrcEnd: 5},
+				Data:     []data.Type{data.Type{LocalType: "Data", SrcPos: 23, SrcEnd: 27}},
+				ToPort:   &data.Port{Name: "bPort", SrcPos: 50, SrcEnd: 55},
+				SrcEnd:   55,
 			},
 			expectedErrCount: 0,
 		}, {
 			givenName:    "complex 2",
 			givenContent: "aPort ( Data1 , Data2, data3 ) -> bPort",
 			expectedValue: data.Arrow{
-				FromPort: &data.Port{Name: "aPort"},
+				FromPort: &data.Port{Name: "aPort", SrcEnd: 5},
 				Data: []data.Type{
-					data.Type{LocalType: "Data1", SrcPos: 8},
-					data.Type{LocalType: "Data2", SrcPos: 16},
-					data.Type{LocalType: "data3", SrcPos: 23},
+					data.Type{LocalType: "Data1", SrcPos: 8, SrcEnd: 13},
+					data.Type{LocalType: "Data2", SrcPos: 16, SrcEnd: 21},
+					data.Type{LocalType: "data3", SrcPos: 23, SrcEnd: 28},
 				},
-				ToPort: &data.Port{Name: "bPort", SrcPos: 34},
+				ToPort: &data.Port{Name: "bPort", SrcPos: 34, SrcEnd: 39},
+				SrcEnd: 39,
 			},
 			expectedErrCount: 0,
 		}, {
 			givenName:    "complex 3",
 			givenContent: "aPort ( Data1, Data2 |\n\t data3 ) -> bPort",
 			expectedValue: data.Arrow{
-				FromPort: &data.Port{Name: "aPort"},
+				FromPort: &data.Port{Name: "aPort", SrcEnd: 5},
 				Data: []data.Type{
-					data.Type{LocalType: "Data1", SrcPos: 8},
-					data.Type{LocalType: "Data2", SrcPos: 15},
+					data.Type{LocalType: "Data1", SrcPos: 8, SrcEnd: 13},
+					data.Type{LocalType: "Data2", SrcPos: 15, SrcEnd: 20},
 					data.SeparatorType,
-					data.Type{LocalType: "data3", SrcPos: 25},
+					data.Type{LocalType: "data3", SrcPos: 25, SrcEnd: 30},
 				},
-				ToPort: &data.Port{Name: "bPort", SrcPos: 36},
+				ToPort: &data.Port{Name: "bPort", SrcPos: 36, SrcEnd: 41},
+				SrcEnd: 41,
 			},
 			expectedErrCount: 0,
 		},
@@ -193,6 +242,11 @@ func TestParseFlow(t *testing.T) {
 			givenContent:     `in(Data)->->out;`,
 			expectedValue:    nil,
 			expectedErrCount: 2,
+		}, {
+			givenName:        "attribute set twice",
+			givenContent:     `in(Data){a: 1, a: 2}->[A];`,
+			expectedValue:    nil,
+			expectedErrCount: 2,
 		}, {
 			givenName:        "two consecutive components",
 			givenContent:     `[A][B];`,
@@ -207,20 +261,23 @@ func TestParseFlow(t *testing.T) {
 			givenName:    "simple 1",
 			givenContent: `a(b)->[c];`,
 			expectedValue: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{
 						data.Arrow{
-							FromPort: &data.Port{Name: "a"},
-							Data:     []data.Type{data.Type{LocalType: "b", SrcPos: 2}},
+							FromPort: &data.Port{Name: "a", SrcEnd: 1},
+							Data:     []data.Type{data.Type{LocalType: "b", SrcPos: 2, SrcEnd: 3}},
+							SrcEnd:   6,
 						},
 						data.Component{
 							Decl: data.CompDecl{
 								Name:      "c",
-								Type:      data.Type{LocalType: "c", SrcPos: 7},
+								Type:      data.Type{LocalType: "c", SrcPos: 7, SrcEnd: 8},
 								VagueType: true,
 								SrcPos:    7,
+								SrcEnd:    8,
 							},
 							SrcPos: 6,
+							SrcEnd: 9,
 						},
 					},
 				},
@@ -230,18 +287,21 @@ func TestParseFlow(t *testing.T) {
 			givenName:    "no data",
 			givenContent: `[A]->out`,
 			expectedValue: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{
 						data.Component{
 							Decl: data.CompDecl{
 								Name:   "a",
-								Type:   data.Type{LocalType: "A", SrcPos: 1},
+								Type:   data.Type{LocalType: "A", SrcPos: 1, SrcEnd: 2},
 								SrcPos: 1,
+								SrcEnd: 2,
 							},
+							SrcEnd: 3,
 						},
 						data.Arrow{
-							ToPort: &data.Port{Name: "out", SrcPos: 5},
+							ToPort: &data.Port{Name: "out", SrcPos: 5, SrcEnd: 8},
 							SrcPos: 3,
+							SrcEnd: 8,
 						},
 					},
 				},
@@ -251,17 +311,19 @@ func TestParseFlow(t *testing.T) {
 			givenName:    "simple 2",
 			givenContent: "[A](b)->c // my comment\n",
 			expectedValue: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{
 						data.Component{Decl: data.CompDecl{
 							Name:   "a",
-							Type:   data.Type{LocalType: "A", SrcPos: 1},
+							Type:   data.Type{LocalType: "A", SrcPos: 1, SrcEnd: 2},
 							SrcPos: 1,
-						}},
+							SrcEnd: 2,
+						}, SrcEnd: 3},
 						data.Arrow{
-							Data:   []data.Type{data.Type{LocalType: "b", SrcPos: 4}},
-							ToPort: &data.Port{Name: "c", SrcPos: 8},
+							Data:   []data.Type{data.Type{LocalType: "b", SrcPos: 4, SrcEnd: 5}},
+							ToPort: &data.Port{Name: "c", SrcPos: 8, SrcEnd: 9},
 							SrcPos: 3,
+							SrcEnd: 9,
 						},
 					},
 				},
@@ -297,39 +359,170 @@ func TestParseFlow(t *testing.T) {
 			givenContent:     "in (d)-> [A]->...1 \n ...2 (e)-> [G] -> h",
 			expectedValue:    nil,
 			expectedErrCount: 2,
+		}, {
+			givenName:        "continuation err: label mismatch",
+			givenContent:     "in (d)-> [A]->...retry \n ...retri (e)-> [G] -> h",
+			expectedValue:    nil,
+			expectedErrCount: 2,
+		}, {
+			givenName:        "continuation err: label and number",
+			givenContent:     "in (d)-> [A]->...retry \n ...1 (e)-> [G] -> h",
+			expectedValue:    nil,
+			expectedErrCount: 2,
+		}, {
+			givenName:    "named continuation",
+			givenContent: "in (d)-> [A] ->...retry \n ...retry (e)-> [G]",
+			expectedValue: data.Flow{
+				Parts: [][]data.Part{
+					{
+						data.Arrow{
+							FromPort: &data.Port{Name: "in", SrcPos: 0, SrcEnd: 2},
+							Data:     []data.Type{{LocalType: "d", SrcPos: 4, SrcEnd: 5}},
+							SrcPos:   0,
+							SrcEnd:   8,
+						},
+						data.Component{Decl: data.CompDecl{
+							Name:   "a",
+							Type:   data.Type{LocalType: "A", SrcPos: 10, SrcEnd: 11},
+							SrcPos: 10,
+							SrcEnd: 11,
+						}, SrcPos: 9, SrcEnd: 12},
+						data.Arrow{
+							ToPort: &data.Port{Name: "...", Label: "retry", SrcPos: 15, SrcEnd: 23},
+							SrcPos: 13,
+							SrcEnd: 23,
+						},
+					}, {
+						data.Arrow{
+							FromPort: &data.Port{Name: "...", Label: "retry", SrcPos: 26, SrcEnd: 34},
+							Data:     []data.Type{{LocalType: "e", SrcPos: 36, SrcEnd: 37}},
+							SrcPos:   26,
+							SrcEnd:   40,
+						},
+						data.Component{
+							Decl: data.CompDecl{
+								Name:   "g",
+								Type:   data.Type{LocalType: "G", SrcPos: 42, SrcEnd: 43},
+								SrcPos: 42,
+								SrcEnd: 43,
+							},
+							SrcPos: 41,
+							SrcEnd: 44,
+						},
+					},
+				},
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:        "port range err: descending",
+			givenContent:     "[A] out:2..1 -> in [B]",
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "port range err: lengths",
+			givenContent:     "[A] out:1..2 -> in:1..3 [B]",
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "port range err: collision",
+			givenContent:     "[A] out:1..2 -> in [B] \n [a] out:2 -> in [C]",
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "port range err: continuation",
+			givenContent:     "[A] out:1..2 -> ...1 \n ...1 -> in [B]",
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:    "port range",
+			givenContent: "[A] out:1..2 (d)-> in:3..4 [B]",
+			expectedValue: data.Flow{
+				Parts: [][]data.Part{
+					{
+						data.Component{Decl: data.CompDecl{
+							Name:   "a",
+							Type:   data.Type{LocalType: "A", SrcPos: 1, SrcEnd: 2},
+							SrcPos: 1,
+							SrcEnd: 2,
+						}, SrcEnd: 3},
+						data.Arrow{
+							FromPort: &data.Port{Name: "out", HasIndex: true, Index: 1, SrcPos: 4, SrcEnd: 12},
+							Data:     []data.Type{{LocalType: "d", SrcPos: 14, SrcEnd: 15}},
+							ToPort:   &data.Port{Name: "in", HasIndex: true, Index: 3, SrcPos: 19, SrcEnd: 26},
+							SrcPos:   4,
+							SrcEnd:   26,
+						},
+						data.Component{Decl: data.CompDecl{
+							Name:   "b",
+							Type:   data.Type{LocalType: "B", SrcPos: 28, SrcEnd: 29},
+							SrcPos: 28,
+							SrcEnd: 29,
+						}, SrcPos: 27, SrcEnd: 30},
+					}, {
+						data.Component{Decl: data.CompDecl{
+							Name:      "a",
+							Type:      data.Type{LocalType: "a", SrcPos: 1, SrcEnd: 2},
+							VagueType: true,
+							SrcPos:    1,
+							SrcEnd:    2,
+						}, SrcEnd: 3},
+						data.Arrow{
+							FromPort: &data.Port{Name: "out", HasIndex: true, Index: 2, SrcPos: 4, SrcEnd: 12},
+							Data:     []data.Type{{LocalType: "d", SrcPos: 14, SrcEnd: 15}},
+							ToPort:   &data.Port{Name: "in", HasIndex: true, Index: 4, SrcPos: 19, SrcEnd: 26},
+							SrcPos:   4,
+							SrcEnd:   26,
+						},
+						data.Component{Decl: data.CompDecl{
+							Name:      "b",
+							Type:      data.Type{LocalType: "b", SrcPos: 28, SrcEnd: 29},
+							VagueType: true,
+							SrcPos:    28,
+							SrcEnd:    29,
+						}, SrcPos: 27, SrcEnd: 30},
+					},
+				},
+			},
+			expectedErrCount: 0,
 		}, {
 			givenName:    "continuation 1",
 			givenContent: "in (d)-> [A] ->...1 \n ...1 (e)-> [G]",
 			expectedValue: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{
 						data.Arrow{
-							FromPort: &data.Port{Name: "in", SrcPos: 0},
-							Data:     []data.Type{{LocalType: "d", SrcPos: 4}},
+							FromPort: &data.Port{Name: "in", SrcPos: 0, SrcEnd: 2},
+							Data:     []data.Type{{LocalType: "d", SrcPos: 4, SrcEnd: 5}},
 							SrcPos:   0,
+							SrcEnd:   8,
 						},
 						data.Component{Decl: data.CompDecl{
 							Name:   "a",
-							Type:   data.Type{LocalType: "A", SrcPos: 10},
+							Type:   data.Type{LocalType: "A", SrcPos: 10, SrcEnd: 11},
 							SrcPos: 10,
-						}, SrcPos: 9},
+							SrcEnd: 11,
+						}, SrcPos: 9, SrcEnd: 12},
 						data.Arrow{
-							ToPort: &data.Port{Name: "...", Index: 1, SrcPos: 15},
+							ToPort: &data.Port{Name: "...", Index: 1, SrcPos: 15, SrcEnd: 19},
 							SrcPos: 13,
+							SrcEnd: 19,
 						},
 					}, {
 						data.Arrow{
-							FromPort: &data.Port{Name: "...", Index: 1, SrcPos: 22},
-							Data:     []data.Type{data.Type{LocalType: "e", SrcPos: 28}},
+							FromPort: &data.Port{Name: "...", Index: 1, SrcPos: 22, SrcEnd: 26},
+							Data:     []data.Type{data.Type{LocalType: "e", SrcPos: 28, SrcEnd: 29}},
 							SrcPos:   22,
+							SrcEnd:   32,
 						},
 						data.Component{
 							Decl: data.CompDecl{
 								Name:   "g",
-								Type:   data.Type{LocalType: "G", SrcPos: 34},
+								Type:   data.Type{LocalType: "G", SrcPos: 34, SrcEnd: 35},
 								SrcPos: 34,
+								SrcEnd: 35,
 							},
 							SrcPos: 33,
+							SrcEnd: 36,
 						},
 					},
 				},
@@ -339,36 +532,42 @@ func TestParseFlow(t *testing.T) {
 			givenName:    "complex 1",
 			givenContent: "[A](b)->c // my comment\nd \t (e)-> \t f \t [G] \t -> \t h",
 			expectedValue: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{
 						data.Component{Decl: data.CompDecl{
 							Name:   "a",
-							Type:   data.Type{LocalType: "A", SrcPos: 1},
+							Type:   data.Type{LocalType: "A", SrcPos: 1, SrcEnd: 2},
 							SrcPos: 1,
-						}},
+							SrcEnd: 2,
+						}, SrcEnd: 3},
 						data.Arrow{
-							Data:   []data.Type{data.Type{LocalType: "b", SrcPos: 4}},
-							ToPort: &data.Port{Name: "c", SrcPos: 8},
+							Data:   []data.Type{data.Type{LocalType: "b", SrcPos: 4, SrcEnd: 5}},
+							ToPort: &data.Port{Name: "c", SrcPos: 8, SrcEnd: 9},
 							SrcPos: 3,
+							SrcEnd: 9,
 						},
 					}, {
 						data.Arrow{
-							FromPort: &data.Port{Name: "d", SrcPos: 24},
-							Data:     []data.Type{data.Type{LocalType: "e", SrcPos: 29}},
-							ToPort:   &data.Port{Name: "f", SrcPos: 36},
+							FromPort: &data.Port{Name: "d", SrcPos: 24, SrcEnd: 25},
+							Data:     []data.Type{data.Type{LocalType: "e", SrcPos: 29, SrcEnd: 30}},
+							ToPort:   &data.Port{Name: "f", SrcPos: 36, SrcEnd: 37},
 							SrcPos:   24,
+							SrcEnd:   37,
 						},
 						data.Component{
 							Decl: data.CompDecl{
 								Name:   "g",
-								Type:   data.Type{LocalType: "G", SrcPos: 41},
+								Type:   data.Type{LocalType: "G", SrcPos: 41, SrcEnd: 42},
 								SrcPos: 41,
+								SrcEnd: 42,
 							},
 							SrcPos: 40,
+							SrcEnd: 43,
 						},
 						data.Arrow{
-							ToPort: &data.Port{Name: "h", SrcPos: 51},
+							ToPort: &data.Port{Name: "h", SrcPos: 51, SrcEnd: 52},
 							SrcPos: 46,
+							SrcEnd: 52,
 						},
 					},
 				},
@@ -378,36 +577,41 @@ func TestParseFlow(t *testing.T) {
 			givenName:    "complex 2",
 			givenContent: "[a B [c=D,E|f=G,H]] (i)-> out\n",
 			expectedValue: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{
 						data.Component{
 							Decl: data.CompDecl{
-								Name: "a", Type: data.Type{LocalType: "B", SrcPos: 3},
+								Name: "a", Type: data.Type{LocalType: "B", SrcPos: 3, SrcEnd: 4},
 								SrcPos: 1,
+								SrcEnd: 4,
 							},
 							Plugins: []data.Plugin{
 								data.Plugin{
 									Name: "c",
 									Types: []data.Type{
-										data.Type{LocalType: "D", SrcPos: 8},
-										data.Type{LocalType: "E", SrcPos: 10},
+										data.Type{LocalType: "D", SrcPos: 8, SrcEnd: 9},
+										data.Type{LocalType: "E", SrcPos: 10, SrcEnd: 11},
 									},
 									SrcPos: 6,
+									SrcEnd: 11,
 								},
 								data.Plugin{
 									Name: "f",
 									Types: []data.Type{
-										data.Type{LocalType: "G", SrcPos: 14},
-										data.Type{LocalType: "H", SrcPos: 16},
+										data.Type{LocalType: "G", SrcPos: 14, SrcEnd: 15},
+										data.Type{LocalType: "H", SrcPos: 16, SrcEnd: 17},
 									},
 									SrcPos: 12,
+									SrcEnd: 17,
 								},
 							},
+							SrcEnd: 19,
 						},
 						data.Arrow{
-							Data:   []data.Type{data.Type{LocalType: "i", SrcPos: 21}},
-							ToPort: &data.Port{Name: "out", SrcPos: 26},
+							Data:   []data.Type{data.Type{LocalType: "i", SrcPos: 21, SrcEnd: 22}},
+							ToPort: &data.Port{Name: "out", SrcPos: 26, SrcEnd: 29},
 							SrcPos: 20,
+							SrcEnd: 29,
 						},
 					},
 				},
@@ -417,87 +621,358 @@ func TestParseFlow(t *testing.T) {
 			givenName:    "complex 3",
 			givenContent: "[A[p1|p2|p3]] (b)->c [D[plug=sp1,sp2,sp3]] (e)-> f[G[sp1,sp2]] -> h;",
 			expectedValue: data.Flow{
-				Parts: [][]interface{}{
+				Parts: [][]data.Part{
 					{
 						data.Component{
 							Decl: data.CompDecl{
 								Name:   "a",
-								Type:   data.Type{LocalType: "A", SrcPos: 1},
+								Type:   data.Type{LocalType: "A", SrcPos: 1, SrcEnd: 2},
 								SrcPos: 1,
+								SrcEnd: 2,
 							},
 							Plugins: []data.Plugin{
 								data.Plugin{
 									Types: []data.Type{
-										data.Type{LocalType: "p1", SrcPos: 3},
+										data.Type{LocalType: "p1", SrcPos: 3, SrcEnd: 5},
 									},
 									SrcPos: 3,
+									SrcEnd: 5,
 								},
 								data.Plugin{
 									Types: []data.Type{
-										data.Type{LocalType: "p2", SrcPos: 6},
+										data.Type{LocalType: "p2", SrcPos: 6, SrcEnd: 8},
 									},
 									SrcPos: 6,
+									SrcEnd: 8,
 								},
 								data.Plugin{
 									Types: []data.Type{
-										data.Type{LocalType: "p3", SrcPos: 9},
+										data.Type{LocalType: "p3", SrcPos: 9, SrcEnd: 11},
 									},
 									SrcPos: 9,
+									SrcEnd: 11,
 								},
 							},
+							SrcEnd: 13,
 						},
 						data.Arrow{
-							Data:   []data.Type{data.Type{LocalType: "b", SrcPos: 15}},
-							ToPort: &data.Port{Name: "c", SrcPos: 19},
+							Data:   []data.Type{data.Type{LocalType: "b", SrcPos: 15, SrcEnd: 16}},
+							ToPort: &data.Port{Name: "c", SrcPos: 19, SrcEnd: 20},
 							SrcPos: 14,
+							SrcEnd: 20,
 						},
 						data.Component{
 							Decl: data.CompDecl{
 								Name:   "d",
-								Type:   data.Type{LocalType: "D", SrcPos: 22},
+								Type:   data.Type{LocalType: "D", SrcPos: 22, SrcEnd: 23},
 								SrcPos: 22,
+								SrcEnd: 23,
 							},
 							Plugins: []data.Plugin{
 								data.Plugin{
 									Name: "plug",
 									Types: []data.Type{
-										data.Type{LocalType: "sp1", SrcPos: 29},
-										data.Type{LocalType: "sp2", SrcPos: 33},
-										data.Type{LocalType: "sp3", SrcPos: 37},
+										data.Type{LocalType: "sp1", SrcPos: 29, SrcEnd: 32},
+										data.Type{LocalType: "sp2", SrcPos: 33, SrcEnd: 36},
+										data.Type{LocalType: "sp3", SrcPos: 37, SrcEnd: 40},
 									},
 									SrcPos: 24,
+									SrcEnd: 40,
 								},
 							},
 							SrcPos: 21,
+							SrcEnd: 42,
 						},
 						data.Arrow{
-							Data:   []data.Type{data.Type{LocalType: "e", SrcPos: 44}},
-							ToPort: &data.Port{Name: "f", SrcPos: 49},
+							Data:   []data.Type{data.Type{LocalType: "e", SrcPos: 44, SrcEnd: 45}},
+							ToPort: &data.Port{Name: "f", SrcPos: 49, SrcEnd: 50},
 							SrcPos: 43,
+							SrcEnd: 50,
 						},
 						data.Component{
 							Decl: data.CompDecl{
 								Name:   "g",
-								Type:   data.Type{LocalType: "G", SrcPos: 51},
+								Type:   data.Type{LocalType: "G", SrcPos: 51, SrcEnd: 52},
 								SrcPos: 51,
+								SrcEnd: 52,
 							},
 							Plugins: []data.Plugin{
 								data.Plugin{
 									Types: []data.Type{
-										data.Type{LocalType: "sp1", SrcPos: 53},
-										data.Type{LocalType: "sp2", SrcPos: 57},
+										data.Type{LocalType: "sp1", SrcPos: 53, SrcEnd: 56},
+										data.Type{LocalType: "sp2", SrcPos: 57, SrcEnd: 60},
 									},
 									SrcPos: 53,
+									SrcEnd: 60,
 								},
 							},
 							SrcPos: 50,
+							SrcEnd: 62,
 						},
 						data.Arrow{
-							ToPort: &data.Port{Name: "h", SrcPos: 66},
+							ToPort: &data.Port{Name: "h", SrcPos: 66, SrcEnd: 67},
 							SrcPos: 63,
+							SrcEnd: 67,
+						},
+					},
+				},
+			},
+			expectedErrCount: 0,
+		},
+	})
+}
+
+func TestParseFlowFile(t *testing.T) {
+	p, err := NewFlowParser()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	runTests(t, p.ParseFlowFile, []parseTestData{
+		{
+			givenName:        "empty",
+			givenContent:     ``,
+			expectedValue:    nil,
+			expectedErrCount: 2,
+		}, {
+			givenName:        "anonymous",
+			givenContent:     `io(b)->[c];`,
+			expectedValue:    nil,
+			expectedErrCount: 2,
+		}, {
+			givenName:    "named",
+			givenContent: "// comment\nflow a {\n in(b)->[c]\n}\nflow d { xy(z)->[e]; }\n",
+			expectedValue: data.FlowFile{Flows: []data.Flow{
+				{Name: "a", Parts: [][]data.Part{simpleLine(21, "in", "b", "c")}},
+				{Name: "d", Parts: [][]data.Part{simpleLine(43, "xy", "z", "e")}},
+			}},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "port named flow",
+			givenContent: "flow flow { io(b)->[c]; }",
+			expectedValue: data.FlowFile{Flows: []data.Flow{
+				{Name: "flow", Parts: [][]data.Part{simpleLine(12, "io", "b", "c")}},
+			}},
+			expectedErrCount: 0,
+		}, {
+			givenName:        "unclosed",
+			givenContent:     "flow a {\n in(b)->[c]\n",
+			expectedValue:    nil,
+			expectedErrCount: 2,
+		}, {
+			givenName:        "duplicate name",
+			givenContent:     "flow a { in(b)->[c]; }\nflow a { in(b)->[c]; }",
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:    "imports",
+			givenContent: "flow a {\n import \"x.flow\"; import \"../y.flow\"\n in(b)->[c]\n}\n",
+			expectedValue: data.FlowFile{Flows: []data.Flow{{
+				Name: "a",
+				Imports: []data.Import{
+					{Path: "x.flow", SrcPos: 10, SrcEnd: 25},
+					{Path: "../y.flow", SrcPos: 27, SrcEnd: 45},
+				},
+				Parts: [][]data.Part{simpleLine(47, "in", "b", "c")},
+			}}},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "packages",
+			givenContent: "flow a {\n package o \"github.com/acme/order\"\n in(o.Order)->[c]\n}\n",
+			expectedValue: data.FlowFile{Flows: []data.Flow{{
+				Name: "a",
+				Packages: []data.PackageDecl{
+					{Name: "o", Path: "github.com/acme/order", SrcPos: 10, SrcEnd: 43},
+				},
+				Parts: [][]data.Part{{
+					data.Arrow{
+						FromPort: &data.Port{Name: "in", SrcPos: 45, SrcEnd: 47},
+						Data: []data.Type{{
+							Package: "o", ImportPath: "github.com/acme/order", LocalType: "Order",
+							SrcPos: 48, SrcEnd: 55,
+						}},
+						SrcPos: 45,
+						SrcEnd: 58,
+					},
+					data.Component{
+						Decl: data.CompDecl{
+							Name:      "c",
+							Type:      data.Type{LocalType: "c", SrcPos: 59, SrcEnd: 60},
+							VagueType: true,
+							SrcPos:    59,
+							SrcEnd:    60,
 						},
+						SrcPos: 58,
+						SrcEnd: 61,
+					},
+				}},
+			}}},
+			expectedErrCount: 0,
+		}, {
+			givenName:        "package declared twice",
+			givenContent:     "flow a {\n package o \"x/o\"; package o \"y/o\"\n in(b)->[c]\n}\n",
+			expectedValue:    nil,
+			expectedErrCount: 2,
+		},
+	})
+}
+
+func TestParseAnyFlowFile(t *testing.T) {
+	p, err := NewFlowParser()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	runTests(t, p.ParseAnyFlowFile, []parseTestData{
+		{
+			givenName:        "empty",
+			givenContent:     ``,
+			expectedValue:    nil,
+			expectedErrCount: 5,
+		}, {
+			givenName:    "anonymous",
+			givenContent: `io(b)->[c];`,
+			expectedValue: data.FlowFile{Flows: []data.Flow{
+				{Parts: [][]data.Part{simpleLine(0, "io", "b", "c")}},
+			}},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "named",
+			givenContent: "// comment\nflow a {\n in(b)->[c]\n}\nflow d { xy(z)->[e]; }\n",
+			expectedValue: data.FlowFile{Flows: []data.Flow{
+				{Name: "a", Parts: [][]data.Part{simpleLine(21, "in", "b", "c")}},
+				{Name: "d", Parts: [][]data.Part{simpleLine(43, "xy", "z", "e")}},
+			}},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "anonymous with port flow",
+			givenContent: "flow (b)->[c];",
+			expectedValue: data.FlowFile{Flows: []data.Flow{{Parts: [][]data.Part{{
+				data.Arrow{
+					FromPort: &data.Port{Name: "flow", SrcEnd: 4},
+					Data:     []data.Type{{LocalType: "b", SrcPos: 6, SrcEnd: 7}},
+					SrcEnd:   10,
+				},
+				data.Component{
+					Decl: data.CompDecl{
+						Name:      "c",
+						Type:      data.Type{LocalType: "c", SrcPos: 11, SrcEnd: 12},
+						VagueType: true,
+						SrcPos:    11,
+						SrcEnd:    12,
 					},
+					SrcPos: 10,
+					SrcEnd: 13,
 				},
+			}}}}},
+			expectedErrCount: 0,
+		}, {
+			givenName:        "unclosed",
+			givenContent:     "flow a {\n in(b)->[c]\n",
+			expectedValue:    nil,
+			expectedErrCount: 2,
+		},
+	})
+}
+
+func simpleLine(offset int, port, typ, comp string) []data.Part {
+	return []data.Part{
+		data.Arrow{
+			FromPort: &data.Port{Name: port, SrcPos: offset, SrcEnd: offset + 2},
+			Data:     []data.Type{{LocalType: typ, SrcPos: offset + 3, SrcEnd: offset + 4}},
+			SrcPos:   offset,
+			SrcEnd:   offset + 7,
+		},
+		data.Component{
+			Decl: data.CompDecl{
+				Name:      comp,
+				Type:      data.Type{LocalType: comp, SrcPos: offset + 8, SrcEnd: offset + 9},
+				VagueType: true,
+				SrcPos:    offset + 8,
+				SrcEnd:    offset + 9,
+			},
+			SrcPos: offset + 7,
+			SrcEnd: offset + 10,
+		},
+	}
+}
+
+func TestParseImport(t *testing.T) {
+	p, err := NewImportParser()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	runTests(t, p.ParseImport, []parseTestData{
+		{
+			givenName:        "empty",
+			givenContent:     ``,
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "no path",
+			givenContent:     `import ;`,
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "empty path",
+			givenContent:     `import "";`,
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "no space",
+			givenContent:     `import"a.flow";`,
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "no statement end",
+			givenContent:     `import "a.flow" in`,
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "simple",
+			givenContent:     "import \"common/errors.flow\" // tails\n",
+			expectedValue:    data.Import{Path: "common/errors.flow", SrcPos: 0, SrcEnd: 27},
+			expectedErrCount: 0,
+		},
+	})
+}
+
+func TestParsePackageDecl(t *testing.T) {
+	p, err := NewPackageDeclParser()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	runTests(t, p.ParsePackageDecl, []parseTestData{
+		{
+			givenName:        "empty",
+			givenContent:     ``,
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "no path",
+			givenContent:     `package order;`,
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "bad name",
+			givenContent:     `package Order "github.com/acme/order";`,
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "mixed case name",
+			givenContent:     `package myOrder "github.com/acme/order";`,
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:    "without name",
+			givenContent: "package \"github.com/acme/order/v2\"\n",
+			expectedValue: data.PackageDecl{
+				Name: "order", Path: "github.com/acme/order/v2", SrcPos: 0, SrcEnd: 34,
+			},
+			expectedErrCount: 0,
+		}, {
+			givenName:    "with name",
+			givenContent: "package ord \"github.com/acme/order\"; // orders\n",
+			expectedValue: data.PackageDecl{
+				Name: "ord", Path: "github.com/acme/order", SrcPos: 0, SrcEnd: 35,
 			},
 			expectedErrCount: 0,
 		},
@@ -11,18 +11,30 @@ import (
 	"github.com/flowdev/gparselib"
 )
 
+// Regular expressions for identifiers following the rules of Go:
+// Identifiers consist of Unicode letters, digits and '_' and start with a
+// letter or '_'.
+// Names don't start with an upper case letter and packages don't contain
+// any.
+const (
+	lowerIdentStart = `[\p{Ll}\p{Lt}\p{Lm}\p{Lo}_]`
+	identStart      = `[\p{L}_]`
+	identRest       = `[\p{L}\p{Nd}_]*`
+	packageIdent    = `[\p{Ll}\p{Lo}_][\p{Ll}\p{Lo}\p{Nd}_]*`
+)
+
 // NameIdentParser is a RegexpParser for parsing a name identifier.
 type NameIdentParser gparselib.RegexpParser
 
 // NewNameIdentParser creates a new parser for the given regular expression.
 // If the regular expression is invalid an error is returned.
 func NewNameIdentParser() (*NameIdentParser, error) {
-	p, err := gparselib.NewRegexpParser(`^[a-z][a-zA-Z0-9]*`)
+	p, err := gparselib.NewRegexpParser(`^` + lowerIdentStart + identRest)
 	return (*NameIdentParser)(p), err
 }
 
 // ParseNameIdent parses a name identifier.
-// * Regexp: [a-z][a-zA-Z0-9]*
+// * Regexp: [\p{Ll}\p{Lt}\p{Lm}\p{Lo}_][\p{L}\p{Nd}_]*
 // * Semantic result: The parsed text.
 //
 // flow:
@@ -39,12 +51,12 @@ type PackageIdentParser gparselib.RegexpParser
 // NewPackageIdentParser creates a new parser for the given regular expression.
 // If the regular expression is invalid an error is returned.
 func NewPackageIdentParser() (*PackageIdentParser, error) {
-	p, err := gparselib.NewRegexpParser(`^[a-z][a-z0-9]*\.`)
+	p, err := gparselib.NewRegexpParser(`^` + packageIdent + `\.`)
 	return (*PackageIdentParser)(p), err
 }
 
 // ParsePackageIdent parses a package identifier.
-// * Regexp: [a-z][a-z0-9]*\.
+// * Regexp: [\p{Ll}\p{Lo}_][\p{Ll}\p{Lo}\p{Nd}_]*\.
 // * Semantic result: The parsed text (without the dot).
 //
 // flow:
@@ -65,12 +77,12 @@ type LocalTypeIdentParser gparselib.RegexpParser
 // NewLocalTypeIdentParser creates a new parser for the given regular expression.
 // If the regular expression is invalid an error is returned.
 func NewLocalTypeIdentParser() (*LocalTypeIdentParser, error) {
-	p, err := gparselib.NewRegexpParser(`^[A-Za-z][a-zA-Z0-9]*`)
+	p, err := gparselib.NewRegexpParser(`^` + identStart + identRest)
 	return (*LocalTypeIdentParser)(p), err
 }
 
 // ParseLocalTypeIdent parses a local (without package) type identifier.
-// * Regexp: [A-Za-z][a-zA-Z0-9]*
+// * Regexp: [\p{L}_][\p{L}\p{Nd}_]*
 // * Semantic result: The parsed text.
 //
 // flow:
@@ -195,3 +207,9 @@ func TextSemantic(pd *gparselib.ParseData, ctx interface{}) (*gparselib.ParseDat
 	pd.Result.Value = pd.Result.Text
 	return pd, ctx
 }
+
+// srcEnd returns the end offset of the successfully parsed text
+// without trailing white space.
+func srcEnd(pd *gparselib.ParseData) int {
+	return pd.Result.Pos + len(strings.TrimRight(pd.Result.Text, " \t\r\n"))
+}
@@ -52,7 +52,7 @@ func TestParseNameIdent(t *testing.T) {
 		}, {
 			givenName:        "simple 3",
 			givenContent:     `aB_CD`,
-			expectedValue:    "aB",
+			expectedValue:    "aB_CD",
 			expectedErrCount: 0,
 		}, {
 			givenName:        "simple 4",
@@ -64,6 +64,31 @@ func TestParseNameIdent(t *testing.T) {
 			givenContent:     `aBC123dEF`,
 			expectedValue:    "aBC123dEF",
 			expectedErrCount: 0,
+		}, {
+			givenName:        "unicode",
+			givenContent:     `größeÜber٣ ok`,
+			expectedValue:    "größeÜber٣",
+			expectedErrCount: 0,
+		}, {
+			givenName:        "unicode no case",
+			givenContent:     `処理`,
+			expectedValue:    "処理",
+			expectedErrCount: 0,
+		}, {
+			givenName:        "unicode upper case",
+			givenContent:     `Größe`,
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "underscore start",
+			givenContent:     `_x`,
+			expectedValue:    "_x",
+			expectedErrCount: 0,
+		}, {
+			givenName:        "underscore",
+			givenContent:     `a_b`,
+			expectedValue:    "a_b",
+			expectedErrCount: 0,
 		},
 	})
 }
@@ -89,6 +114,11 @@ func TestParsePackageIdent(t *testing.T) {
 			givenContent:     `123.`,
 			expectedValue:    nil,
 			expectedErrCount: 1,
+		}, {
+			givenName:        "no match mixed case",
+			givenContent:     `myPkg.Type`,
+			expectedValue:    nil,
+			expectedErrCount: 1,
 		}, {
 			givenName:        "simple 1",
 			givenContent:     `a.`,
@@ -114,6 +144,21 @@ func TestParsePackageIdent(t *testing.T) {
 			givenContent:     `abc123d.EF`,
 			expectedValue:    "abc123d",
 			expectedErrCount: 0,
+		}, {
+			givenName:        "unicode",
+			givenContent:     `straße.Haus`,
+			expectedValue:    "straße",
+			expectedErrCount: 0,
+		}, {
+			givenName:        "unicode upper case",
+			givenContent:     `Ärger.Haus`,
+			expectedValue:    nil,
+			expectedErrCount: 1,
+		}, {
+			givenName:        "underscore",
+			givenContent:     `order_v2.T`,
+			expectedValue:    "order_v2",
+			expectedErrCount: 0,
 		},
 	})
 }
@@ -136,7 +181,7 @@ func TestParseLocalTypeIdent(t *testing.T) {
 			expectedErrCount: 1,
 		}, {
 			givenName:        "no match 2",
-			givenContent:     `_A`,
+			givenContent:     `.A`,
 			expectedValue:    nil,
 			expectedErrCount: 1,
 		}, {
@@ -152,7 +197,7 @@ func TestParseLocalTypeIdent(t *testing.T) {
 		}, {
 			givenName:        "simple 3",
 			givenContent:     `Ab_cd`,
-			expectedValue:    "Ab",
+			expectedValue:    "Ab_cd",
 			expectedErrCount: 0,
 		}, {
 			givenName:        "simple 4",
@@ -164,6 +209,16 @@ func TestParseLocalTypeIdent(t *testing.T) {
 			givenContent:     `Abc123Def`,
 			expectedValue:    "Abc123Def",
 			expectedErrCount: 0,
+		}, {
+			givenName:        "unicode",
+			givenContent:     `Größe2`,
+			expectedValue:    "Größe2",
+			expectedErrCount: 0,
+		}, {
+			givenName:        "underscore start",
+			givenContent:     `_A`,
+			expectedValue:    "_A",
+			expectedErrCount: 0,
 		},
 	})
 }
@@ -0,0 +1,244 @@
+package gflowparser
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/flowdev/gflowparser/data"
+	"github.com/flowdev/gflowparser/data2svg"
+	"github.com/flowdev/gflowparser/svg"
+)
+
+// SubflowMode tells how components that are implemented by other flows
+// (subflows) are drawn.
+type SubflowMode int
+
+// Available subflow modes.
+const (
+	// SubflowNone draws all components as simple boxes.
+	SubflowNone SubflowMode = iota
+	// SubflowExpand draws subflows inline as nested boxes.
+	// Subflows are always drawn in rows.
+	SubflowExpand
+	// SubflowLink lets components link to the diagrams of their subflows.
+	// Only SVG and draw.io diagrams support links.
+	SubflowLink
+)
+
+// Subflows is a set of flows that can implement the components of other
+// flows.
+// A component type resolves to a flow if its local type is the name of the
+// flow with an upper case first letter (e.g.: type CheckOrder and flow
+// checkOrder).
+// Flows of other files are added with a package name (usually the name of
+// the directory of the file) and the component type has to use it,
+// e.g.: [check order.CheckOrder]
+// Inside of a subflow its own package is the default.
+// The flows of the converted file are always added with the empty package.
+type Subflows struct {
+	flows map[string]subflow
+}
+
+type subflow struct {
+	pkg  string
+	flow data.Flow
+	src  data2svg.Whereer
+}
+
+// NewSubflows creates a new, empty set of subflows.
+func NewSubflows() *Subflows {
+	return &Subflows{flows: make(map[string]subflow)}
+}
+
+// AddFlowFile adds all named flows of the flow file with the given package
+// to the set.
+// The source (usually a data.FileSet) is used for error messages and can be
+// nil.
+func (s *Subflows) AddFlowFile(pkg string, ff data.FlowFile, src data2svg.Whereer) {
+	for _, flow := range ff.Flows {
+		if flow.Name == "" {
+			continue
+		}
+		fsrc := src
+		if fsrc == nil {
+			fsrc = data.NewFile(flow.Name, "")
+		}
+		s.flows[pkg+"."+flow.Name] = subflow{pkg: pkg, flow: flow, src: fsrc}
+	}
+}
+
+// AddFlowFileDSL parses the flow file given as DSL string and adds all of
+// its flows with the given package to the set.
+// A single unnamed flow gets the name of the file without extension.
+func (s *Subflows) AddFlowFileDSL(pkg, fileContent, fileName string) error {
+	ff, src, _, err := parseFlowFileDSL(fileContent, fileName, nil)
+	if err != nil {
+		return err
+	}
+	if len(ff.Flows) == 1 && ff.Flows[0].Name == "" {
+		base := filepath.Base(fileName)
+		ff.Flows[0].Name = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	s.AddFlowFile(pkg, ff, src)
+	return nil
+}
+
+// Resolve returns the flow implementing components of the given type and
+// its package.
+// The package of the type defaults to the given one.
+func (s *Subflows) Resolve(t data.Type, pkg string) (flow data.Flow, flowPkg string, ok bool) {
+	if s == nil || t.LocalType == "" {
+		return data.Flow{}, "", false
+	}
+	if t.Package != "" {
+		pkg = t.Package
+	}
+	sub, ok := s.flows[pkg+"."+lowerFirst(t.LocalType)]
+	if !ok {
+		return data.Flow{}, "", false
+	}
+	return sub.flow, sub.pkg, true
+}
+
+// withFlowFile returns a copy of the set that contains the named flows of
+// the flow file with the empty package, too.
+func (s *Subflows) withFlowFile(ff data.FlowFile, src data2svg.Whereer) *Subflows {
+	ns := NewSubflows()
+	if s != nil {
+		for k, sub := range s.flows {
+			ns.flows[k] = sub
+		}
+	}
+	ns.AddFlowFile("", ff, src)
+	return ns
+}
+
+func lowerFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToLower(r)) + s[n:]
+}
+
+// defaultSubflowLink links to the SVG diagram of the subflow in the same
+// directory or the directory of its package.
+func defaultSubflowLink(pkg, name string) string {
+	if pkg == "" {
+		return name + ".svg"
+	}
+	return pkg + "/" + name + ".svg"
+}
+
+// subflowApplier adds subflows or links to the operations of converted
+// flows.
+type subflowApplier struct {
+	subs  *Subflows
+	mode  SubflowMode
+	link  func(pkg, name string) string
+	attrs []string        // attributes to show
+	path  map[string]bool // subflows that are expanded already (circles)
+}
+
+func newSubflowApplier(subs *Subflows, opts Options, flow data.Flow) *subflowApplier {
+	link := opts.SubflowLink
+	if link == nil {
+		link = defaultSubflowLink
+	}
+	return &subflowApplier{
+		subs:  subs,
+		mode:  opts.SubflowMode,
+		link:  link,
+		attrs: opts.Attributes,
+		path:  map[string]bool{"." + flow.Name: flow.Name != ""},
+	}
+}
+
+func (sa *subflowApplier) applyToFlow(sf svg.Flow, flow data.Flow, pkg string) error {
+	types := compTypes(flow)
+	var err error
+	var applyShapes func(shapes [][]svg.Shape)
+	applyShapes = func(shapes [][]svg.Shape) {
+		for _, row := range shapes {
+			for _, shape := range row {
+				switch s := shape.(type) {
+				case *svg.Op:
+					if err == nil {
+						err = sa.applyToOp(s, types, pkg)
+					}
+				case *svg.Split:
+					applyShapes(s.Shapes)
+				}
+			}
+		}
+	}
+	applyShapes(sf.Shapes)
+	return err
+}
+
+func (sa *subflowApplier) applyToGraph(g svg.Graph, flow data.Flow) error {
+	types := compTypes(flow)
+	for _, n := range g.Nodes {
+		if n.Op == nil {
+			continue
+		}
+		if err := sa.applyToOp(n.Op, types, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sa *subflowApplier) applyToOp(op *svg.Op, types map[string]data.Type, pkg string) error {
+	if op.Main == nil || len(op.Main.Text) == 0 {
+		return nil
+	}
+	t, ok := types[op.Main.Text[0]] // the name of the component
+	if !ok {
+		return nil
+	}
+	flow, flowPkg, ok := sa.subs.Resolve(t, pkg)
+	if !ok {
+		return nil
+	}
+	if sa.mode == SubflowLink {
+		op.Link = sa.link(flowPkg, flow.Name)
+		return nil
+	}
+	key := flowPkg + "." + flow.Name
+	if sa.path[key] { // don't expand circles forever
+		return nil
+	}
+	flow = flow.FilterAttributes(sa.attrs)
+	sf, err := data2svg.Convert(flow, sa.subs.flows[key].src)
+	if err != nil {
+		return err
+	}
+	sa.path[key] = true
+	err = sa.applyToFlow(sf, flow, flowPkg)
+	delete(sa.path, key)
+	if err != nil {
+		return err
+	}
+	op.Subflow = &sf
+	return nil
+}
+
+// compTypes returns the types of all components of the flow by name.
+func compTypes(flow data.Flow) map[string]data.Type {
+	types := make(map[string]data.Type)
+	vague := make(map[string]bool)
+	data.Inspect(flow, func(n data.Node) bool {
+		switch c := n.(type) {
+		case data.Component:
+			if _, ok := types[c.Decl.Name]; !ok || (vague[c.Decl.Name] && !c.Decl.VagueType) {
+				types[c.Decl.Name] = c.Decl.Type
+				vague[c.Decl.Name] = c.Decl.VagueType
+			}
+			return false
+		case data.Arrow:
+			return false
+		}
+		return true
+	})
+	return types
+}
@@ -4,62 +4,71 @@ func arrowDataToSVG(a *Arrow, sf *svgFlow, lsr *svgRect, x int, y int,
 ) (nsf *svgFlow, nx, ny int, mod *moveData) {
 	var srcPortText, dstPortText *svgText
 	dataTexts := make([]*svgText, 0, 8)
+	cfg := sf.cfg
 
-	y += 24
+	y += cfg.LineHeight
 	portLen := 0 // length in chars NOT pixels
 	if a.HasSrcOp {
-		portLen = len(a.SrcPort)
+		portLen = textLen(a.SrcPort)
 	}
 	if a.HasDstOp {
-		portLen += len(a.DstPort)
+		portLen += textLen(a.DstPort)
 	}
 
-	dataLen := maxLen(a.DataType)
-	width := max(
-		portLen+2,
-		dataLen+2,
-	)*12 + 6 + // 6 so the source port text isn't glued to the op
-		12 // last 12 is for tip of arrow
+	dataLines := cfg.fitTexts(a.DataType, dataBreaks)
+	dataLen := maxLineLen(dataLines)
+	width := cfg.textWidth(max(portLen, dataLen)) + cfg.MinArrowLength +
+		cfg.Padding + // so the source port text isn't glued to the op
+		cfg.tipSpace()
 
-	sf.Texts, x = addSrcPort(a, sf.Texts, x, y)
+	sf.Texts, x = addSrcPort(a, cfg, sf.Texts, x, y)
 	if a.SrcPort != "" { // remember this text as we might have to move it down
 		srcPortText = sf.Texts[len(sf.Texts)-1]
 	}
 
-	if len(a.DataType) != 0 {
-		dataX := x + ((width-12)-dataLen*12)/2
-		for i, text := range a.DataType {
+	if len(dataLines) != 0 {
+		dataX := x + ((width-cfg.tipSpace())-cfg.textWidth(dataLen))/2
+		for i, line := range dataLines {
 			if i > 0 {
-				y += 22
+				y += cfg.DataLineHeight
 				if srcPortText != nil {
-					srcPortText.Y += 22
+					srcPortText.Y += cfg.DataLineHeight
 				}
 			}
 			st := &svgText{
-				X: dataX, Y: y - 8,
-				Width: len(text) * 12,
-				Text:  text,
+				X: dataX, Y: y - cfg.FontSize/2,
+				Width: cfg.textWidth(textLen(line.text)),
+				Text:  line.text,
+				Title: line.title,
 			}
 			sf.Texts = append(sf.Texts, st)
 			dataTexts = append(dataTexts, st)
 		}
 	}
 
-	sf.Arrows = append(sf.Arrows, &svgArrow{
-		X1: x, Y1: y,
-		X2: x + width, Y2: y,
-		XTip1: x + width - 8, YTip1: y - 8,
-		XTip2: x + width - 8, YTip2: y + 8,
-	})
+	arr := newSVGArrow(cfg, x, x+width, y)
+	arr.IsError = cfg.MarkErrors && cfg.isErrorArrow(a, a.HasSrcOp, a.HasDstOp)
+	sf.Arrows = append(sf.Arrows, arr)
 	x += width
 
-	sf.Texts, x = addDstPort(a, sf.Texts, x, y)
+	sf.Texts, x = addDstPort(a, cfg, sf.Texts, x, y)
 	if a.DstPort != "" {
 		dstPortText = sf.Texts[len(sf.Texts)-1]
 	}
+	if a.HasSrcOp {
+		addPortEnd(sf, &arr.X1, &arr.Y1, true, a.SrcPort, srcPortText, arr)
+	} else {
+		addConnector(sf, true, a.SrcPort, srcPortText)
+	}
+	if a.HasDstOp {
+		addPortEnd(sf, &arr.X2, &arr.Y2, false, a.DstPort, dstPortText, arr)
+	} else {
+		addErrorEnd(sf, a.DstPort, arr, dstPortText, dataTexts)
+		addConnector(sf, false, a.DstPort, dstPortText)
+	}
 
-	yn := y + 24
-	adjustLastRect(lsr, yn-12)
+	yn := y + cfg.LineHeight
+	adjustLastRect(lsr, yn-cfg.LineHeight/2)
 
 	return sf, x, yn, &moveData{
 		arrow:       sf.Arrows[len(sf.Arrows)-1],
@@ -69,43 +78,58 @@ func arrowDataToSVG(a *Arrow, sf *svgFlow, lsr *svgRect, x int, y int,
 	}
 }
 
-func addSrcPort(a *Arrow, sts []*svgText, x, y int) ([]*svgText, int) {
+func newSVGArrow(cfg *LayoutConfig, x1, x2, y int) *svgArrow {
+	return &svgArrow{
+		X1: x1, Y1: y,
+		X2: x2, Y2: y,
+		XTip1: x2 - cfg.ArrowTipSize, YTip1: y - cfg.ArrowTipSize,
+		XTip2: x2 - cfg.ArrowTipSize, YTip2: y + cfg.ArrowTipSize,
+	}
+}
+
+func addSrcPort(a *Arrow, cfg *LayoutConfig, sts []*svgText, x, y int,
+) ([]*svgText, int) {
 	if !a.HasSrcOp { // text before the arrow
 		if a.SrcPort != "" {
 			sts = append(sts, &svgText{
-				X: x + 1, Y: y + 6,
-				Width: len(a.SrcPort)*12 - 2,
-				Text:  a.SrcPort,
+				X: x + 1, Y: y + cfg.descent(),
+				Width:  cfg.textWidth(textLen(a.SrcPort)) - 2,
+				Text:   a.SrcPort,
+				onLine: true,
 			})
 		}
-		x += 12 * len(a.SrcPort)
+		x += cfg.textWidth(textLen(a.SrcPort))
 	} else { // text under the arrow
 		if a.SrcPort != "" {
 			sts = append(sts, &svgText{
-				X: x + 6, Y: y + 20,
-				Width: len(a.SrcPort) * 12,
+				X: x + cfg.Padding, Y: y + cfg.portTextOffset(),
+				Width: cfg.textWidth(textLen(a.SrcPort)),
 				Text:  a.SrcPort,
+				under: true,
 			})
 		}
 	}
 	return sts, x
 }
 
-func addDstPort(a *Arrow, sts []*svgText, x, y int) ([]*svgText, int) {
+func addDstPort(a *Arrow, cfg *LayoutConfig, sts []*svgText, x, y int,
+) ([]*svgText, int) {
 	if !a.HasDstOp {
 		if a.DstPort != "" { // text after the arrow
 			sts = append(sts, &svgText{
-				X: x + 3, Y: y + 6,
-				Width: len(a.DstPort)*12 - 2,
-				Text:  a.DstPort,
+				X: x + cfg.Padding/2, Y: y + cfg.descent(),
+				Width:  cfg.textWidth(textLen(a.DstPort)) - 2,
+				Text:   a.DstPort,
+				onLine: true,
 			})
 		}
-		x += 3 + 12*len(a.DstPort)
+		x += cfg.Padding/2 + cfg.textWidth(textLen(a.DstPort))
 	} else if a.DstPort != "" { // text under the arrow
 		sts = append(sts, &svgText{
-			X: x - len(a.DstPort)*12 - 12, Y: y + 20,
-			Width: len(a.DstPort) * 12,
+			X: x - cfg.textWidth(textLen(a.DstPort)) - cfg.tipSpace(), Y: y + cfg.portTextOffset(),
+			Width: cfg.textWidth(textLen(a.DstPort)),
 			Text:  a.DstPort,
+			under: true,
 		})
 	}
 	return sts, x
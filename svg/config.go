@@ -0,0 +1,130 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+)
+
+// LayoutConfig contains the metrics used for laying out a diagram.
+// All values are in pixels.
+type LayoutConfig struct {
+	FontSize       int // size of the (monospace) font of all texts
+	CharWidth      int // width of a single character
+	LineHeight     int // height of a line of text
+	DataLineHeight int // height of an additional line of data types of an arrow
+	Padding        int // padding between texts, lines and boxes
+	ArrowTipSize   int // length of the lines of the tip of an arrow
+	RowGap         int // vertical gap between rows
+	MinArrowLength int // minimal length of an arrow (without its tip)
+
+	// MaxTextWidth is the maximal width of a line of text (0 means unlimited).
+	// Longer data type lists are wrapped at commas and longer types at
+	// package dots.
+	MaxTextWidth int
+	// TruncateTexts lets texts that are too long be truncated with an
+	// ellipsis instead of being wrapped.
+	// The full text is available as tooltip.
+	TruncateTexts bool
+	// PortMarkers lets ports be drawn as small squares on the edges of
+	// operations.
+	// All arrows using the same port share its square.
+	PortMarkers bool
+	// ContinuationMarkers lets continuations ('...1', '...retryPath') be
+	// drawn as numbered connector circles at both ends of a wrapped arrow.
+	// The continuation itself is available as tooltip.
+	ContinuationMarkers bool
+
+	// ErrorPorts are the names of the ports used for errors.
+	ErrorPorts []string
+	// MarkErrors lets error arrows (starting at an error port of an
+	// operation or ending at an outer error port) be drawn dashed and red.
+	MarkErrors bool
+	// CollapseErrors lets all arrows to the same outer error port end in a
+	// single shared error sink at the right margin.
+	CollapseErrors bool
+}
+
+// DefaultLayoutConfig returns the default metrics used by FromFlowData and
+// FromGraphData.
+func DefaultLayoutConfig() LayoutConfig {
+	return LayoutConfig{
+		FontSize:       16,
+		CharWidth:      12,
+		LineHeight:     24,
+		DataLineHeight: 22,
+		Padding:        6,
+		ArrowTipSize:   8,
+		RowGap:         5,
+		MinArrowLength: 24,
+		ErrorPorts:     []string{"error", "err"},
+	}
+}
+
+// Scale returns a copy of the configuration with all metrics multiplied by
+// the given factor.
+// This is useful for compact diagrams (e.g. on slides) or larger ones (e.g.
+// for print).
+func (c LayoutConfig) Scale(factor float64) LayoutConfig {
+	scale := func(v int) int {
+		return max(int(math.Round(float64(v)*factor)), 1)
+	}
+	s := c
+	s.FontSize = scale(c.FontSize)
+	s.CharWidth = scale(c.CharWidth)
+	s.LineHeight = scale(c.LineHeight)
+	s.DataLineHeight = scale(c.DataLineHeight)
+	s.Padding = scale(c.Padding)
+	s.ArrowTipSize = scale(c.ArrowTipSize)
+	s.RowGap = scale(c.RowGap)
+	s.MinArrowLength = scale(c.MinArrowLength)
+	s.MaxTextWidth = int(math.Round(float64(c.MaxTextWidth) * factor))
+	return s
+}
+
+func validateLayoutConfig(c *LayoutConfig) error {
+	if c.MaxTextWidth < 0 {
+		return fmt.Errorf("maximal text width mustn't be negative but is: %d",
+			c.MaxTextWidth)
+	}
+	for _, m := range []struct {
+		name  string
+		value int
+	}{
+		{"FontSize", c.FontSize},
+		{"CharWidth", c.CharWidth},
+		{"LineHeight", c.LineHeight},
+		{"DataLineHeight", c.DataLineHeight},
+		{"Padding", c.Padding},
+		{"ArrowTipSize", c.ArrowTipSize},
+		{"RowGap", c.RowGap},
+		{"MinArrowLength", c.MinArrowLength},
+	} {
+		if m.value <= 0 {
+			return fmt.Errorf("layout metric %s has to be positive but is: %d",
+				m.name, m.value)
+		}
+	}
+	return nil
+}
+
+// descent is the distance between the baseline of a text and the bottom of
+// its line.
+func (c *LayoutConfig) descent() int {
+	return c.FontSize * 3 / 8
+}
+
+// tipSpace is the horizontal space reserved for the tip of an arrow.
+func (c *LayoutConfig) tipSpace() int {
+	return c.ArrowTipSize * 3 / 2
+}
+
+// portTextOffset is the vertical distance of the baseline of a port text
+// under an arrow from the arrow.
+func (c *LayoutConfig) portTextOffset() int {
+	return c.FontSize + c.FontSize/4
+}
+
+// textWidth is the width of a text with n characters.
+func (c *LayoutConfig) textWidth(n int) int {
+	return n * c.CharWidth
+}
@@ -0,0 +1,54 @@
+package svg_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/flowdev/gflowparser/svg"
+)
+
+func TestLayoutConfigScale(t *testing.T) {
+	cfg := svg.DefaultLayoutConfig().Scale(0.5)
+	if cfg.FontSize != 8 || cfg.CharWidth != 6 || cfg.LineHeight != 12 {
+		t.Errorf("Unexpected scaled config: %#v", cfg)
+	}
+	cfg = svg.DefaultLayoutConfig().Scale(0.01)
+	if cfg.Padding != 1 || cfg.RowGap != 1 {
+		t.Errorf("Expected all metrics to be at least 1 but got: %#v", cfg)
+	}
+}
+
+func TestFromFlowDataWithConfig(t *testing.T) {
+	cfg := svg.DefaultLayoutConfig().Scale(2)
+	buf, err := svg.FromFlowDataWithConfig(svg.BigTestFlowData, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !strings.Contains(string(buf), `font-size="32"`) {
+		t.Error("Expected scaled font size in SVG output.")
+	}
+	def, err := svg.FromFlowData(svg.BigTestFlowData)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if string(buf) == string(def) {
+		t.Error("Expected a different diagram for the scaled config.")
+	}
+
+	cfg = svg.DefaultLayoutConfig()
+	cfg.MaxTextWidth = 60
+	cfg.TruncateTexts = true
+	buf, err = svg.FromFlowDataWithConfig(svg.BigTestFlowData, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !strings.Contains(string(buf), `...<title>`) {
+		t.Error("Expected truncated text with tooltip in SVG output.")
+	}
+
+	cfg.CharWidth = 0
+	_, err = svg.FromFlowDataWithConfig(svg.BigTestFlowData, cfg)
+	if err == nil {
+		t.Error("Expected an error for an invalid config but didn't get one.")
+	}
+}
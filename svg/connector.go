@@ -0,0 +1,64 @@
+package svg
+
+import (
+	"strconv"
+	"strings"
+)
+
+// continuationSignal starts the port text of a continuation ('...1',
+// '...retryPath').
+const continuationSignal = "..."
+
+// connector is an end of a wrapped arrow at a continuation.
+type connector struct {
+	src  bool     // start of the continued arrow (else end of the wrapped one)
+	text *svgText // label of the continuation
+}
+
+type svgCircle struct {
+	CX, CY int
+	R      int
+}
+
+func isContinuation(port string) bool {
+	return strings.HasPrefix(port, continuationSignal)
+}
+
+func addConnector(sf *svgFlow, src bool, port string, text *svgText) {
+	if !sf.cfg.ContinuationMarkers || text == nil || !isContinuation(port) {
+		return
+	}
+	sf.connectors = append(sf.connectors, &connector{src: src, text: text})
+}
+
+// addConnectorMarkers replaces the labels of continuations with numbered
+// circles at both ends of wrapped arrows.
+// The continuations are numbered in order of appearance and the original
+// label becomes the tooltip of the number.
+func addConnectorMarkers(sf *svgFlow) {
+	cfg := sf.cfg
+	numbers := make(map[string]int, len(sf.connectors))
+	for _, c := range sf.connectors {
+		t := c.text
+		num, ok := numbers[t.Text]
+		if !ok {
+			num = len(numbers) + 1
+			numbers[t.Text] = num
+		}
+		label := strconv.Itoa(num)
+		w := cfg.textWidth(textLen(label))
+		r := max(cfg.LineHeight*3/8, w/2+cfg.Padding/2)
+
+		cy := t.Y - cfg.descent()
+		cx := t.X - cfg.Padding/2 + r // right after the tip of the arrow
+		if c.src {
+			cx = t.X + t.Width + 1 - r // right before the start of the arrow
+		}
+		sf.Circles = append(sf.Circles, &svgCircle{CX: cx, CY: cy, R: r})
+
+		t.Title = t.Text
+		t.Text = label
+		t.X = cx - w/2
+		t.Width = w
+	}
+}
@@ -0,0 +1,64 @@
+package svg
+
+import (
+	"testing"
+)
+
+func TestAddConnectorMarkers(t *testing.T) {
+	f := Flow{Shapes: [][]Shape{
+		{
+			&Arrow{DataType: []string{"(data)"}, SrcPort: "in", HasDstOp: true},
+			&Op{Main: &Rect{Text: []string{"a"}}},
+			&Arrow{HasSrcOp: true, DstPort: "...retryPath"},
+		}, {
+			&Arrow{HasDstOp: true},
+			&Op{Main: &Rect{Text: []string{"b"}}},
+			&Arrow{HasSrcOp: true, DstPort: "...1"},
+		}, {
+			&Arrow{DataType: []string{"(data)"}, SrcPort: "...retryPath", HasDstOp: true},
+			&Op{Main: &Rect{Text: []string{"c"}}},
+			&Arrow{HasSrcOp: true, DstPort: "out"},
+		}, {
+			&Arrow{SrcPort: "...1", DstPort: "out2"},
+		},
+	}}
+	cfg := DefaultLayoutConfig()
+	cfg.ContinuationMarkers = true
+	sf := flowDataToSVGFlow(f, &cfg)
+
+	if len(sf.Circles) != 4 {
+		t.Fatalf("Expected 4 connector circles but got: %d", len(sf.Circles))
+	}
+	numbers := make(map[string]string)
+	for _, st := range sf.Texts {
+		if st.Title == "" {
+			continue
+		}
+		if n, ok := numbers[st.Title]; ok && n != st.Text {
+			t.Errorf("Expected number %q for %q but got %q.", n, st.Title, st.Text)
+		}
+		numbers[st.Title] = st.Text
+	}
+	expected := map[string]string{"...retryPath": "1", "...1": "2"}
+	for title, n := range expected {
+		if numbers[title] != n {
+			t.Errorf("Expected number %q for %q but got %q.", n, title, numbers[title])
+		}
+	}
+
+	first, last := sf.Arrows[1], sf.Arrows[4]
+	if c := sf.Circles[0]; c.CX-c.R != first.X2 || c.CY != first.Y2 {
+		t.Errorf("Expected the first circle at the tip of the wrapped arrow (%d, %d) but got: %+v",
+			first.X2, first.Y2, *c)
+	}
+	if c := sf.Circles[2]; c.CX+c.R != last.X1 || c.CY != last.Y1 {
+		t.Errorf("Expected the third circle at the start of the continued arrow (%d, %d) but got: %+v",
+			last.X1, last.Y1, *c)
+	}
+
+	cfg.ContinuationMarkers = false
+	sf = flowDataToSVGFlow(f, &cfg)
+	if len(sf.Circles) != 0 {
+		t.Errorf("Expected no connector circles but got: %d", len(sf.Circles))
+	}
+}
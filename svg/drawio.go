@@ -0,0 +1,282 @@
+package svg
+
+import (
+	"bytes"
+	"html"
+	"math"
+	"strconv"
+	"text/template"
+)
+
+const drawioDiagram = `<mxfile host="gflowparser">
+  <diagram id="flow" name="flow">
+    <mxGraphModel grid="0" page="0" pageWidth="{{.Width}}" pageHeight="{{.Height}}">
+      <root>
+        <mxCell id="0"/>
+        <mxCell id="1" parent="0"/>
+{{- range .Cells}}
+{{- if .Edge}}
+        <mxCell id="{{.ID}}" style="{{.Style}}" edge="1" parent="{{.Parent}}"{{if .Source}} source="{{.Source}}"{{end}}{{if .Target}} target="{{.Target}}"{{end}}>
+          <mxGeometry relative="1" as="geometry">
+            <mxPoint x="{{.X}}" y="{{.Y}}" as="sourcePoint"/>
+            <mxPoint x="{{.X2}}" y="{{.Y2}}" as="targetPoint"/>
+{{- if .Points}}
+            <Array as="points">
+{{- range .Points}}
+              <mxPoint x="{{.X}}" y="{{.Y}}"/>
+{{- end}}
+            </Array>
+{{- end}}
+          </mxGeometry>
+        </mxCell>
+{{- else if or .Tooltip .Link}}
+        <UserObject id="{{.ID}}" label="{{.Value}}"{{if .Tooltip}} tooltip="{{.Tooltip}}"{{end}}{{if .Link}} link="{{.Link}}"{{end}}>
+          <mxCell style="{{.Style}}" vertex="1" parent="{{.Parent}}">
+            <mxGeometry x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}" as="geometry"/>
+          </mxCell>
+        </UserObject>
+{{- else}}
+        <mxCell id="{{.ID}}" value="{{.Value}}" style="{{.Style}}" vertex="1" parent="{{.Parent}}">
+          <mxGeometry x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}" as="geometry"/>
+        </mxCell>
+{{- end}}
+{{- end}}
+      </root>
+    </mxGraphModel>
+  </diagram>
+</mxfile>
+`
+
+const (
+	drawioOpStyle      = "rounded=1;arcSize=20;absoluteArcSize=1;html=1;container=1;collapsible=0;fillColor=#60C4FF;strokeColor=#000000;strokeWidth=2.5;"
+	drawioSubflowStyle = "rounded=1;arcSize=20;absoluteArcSize=1;html=1;container=1;collapsible=0;fillColor=none;strokeColor=#000000;strokeWidth=2.5;dashed=1;dashPattern=8 4;"
+	drawioPluginStyle  = "rounded=0;html=1;fillColor=#20E020;strokeColor=#000000;strokeWidth=2.5;"
+	drawioMarkerStyle  = "rounded=0;html=1;fillColor=#FFFFFF;strokeColor=#000000;strokeWidth=1.5;"
+	drawioCircleStyle  = "ellipse;html=1;fillColor=#FFFFFF;strokeColor=#000000;strokeWidth=1.5;"
+	drawioLineStyle    = "line;html=1;strokeColor=#000000;strokeWidth=1;"
+	drawioTextStyle    = "text;html=1;align=left;verticalAlign=bottom;spacing=0;overflow=visible;fontFamily=monospace;"
+	drawioArrowStyle   = "edgeStyle=none;rounded=0;html=1;endArrow=open;strokeWidth=2.5;"
+)
+
+// drawioCell is a vertex or an edge of a draw.io diagram.
+// The coordinates of vertices are relative to their parent.
+// Edges use X, Y and X2, Y2 for their start and end points.
+type drawioCell struct {
+	ID      string
+	Parent  string
+	Value   string
+	Tooltip string
+	Link    string
+	Style   string
+	Edge    bool
+	X, Y    int
+	Width   int
+	Height  int
+	X2, Y2  int
+	Source  string
+	Target  string
+	Points  []svgPoint
+}
+
+type drawioFlow struct {
+	Width, Height int
+	Cells         []*drawioCell
+}
+
+var drawioTmpl = template.Must(template.New("drawio").Parse(drawioDiagram))
+
+// DrawIOFromFlowData creates a draw.io diagram from flow data using the given
+// layout configuration.
+// The diagram has got exactly the same geometry as the one created by
+// FromFlowDataWithConfig, so it can be polished manually.
+// If the flow data or configuration isn't valid or the diagram can't be
+// created with its template, an error is returned.
+func DrawIOFromFlowData(f Flow, cfg LayoutConfig) ([]byte, error) {
+	err := validateLayoutConfig(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	err = validateFlowData(f)
+	if err != nil {
+		return nil, err
+	}
+
+	sf := flowDataToSVGFlow(f, &cfg)
+
+	return svgFlowToDrawIO(sf)
+}
+
+// DrawIOFromGraphData creates a draw.io diagram from graph data using the
+// given layout configuration.
+// The diagram has got exactly the same geometry as the one created by
+// FromGraphDataWithConfig, so it can be polished manually.
+// If the graph data or configuration isn't valid or the diagram can't be
+// created with its template, an error is returned.
+func DrawIOFromGraphData(g Graph, cfg LayoutConfig) ([]byte, error) {
+	err := validateLayoutConfig(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	err = validateGraphData(g)
+	if err != nil {
+		return nil, err
+	}
+
+	lg, err := newLayeredGraph(g, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sf := layeredGraphToSVGFlow(lg)
+
+	return svgFlowToDrawIO(sf)
+}
+
+// svgFlowToDrawIO converts the computed layout into draw.io cells.
+// Operations become containers for their plugins, separator lines, texts
+// and port markers, so they can be moved as a whole.
+// Arrows are connected to the operations at their exact positions.
+func svgFlowToDrawIO(sf *svgFlow) ([]byte, error) {
+	df := &drawioFlow{Width: sf.TotalWidth, Height: sf.TotalHeight}
+	ids := make(map[*svgRect]string, len(sf.Rects))
+	add := func(c *drawioCell) *drawioCell {
+		c.ID = strconv.Itoa(len(df.Cells) + 2)
+		df.Cells = append(df.Cells, c)
+		return c
+	}
+	// container returns the innermost operation containing the point
+	// (ignoring the operation self) or nil.
+	container := func(x, y int, self *svgRect, subflowsOnly bool) *svgRect {
+		var c *svgRect
+		for _, r := range sf.Rects {
+			if r == self || r.IsPlugin || (subflowsOnly && !r.IsSubflow) {
+				continue
+			}
+			if x >= r.X && x <= r.X+r.Width && y >= r.Y && y <= r.Y+r.Height &&
+				(c == nil || r.Width*r.Height < c.Width*c.Height) {
+				c = r
+			}
+		}
+		return c
+	}
+	// parent returns the ID and position of the operation containing the
+	// point or the root layer.
+	parent := func(x, y int) (string, int, int) {
+		if r := container(x, y, nil, false); r != nil {
+			return ids[r], r.X, r.Y
+		}
+		return "1", 0, 0
+	}
+
+	for _, r := range sf.Rects {
+		if r.IsPlugin {
+			continue
+		}
+		p, px, py := "1", 0, 0
+		if c := container(r.X, r.Y, r, true); c != nil {
+			p, px, py = ids[c], c.X, c.Y
+		}
+		style := drawioOpStyle
+		if r.IsSubflow {
+			style = drawioSubflowStyle
+		}
+		c := rectToDrawIO(r, p, px, py, style)
+		c.Link = html.EscapeString(r.Link)
+		ids[r] = add(c).ID
+	}
+	for _, r := range sf.Rects {
+		if r.IsPlugin {
+			p, px, py := parent(r.X, r.Y)
+			add(rectToDrawIO(r, p, px, py, drawioPluginStyle))
+		}
+	}
+	for _, l := range sf.Lines {
+		p, px, py := parent(l.X1, l.Y1)
+		add(&drawioCell{
+			Parent: p, Style: drawioLineStyle,
+			X: l.X1 - px, Y: l.Y1 - 1 - py,
+			Width: l.X2 - l.X1, Height: 2,
+		})
+	}
+	for _, m := range sf.PortMarkers {
+		p, px, py := "1", 0, 0
+		cx, cy := m.X+m.Width/2, m.Y+m.Height/2
+		if r := findOpRect(sf, cx, cy, true); r != nil {
+			p, px, py = ids[r], r.X, r.Y
+		} else if r := findOpRect(sf, cx, cy, false); r != nil {
+			p, px, py = ids[r], r.X, r.Y
+		}
+		add(rectToDrawIO(m, p, px, py, drawioMarkerStyle))
+	}
+	for _, c := range sf.Circles {
+		add(&drawioCell{
+			Parent: "1", Style: drawioCircleStyle,
+			X: c.CX - c.R, Y: c.CY - c.R,
+			Width: 2 * c.R, Height: 2 * c.R,
+		})
+	}
+	for _, a := range sf.Arrows {
+		add(arrowToDrawIO(a, sf, ids))
+	}
+	lh := sf.cfg.LineHeight
+	for _, t := range sf.Texts {
+		p, px, py := parent(t.X, t.Y)
+		add(&drawioCell{
+			Parent: p, Style: drawioTextStyle + "fontSize=" + strconv.Itoa(sf.FontSize) + ";",
+			Value:   html.EscapeString(t.Text),
+			Tooltip: html.EscapeString(t.Title),
+			X:       t.X - px, Y: t.Y + sf.cfg.descent() - lh - py,
+			Width: t.Width, Height: lh,
+		})
+	}
+
+	buf := bytes.Buffer{}
+	err := drawioTmpl.Execute(&buf, df)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func rectToDrawIO(r *svgRect, parent string, px, py int, style string) *drawioCell {
+	return &drawioCell{
+		Parent: parent, Style: style,
+		X: r.X - px, Y: r.Y - py,
+		Width: r.Width, Height: r.Height,
+	}
+}
+
+func arrowToDrawIO(a *svgArrow, sf *svgFlow, ids map[*svgRect]string) *drawioCell {
+	style := drawioArrowStyle + "endSize=" + strconv.Itoa(sf.cfg.ArrowTipSize) + ";"
+	if a.IsError {
+		style += "strokeColor=#D00000;dashed=1;dashPattern=8 4;"
+	} else {
+		style += "strokeColor=#000000;"
+	}
+	c := &drawioCell{
+		Parent: "1", Edge: true,
+		X: a.X1, Y: a.Y1,
+		X2: a.X2, Y2: a.Y2,
+	}
+	if a.path != nil {
+		c.X, c.Y = a.path.Points[0].X, a.path.Points[0].Y
+		c.Points = a.path.Points[1:]
+	}
+	if r := findOpRect(sf, c.X, c.Y, true); r != nil {
+		c.Source = ids[r]
+		style += "exitX=1;exitY=" + relativePos(c.Y-r.Y, r.Height) + ";exitDx=0;exitDy=0;"
+	}
+	if r := findOpRect(sf, c.X2, c.Y2, false); r != nil {
+		c.Target = ids[r]
+		style += "entryX=0;entryY=" + relativePos(c.Y2-r.Y, r.Height) + ";entryDx=0;entryDy=0;"
+	}
+	c.Style = style
+	return c
+}
+
+// relativePos returns the position relative to the size with at most four
+// decimal places.
+func relativePos(pos, size int) string {
+	v := math.Round(float64(pos)/float64(size)*10000) / 10000
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,141 @@
+package svg_test
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/flowdev/gflowparser/svg"
+)
+
+type drawioPoint struct {
+	X  int    `xml:"x,attr"`
+	Y  int    `xml:"y,attr"`
+	As string `xml:"as,attr"`
+}
+
+type drawioCell struct {
+	ID       string `xml:"id,attr"`
+	Value    string `xml:"value,attr"`
+	Style    string `xml:"style,attr"`
+	Vertex   string `xml:"vertex,attr"`
+	Edge     string `xml:"edge,attr"`
+	Parent   string `xml:"parent,attr"`
+	Source   string `xml:"source,attr"`
+	Target   string `xml:"target,attr"`
+	Geometry struct {
+		X      int           `xml:"x,attr"`
+		Y      int           `xml:"y,attr"`
+		Width  int           `xml:"width,attr"`
+		Height int           `xml:"height,attr"`
+		Points []drawioPoint `xml:"mxPoint"`
+		Array  []drawioPoint `xml:"Array>mxPoint"`
+	} `xml:"mxGeometry"`
+}
+
+type drawioFile struct {
+	Cells []drawioCell `xml:"diagram>mxGraphModel>root>mxCell"`
+}
+
+func TestDrawIOFromGraphData(t *testing.T) {
+	g := svg.Graph{
+		Nodes: []*svg.Node{
+			{ID: "#0", Port: "in"},
+			{ID: "a", Op: &svg.Op{
+				Main: &svg.Rect{Text: []string{"a", "A"}},
+				Plugins: []*svg.Plugin{{
+					Title: "semantics",
+					Rects: []*svg.Rect{{Text: []string{"Text"}}},
+				}},
+			}},
+			{ID: "b", Op: &svg.Op{Main: &svg.Rect{Text: []string{"b"}}}},
+			{ID: "c", Op: &svg.Op{Main: &svg.Rect{Text: []string{"c"}}}},
+		},
+		Edges: []*svg.Edge{
+			{From: "#0", To: "a", Arrow: &svg.Arrow{DataType: []string{"(x)"}, SrcPort: "in"}},
+			{From: "a", To: "b", Arrow: &svg.Arrow{SrcPort: "out", DstPort: "in"}},
+			{From: "a", To: "c", Arrow: &svg.Arrow{SrcPort: "error"}},
+		},
+	}
+	cfg := svg.DefaultLayoutConfig()
+	cfg.MarkErrors = true
+	buf, err := svg.DrawIOFromGraphData(g, cfg)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	df := drawioFile{}
+	err = xml.Unmarshal(buf, &df)
+	if err != nil {
+		t.Fatalf("Expected valid XML but got error: %s\n%s", err, buf)
+	}
+
+	cells := make(map[string]drawioCell, len(df.Cells))
+	texts := make(map[string]drawioCell)
+	var edges []drawioCell
+	for _, c := range df.Cells {
+		cells[c.ID] = c
+		switch {
+		case c.Edge == "1":
+			edges = append(edges, c)
+		case c.Value != "":
+			texts[c.Value] = c
+		}
+	}
+
+	opA := texts["a"].Parent
+	if opA == "1" || opA == "" {
+		t.Fatalf("Expected text 'a' to be grouped in its operation but got parent: %q", opA)
+	}
+	for _, txt := range []string{"A", "semantics:", "Text"} {
+		if texts[txt].Parent != opA {
+			t.Errorf("Expected text %q to be in operation %q but got parent: %q",
+				txt, opA, texts[txt].Parent)
+		}
+	}
+	if p := texts["in"].Parent; p != "1" {
+		t.Errorf("Expected outer port text to be on the root layer but got parent: %q", p)
+	}
+	if tx, ax := texts["A"].Geometry.X, cells[opA].Geometry.X; tx >= ax {
+		t.Errorf("Expected relative text position (%d) to be smaller than absolute op position (%d)", tx, ax)
+	}
+
+	if len(edges) != 3 {
+		t.Fatalf("Expected 3 edges but got: %d", len(edges))
+	}
+	if edges[0].Source != "" || edges[0].Target != opA {
+		t.Errorf("Expected first edge to connect only to %q but got: %q -> %q",
+			opA, edges[0].Source, edges[0].Target)
+	}
+	for _, e := range edges[1:] {
+		if e.Source != opA || e.Target == "" || e.Target == opA {
+			t.Errorf("Expected edge from operation %q to another one but got: %q -> %q",
+				opA, e.Source, e.Target)
+		}
+	}
+	if !strings.Contains(edges[2].Style, "dashed=1") {
+		t.Errorf("Expected error edge to be dashed but got style: %q", edges[2].Style)
+	}
+	if strings.Contains(edges[1].Style, "dashed=1") {
+		t.Errorf("Expected normal edge not to be dashed but got style: %q", edges[1].Style)
+	}
+}
+
+func TestDrawIOFromFlowData(t *testing.T) {
+	buf, err := svg.DrawIOFromFlowData(svg.BigTestFlowData, svg.DefaultLayoutConfig())
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	df := drawioFile{}
+	err = xml.Unmarshal(buf, &df)
+	if err != nil {
+		t.Fatalf("Expected valid XML but got error: %s", err)
+	}
+	if len(df.Cells) < 3 {
+		t.Fatalf("Expected cells but got: %d", len(df.Cells))
+	}
+
+	_, err = svg.DrawIOFromFlowData(svg.Flow{}, svg.DefaultLayoutConfig())
+	if err == nil {
+		t.Error("Expected an error for an empty flow but didn't get one.")
+	}
+}
@@ -0,0 +1,117 @@
+package svg
+
+// errorEnd is the end of an arrow at an outer error port.
+type errorEnd struct {
+	arrow     *svgArrow
+	port      string
+	portText  *svgText
+	dataTexts []*svgText
+}
+
+func (c *LayoutConfig) isErrorPort(port string) bool {
+	name, _ := splitPort(port)
+	for _, ep := range c.ErrorPorts {
+		if name == ep {
+			return true
+		}
+	}
+	return false
+}
+
+// isErrorArrow returns true if the arrow starts at an error port of an
+// operation or ends at an outer error port.
+func (c *LayoutConfig) isErrorArrow(a *Arrow, hasSrcOp, hasDstOp bool) bool {
+	return (hasSrcOp && c.isErrorPort(a.SrcPort)) ||
+		(!hasDstOp && c.isErrorPort(a.DstPort))
+}
+
+// addErrorEnd remembers the end of an arrow at an outer port if it has to be
+// collapsed into an error sink.
+func addErrorEnd(sf *svgFlow, port string, arr *svgArrow, portText *svgText, dataTexts []*svgText) {
+	if !sf.cfg.CollapseErrors || !sf.cfg.isErrorPort(port) {
+		return
+	}
+	sf.errorEnds = append(sf.errorEnds, &errorEnd{
+		arrow:     arr,
+		port:      port,
+		portText:  portText,
+		dataTexts: dataTexts,
+	})
+}
+
+// collapseErrorSinks lets all arrows to the same outer error port end in a
+// single error sink at the right margin.
+// The new maximal X coordinate is returned.
+func collapseErrorSinks(sf *svgFlow, xn int) int {
+	if len(sf.errorEnds) == 0 {
+		return xn
+	}
+	cfg := sf.cfg
+	dropTexts := make(map[*svgText]bool, len(sf.errorEnds))
+	ports := make([]string, 0, 2)
+	sinks := make(map[string][]*errorEnd)
+	for _, ee := range sf.errorEnds {
+		if ee.portText != nil {
+			dropTexts[ee.portText] = true
+		}
+		if _, ok := sinks[ee.port]; !ok {
+			ports = append(ports, ee.port)
+		}
+		sinks[ee.port] = append(sinks[ee.port], ee)
+	}
+	texts := sf.Texts[:0]
+	for _, t := range sf.Texts {
+		if !dropTexts[t] {
+			texts = append(texts, t)
+		}
+	}
+	sf.Texts = texts
+
+	x := contentWidth(sf) + cfg.MinArrowLength
+	xn = x
+	for _, port := range ports {
+		ees := sinks[port]
+		miny, maxy := ees[0].arrow.Y2, ees[0].arrow.Y2
+		for _, ee := range ees {
+			xShift := x - ee.arrow.X2
+			ee.arrow.X2 = x
+			ee.arrow.XTip1 = x - cfg.ArrowTipSize
+			ee.arrow.XTip2 = x - cfg.ArrowTipSize
+			for _, dt := range ee.dataTexts {
+				dt.X += xShift / 2
+			}
+			miny = min(miny, ee.arrow.Y2)
+			maxy = max(maxy, ee.arrow.Y2)
+		}
+		s := cfg.ArrowTipSize
+		sf.PortMarkers = append(sf.PortMarkers, &svgRect{
+			X: x, Y: miny - s,
+			Width: s, Height: maxy - miny + 2*s,
+		})
+		sf.Texts = append(sf.Texts, &svgText{
+			X: x + s + cfg.Padding/2, Y: (miny+maxy)/2 + cfg.descent(),
+			Width:  cfg.textWidth(textLen(port)),
+			Text:   port,
+			onLine: true,
+		})
+		xn = x + s + cfg.Padding/2 + cfg.textWidth(textLen(port))
+		x = xn + cfg.Padding
+	}
+	return xn
+}
+
+// contentWidth returns the maximal X coordinate of all arrows, rectangles
+// and texts.
+func contentWidth(sf *svgFlow) int {
+	xn := 0
+	for _, a := range sf.Arrows {
+		xn = max(xn, max(a.X1, a.X2))
+	}
+	for _, r := range sf.Rects {
+		xn = max(xn, r.X+r.Width)
+	}
+	for _, t := range sf.Texts {
+		xn = max(xn, t.X+t.Width)
+	}
+	return xn
+}
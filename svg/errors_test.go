@@ -0,0 +1,56 @@
+package svg
+
+import (
+	"testing"
+)
+
+func TestCollapseErrorSinks(t *testing.T) {
+	errArrow := func(srcPort string) *Arrow {
+		return &Arrow{DataType: []string{"(err)"}, HasSrcOp: true, SrcPort: srcPort, DstPort: "error"}
+	}
+	f := Flow{Shapes: [][]Shape{
+		{
+			&Arrow{DataType: []string{"(data)"}, DstPort: "in", HasDstOp: true},
+			&Op{Main: &Rect{Text: []string{"short"}}},
+			errArrow("err"),
+		}, {
+			&Arrow{DataType: []string{"(data)"}, HasDstOp: true},
+			&Op{Main: &Rect{Text: []string{"quiteALongName"}}},
+			errArrow("err[2]"),
+		}, {
+			&Arrow{DataType: []string{"(data)"}, HasDstOp: true},
+			&Op{Main: &Rect{Text: []string{"other"}}},
+			&Arrow{DataType: []string{"(data)"}, HasSrcOp: true, SrcPort: "out", DstPort: "out"},
+		},
+	}}
+	cfg := DefaultLayoutConfig()
+	cfg.MarkErrors = true
+	cfg.CollapseErrors = true
+	sf := flowDataToSVGFlow(f, &cfg)
+
+	var errArrows []*svgArrow
+	for _, a := range sf.Arrows {
+		if a.IsError {
+			errArrows = append(errArrows, a)
+		}
+	}
+	if len(errArrows) != 2 {
+		t.Fatalf("Expected 2 error arrows but got: %d", len(errArrows))
+	}
+	if errArrows[0].X2 != errArrows[1].X2 {
+		t.Errorf("Expected error arrows to end at the same sink but got: %d and %d",
+			errArrows[0].X2, errArrows[1].X2)
+	}
+	if len(sf.PortMarkers) != 1 {
+		t.Errorf("Expected exactly 1 error sink but got: %d", len(sf.PortMarkers))
+	}
+	labels := 0
+	for _, st := range sf.Texts {
+		if st.Text == "error" {
+			labels++
+		}
+	}
+	if labels != 1 {
+		t.Errorf("Expected exactly 1 error label but got: %d", labels)
+	}
+}
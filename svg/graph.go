@@ -0,0 +1,117 @@
+package svg
+
+import (
+	"fmt"
+)
+
+// Node is a single node of a Graph.
+// Exactly one of its fields Op, Rect or Port has to be set:
+// - Op: a normal operation including possible plugins.
+// - Rect: a back reference to an operation (breaking a circle).
+// - Port: an outer port at the start or end of the flow.
+type Node struct {
+	ID   string
+	Op   *Op
+	Rect *Rect
+	Port string
+}
+
+// Edge connects two nodes of a Graph.
+// The ports of the Arrow are only displayed at ends that are connected to an
+// operation.
+// The fields HasSrcOp and HasDstOp of the Arrow are ignored since they are
+// given by the nodes.
+type Edge struct {
+	From  string
+	To    string
+	Arrow *Arrow
+}
+
+// Graph contains data for a whole flow as nodes and the edges between them.
+// In contrast to Flow the position of the shapes isn't given at all but is
+// computed by a layered (Sugiyama style) layout algorithm.
+// The graph must not contain any circles.
+type Graph struct {
+	Nodes []*Node
+	Edges []*Edge
+}
+
+// FromGraphData creates a SVG diagram from graph data.
+// If the graph data isn't valid or the SVG diagram can't be created with its
+// template, an error is returned.
+func FromGraphData(g Graph) ([]byte, error) {
+	return FromGraphDataWithConfig(g, DefaultLayoutConfig())
+}
+
+// FromGraphDataWithConfig creates a SVG diagram from graph data using the
+// given layout configuration.
+// If the graph data or configuration isn't valid or the SVG diagram can't be
+// created with its template, an error is returned.
+func FromGraphDataWithConfig(g Graph, cfg LayoutConfig) ([]byte, error) {
+	err := validateLayoutConfig(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	err = validateGraphData(g)
+	if err != nil {
+		return nil, err
+	}
+
+	lg, err := newLayeredGraph(g, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sf := layeredGraphToSVGFlow(lg)
+
+	return svgFlowToBytes(sf)
+}
+
+func validateGraphData(g Graph) error {
+	if len(g.Nodes) <= 0 {
+		return fmt.Errorf("No nodes found")
+	}
+	ids := make(map[string]bool, len(g.Nodes))
+	for i, n := range g.Nodes {
+		if n == nil {
+			return fmt.Errorf("node at index %d is nil", i)
+		}
+		if ids[n.ID] {
+			return fmt.Errorf("node ID '%s' at index %d isn't unique", n.ID, i)
+		}
+		ids[n.ID] = true
+
+		kinds := 0
+		if n.Op != nil {
+			kinds++
+		}
+		if n.Rect != nil {
+			kinds++
+		}
+		if n.Port != "" {
+			kinds++
+		}
+		if kinds != 1 {
+			return fmt.Errorf(
+				"node '%s' at index %d must be exactly one of operation, back reference or port",
+				n.ID, i)
+		}
+		if n.Op != nil && n.Op.Subflow != nil {
+			if err := Validate(*n.Op.Subflow); err != nil {
+				return fmt.Errorf("subflow of node '%s' at index %d: %s", n.ID, i, err)
+			}
+		}
+	}
+	for i, e := range g.Edges {
+		if e == nil || e.Arrow == nil {
+			return fmt.Errorf("edge at index %d has got no arrow", i)
+		}
+		if !ids[e.From] {
+			return fmt.Errorf("unknown source node '%s' of edge at index %d", e.From, i)
+		}
+		if !ids[e.To] {
+			return fmt.Errorf("unknown destination node '%s' of edge at index %d", e.To, i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package svg
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Kinds of the shapes of a flow in JSON.
+const (
+	kindArrow = "arrow"
+	kindOp    = "op"
+	kindRect  = "rect"
+	kindSplit = "split"
+	kindMerge = "merge"
+)
+
+type jsonArrow struct {
+	Kind string `json:"kind"`
+	*Arrow
+}
+
+type jsonOp struct {
+	Kind string `json:"kind"`
+	*Op
+}
+
+type jsonRect struct {
+	Kind string `json:"kind"`
+	*Rect
+}
+
+type jsonMerge struct {
+	Kind string `json:"kind"`
+	*Merge
+}
+
+type jsonShapes struct {
+	Kind   string              `json:"kind,omitempty"`
+	Shapes [][]json.RawMessage `json:"shapes"`
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// Every shape gets a 'kind' field ('arrow', 'op', 'rect', 'split' or
+// 'merge') so it can be decoded again.
+func (f Flow) MarshalJSON() ([]byte, error) {
+	return marshalShapes(f.Shapes, "")
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (f *Flow) UnmarshalJSON(buf []byte) error {
+	shapes, err := unmarshalShapes(buf)
+	if err != nil {
+		return err
+	}
+	f.Shapes = shapes
+	return nil
+}
+
+func marshalShapes(shapes [][]Shape, kind string) ([]byte, error) {
+	js := jsonShapes{Kind: kind, Shapes: make([][]json.RawMessage, len(shapes))}
+	for i, row := range shapes {
+		js.Shapes[i] = make([]json.RawMessage, len(row))
+		for j, ishape := range row {
+			var buf []byte
+			var err error
+			switch shape := ishape.(type) {
+			case *Arrow:
+				buf, err = json.Marshal(jsonArrow{Kind: kindArrow, Arrow: shape})
+			case *Op:
+				buf, err = json.Marshal(jsonOp{Kind: kindOp, Op: shape})
+			case *Rect:
+				buf, err = json.Marshal(jsonRect{Kind: kindRect, Rect: shape})
+			case *Split:
+				buf, err = marshalShapes(shape.Shapes, kindSplit)
+			case *Merge:
+				buf, err = json.Marshal(jsonMerge{Kind: kindMerge, Merge: shape})
+			default:
+				err = fmt.Errorf(
+					"unsupported shape type %T at row index %d and column index %d",
+					ishape, i, j)
+			}
+			if err != nil {
+				return nil, err
+			}
+			js.Shapes[i][j] = buf
+		}
+	}
+	return json.Marshal(js)
+}
+
+func unmarshalShapes(buf []byte) ([][]Shape, error) {
+	js := jsonShapes{}
+	err := json.Unmarshal(buf, &js)
+	if err != nil {
+		return nil, err
+	}
+	shapes := make([][]Shape, len(js.Shapes))
+	for i, jRow := range js.Shapes {
+		shapes[i] = make([]Shape, len(jRow))
+		for j, jShape := range jRow {
+			kind := struct {
+				Kind string `json:"kind"`
+			}{}
+			err = json.Unmarshal(jShape, &kind)
+			if err != nil {
+				return nil, err
+			}
+			var shape Shape
+			switch kind.Kind {
+			case kindArrow:
+				shape = &Arrow{}
+			case kindOp:
+				shape = &Op{}
+			case kindRect:
+				shape = &Rect{}
+			case kindMerge:
+				shape = &Merge{}
+			case kindSplit:
+				split := &Split{}
+				split.Shapes, err = unmarshalShapes(jShape)
+				if err != nil {
+					return nil, err
+				}
+				shapes[i][j] = split
+				continue
+			default:
+				return nil, fmt.Errorf(
+					"unsupported shape kind '%s' at row index %d and column index %d",
+					kind.Kind, i, j)
+			}
+			err = json.Unmarshal(jShape, shape)
+			if err != nil {
+				return nil, err
+			}
+			shapes[i][j] = shape
+		}
+	}
+	return shapes, nil
+}
@@ -0,0 +1,70 @@
+package svg_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/flowdev/gflowparser/svg"
+)
+
+func TestFlowJSONRoundTrip(t *testing.T) {
+	buf, err := json.Marshal(svg.BigTestFlowData)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	got := svg.Flow{}
+	err = json.Unmarshal(buf, &got)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	// empty and nil slices can't be distinguished so we compare the JSON
+	buf2, err := json.Marshal(got)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if string(buf2) != string(buf) {
+		t.Errorf("Expected JSON:\n%s\nbut got:\n%s", buf, buf2)
+	}
+}
+
+func TestFlowFromJSON(t *testing.T) {
+	input := `{"shapes": [[
+		{"kind": "arrow", "srcPort": "in", "hasDstOp": true},
+		{"kind": "op", "main": {"text": ["a"]}},
+		{"kind": "split", "shapes": [
+			[{"kind": "arrow", "hasSrcOp": true, "dstPort": "out"}],
+			[{"kind": "arrow", "hasSrcOp": true, "srcPort": "err", "dstPort": "err"}]
+		]}
+	]]}`
+	expected := svg.Flow{Shapes: [][]svg.Shape{{
+		&svg.Arrow{SrcPort: "in", HasDstOp: true},
+		&svg.Op{Main: &svg.Rect{Text: []string{"a"}}},
+		&svg.Split{Shapes: [][]svg.Shape{
+			{&svg.Arrow{HasSrcOp: true, DstPort: "out"}},
+			{&svg.Arrow{HasSrcOp: true, SrcPort: "err", DstPort: "err"}},
+		}},
+	}}}
+	got := svg.Flow{}
+	err := json.Unmarshal([]byte(input), &got)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected flow %#v but got: %#v", expected, got)
+	}
+
+	for _, input := range []string{
+		`{"shapes": [[{"kind": "circle"}]]}`,
+		`{"shapes": [[{"kind": "split", "shapes": [[{"kind": "op", "main": 1}]]}]]}`,
+	} {
+		err = json.Unmarshal([]byte(input), &got)
+		if err == nil {
+			t.Errorf("Expected an error for input %s but didn't get one.", input)
+		}
+	}
+	_, err = json.Marshal(svg.Flow{Shapes: [][]svg.Shape{{nil}}})
+	if err == nil {
+		t.Error("Expected an error for a missing shape but didn't get one.")
+	}
+}
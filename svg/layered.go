@@ -0,0 +1,628 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Constants for the layered layout.
+const (
+	crossingSweeps  = 8 // sweeps for minimizing edge crossings
+	placementSweeps = 9 // sweeps for assigning vertical coordinates (odd: last one downwards)
+)
+
+// lNode is a node of the layered graph.
+// Dummy nodes (node == nil) are inserted for edges spanning multiple layers.
+type lNode struct {
+	node          *Node
+	layer         int
+	pos           int // position in its layer
+	seq           int // sequence for the initial order
+	x, y          int
+	width, height int
+	lanesH        int // height needed by all arrows at one side
+	ins, outs     []*lSeg
+}
+
+func (n *lNode) isOp() bool {
+	return n.node != nil && n.node.Op != nil
+}
+
+// lSeg is a segment of an edge between two adjacent layers.
+type lSeg struct {
+	from, to       *lNode
+	edge           *lEdge
+	srcOff, dstOff int // vertical offsets of the arrow from the node tops
+	jogX           int // X of the vertical part of the segment
+}
+
+func (s *lSeg) isFirst() bool {
+	return s.edge.segs[0] == s
+}
+func (s *lSeg) isLast() bool {
+	return s.edge.segs[len(s.edge.segs)-1] == s
+}
+
+type lEdge struct {
+	edge *Edge
+	segs []*lSeg
+}
+
+type layeredGraph struct {
+	cfg    *LayoutConfig
+	nodes  []*lNode
+	edges  []*lEdge
+	layers [][]*lNode
+	layerW []int
+}
+
+// newLayeredGraph lays out the graph in the following steps:
+// 1. Assign every node to a layer (longest path).
+// 2. Split edges spanning multiple layers by inserting dummy nodes.
+// 3. Minimize edge crossings (barycenter heuristic).
+// 4. Compute the sizes of all nodes.
+// 5. Assign vertical coordinates (aligning connected arrows).
+// 6. Assign horizontal coordinates (making room for the arrow texts).
+func newLayeredGraph(g Graph, cfg *LayoutConfig) (*layeredGraph, error) {
+	lg := &layeredGraph{
+		cfg:   cfg,
+		nodes: make([]*lNode, 0, len(g.Nodes)*2),
+		edges: make([]*lEdge, len(g.Edges)),
+	}
+	ids := make(map[string]*lNode, len(g.Nodes))
+	for _, n := range g.Nodes {
+		ln := &lNode{node: n, seq: -1}
+		ids[n.ID] = ln
+		lg.nodes = append(lg.nodes, ln)
+	}
+	for i, e := range g.Edges {
+		lg.edges[i] = &lEdge{edge: e}
+	}
+
+	err := lg.assignLayers(ids)
+	if err != nil {
+		return nil, err
+	}
+	lg.addSegments(ids)
+	lg.orderNodes()
+	lg.minimizeCrossings()
+	lg.computeSizes()
+	lg.assignY()
+	lg.assignX()
+	return lg, nil
+}
+
+func (lg *layeredGraph) assignLayers(ids map[string]*lNode) error {
+	succs := make(map[*lNode][]*lNode, len(lg.nodes))
+	indeg := make(map[*lNode]int, len(lg.nodes))
+	for _, le := range lg.edges {
+		from, to := ids[le.edge.From], ids[le.edge.To]
+		succs[from] = append(succs[from], to)
+		indeg[to]++
+	}
+
+	queue := make([]*lNode, 0, len(lg.nodes))
+	for _, n := range lg.nodes {
+		if indeg[n] == 0 {
+			queue = append(queue, n)
+		}
+	}
+	order := make([]*lNode, 0, len(lg.nodes))
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		order = append(order, n)
+		for _, s := range succs[n] {
+			s.layer = max(s.layer, n.layer+1)
+			indeg[s]--
+			if indeg[s] == 0 {
+				queue = append(queue, s)
+			}
+		}
+	}
+	if len(order) < len(lg.nodes) {
+		return fmt.Errorf("the graph contains a circle")
+	}
+
+	// pull sources towards their successors
+	for i := len(order) - 1; i >= 0; i-- {
+		n := order[i]
+		if indeg[n] != 0 || len(succs[n]) == 0 {
+			continue
+		}
+		l := math.MaxInt32
+		for _, s := range succs[n] {
+			l = min(l, s.layer)
+		}
+		n.layer = l - 1
+	}
+
+	// error sinks are collapsed at the right margin
+	if lg.cfg.CollapseErrors {
+		last := 0
+		for _, n := range lg.nodes {
+			last = max(last, n.layer)
+		}
+		for _, n := range lg.nodes {
+			if n.node.Port != "" && indeg[n] == 0 && len(succs[n]) == 0 &&
+				lg.cfg.isErrorPort(n.node.Port) {
+				n.layer = last
+			}
+		}
+	}
+	return nil
+}
+
+func (lg *layeredGraph) addSegments(ids map[string]*lNode) {
+	for _, le := range lg.edges {
+		from, to := ids[le.edge.From], ids[le.edge.To]
+		for prev := from; prev != to; {
+			next := to
+			if to.layer-prev.layer > 1 {
+				next = &lNode{layer: prev.layer + 1, seq: -1}
+				lg.nodes = append(lg.nodes, next)
+			}
+			s := &lSeg{from: prev, to: next, edge: le}
+			prev.outs = append(prev.outs, s)
+			next.ins = append(next.ins, s)
+			le.segs = append(le.segs, s)
+			prev = next
+		}
+	}
+}
+
+// orderNodes creates the layers in the order of a depth first search.
+// So nodes that are near to each other in the flow are near in the diagram.
+func (lg *layeredGraph) orderNodes() {
+	seq := 0
+	var visit func(n *lNode)
+	visit = func(n *lNode) {
+		if n.seq >= 0 {
+			return
+		}
+		n.seq = seq
+		seq++
+		for _, s := range n.outs {
+			visit(s.to)
+		}
+	}
+	for _, n := range lg.nodes {
+		if len(n.ins) == 0 {
+			visit(n)
+		}
+	}
+
+	nLayers := 0
+	for _, n := range lg.nodes {
+		nLayers = max(nLayers, n.layer+1)
+	}
+	lg.layers = make([][]*lNode, nLayers)
+	for _, n := range lg.nodes {
+		lg.layers[n.layer] = append(lg.layers[n.layer], n)
+	}
+	for _, layer := range lg.layers {
+		sort.SliceStable(layer, func(i, j int) bool {
+			return layer[i].seq < layer[j].seq
+		})
+		updatePositions(layer)
+	}
+}
+
+func (lg *layeredGraph) minimizeCrossings() {
+	best := lg.crossings()
+	bestLayers := lg.copyLayers()
+	for i := 0; i < crossingSweeps && best > 0; i++ {
+		if i%2 == 0 {
+			for l := 1; l < len(lg.layers); l++ {
+				sortByBarycenter(lg.layers[l], true)
+			}
+		} else {
+			for l := len(lg.layers) - 2; l >= 0; l-- {
+				sortByBarycenter(lg.layers[l], false)
+			}
+		}
+		if c := lg.crossings(); c < best {
+			best = c
+			bestLayers = lg.copyLayers()
+		}
+	}
+	lg.layers = bestLayers
+	for _, layer := range lg.layers {
+		updatePositions(layer)
+		for _, n := range layer {
+			sort.SliceStable(n.outs, func(i, j int) bool {
+				return n.outs[i].to.pos < n.outs[j].to.pos
+			})
+			sort.SliceStable(n.ins, func(i, j int) bool {
+				return n.ins[i].from.pos < n.ins[j].from.pos
+			})
+			if lg.cfg.PortMarkers && n.isOp() {
+				sortByPort(n.outs, func(s *lSeg) string { return s.edge.edge.Arrow.SrcPort })
+				sortByPort(n.ins, func(s *lSeg) string { return s.edge.edge.Arrow.DstPort })
+			}
+		}
+	}
+}
+
+func sortByBarycenter(layer []*lNode, usePreds bool) {
+	keys := make(map[*lNode]float64, len(layer))
+	for _, n := range layer {
+		segs := n.outs
+		if usePreds {
+			segs = n.ins
+		}
+		if len(segs) == 0 {
+			keys[n] = float64(n.pos)
+			continue
+		}
+		sum := 0
+		for _, s := range segs {
+			if usePreds {
+				sum += s.from.pos
+			} else {
+				sum += s.to.pos
+			}
+		}
+		keys[n] = float64(sum) / float64(len(segs))
+	}
+	sort.SliceStable(layer, func(i, j int) bool {
+		return keys[layer[i]] < keys[layer[j]]
+	})
+	updatePositions(layer)
+}
+
+func updatePositions(layer []*lNode) {
+	for i, n := range layer {
+		n.pos = i
+	}
+}
+
+func (lg *layeredGraph) copyLayers() [][]*lNode {
+	layers := make([][]*lNode, len(lg.layers))
+	for i, layer := range lg.layers {
+		layers[i] = make([]*lNode, len(layer))
+		copy(layers[i], layer)
+	}
+	return layers
+}
+
+// crossings counts the edge crossings between all adjacent layers.
+func (lg *layeredGraph) crossings() int {
+	c := 0
+	for _, layer := range lg.layers {
+		segs := make([]*lSeg, 0, 32)
+		for _, n := range layer {
+			segs = append(segs, n.outs...)
+		}
+		for i, a := range segs {
+			for _, b := range segs[i+1:] {
+				if (a.from.pos-b.from.pos)*(a.to.pos-b.to.pos) < 0 {
+					c++
+				}
+			}
+		}
+	}
+	return c
+}
+
+func (lg *layeredGraph) computeSizes() {
+	cfg := lg.cfg
+	for _, n := range lg.nodes {
+		outsH := 0
+		for _, s := range n.outs {
+			h, off := outLane(s, cfg)
+			s.srcOff = outsH + off
+			outsH += h
+		}
+		insH := 0
+		for _, s := range n.ins {
+			h, off := inLane(s, cfg)
+			s.dstOff = insH + off
+			insH += h
+		}
+		n.lanesH = max(outsH, insH)
+
+		switch {
+		case n.node == nil:
+			n.width, n.height = 0, n.lanesH
+		case n.node.Op != nil:
+			sf, _, _ := initSVGData(cfg)
+			_, _, _, n.width, n.height = opDataToSVG(n.node.Op, sf, 0, 0, n.lanesH)
+		case n.node.Rect != nil:
+			n.width = cfg.Padding/2 + cfg.textWidth(textLen(backRefText(n.node.Rect)))
+			n.height = n.lanesH
+		case len(n.ins) == 0: // port at the start
+			n.width, n.height = cfg.textWidth(textLen(n.node.Port)), n.lanesH
+		default: // port at the end
+			n.width = cfg.Padding/2 + cfg.textWidth(textLen(n.node.Port))
+			n.height = n.lanesH
+		}
+	}
+}
+
+// outLane returns the height needed by the arrow of the segment at its
+// source node and the offset of the arrow itself.
+func outLane(s *lSeg, cfg *LayoutConfig) (h, off int) {
+	if s.from.node == nil {
+		return cfg.LineHeight, cfg.LineHeight / 2
+	}
+	n := max(len(cfg.fitTexts(s.edge.edge.Arrow.DataType, dataBreaks)), 1)
+	h = 2*cfg.LineHeight + (n-1)*cfg.DataLineHeight
+	return h, h - cfg.LineHeight
+}
+
+// inLane returns the height needed by the arrow of the segment at its
+// destination node and the offset of the arrow itself.
+func inLane(s *lSeg, cfg *LayoutConfig) (h, off int) {
+	if s.to.node == nil {
+		return cfg.LineHeight, cfg.LineHeight / 2
+	}
+	return 2 * cfg.LineHeight, cfg.LineHeight
+}
+
+func (lg *layeredGraph) assignY() {
+	gap := lg.cfg.CharWidth
+	for _, layer := range lg.layers {
+		y := 1
+		for _, n := range layer {
+			n.y = y
+			y += n.height + gap
+		}
+	}
+	for i := 0; i < placementSweeps; i++ {
+		if i%2 == 0 {
+			for l := 1; l < len(lg.layers); l++ {
+				placeLayer(lg.layers[l], true, gap)
+			}
+		} else {
+			for l := len(lg.layers) - 2; l >= 0; l-- {
+				placeLayer(lg.layers[l], false, gap)
+			}
+		}
+	}
+}
+
+// placeLayer moves the nodes of a layer as near as possible to the positions
+// that straighten their arrows without changing their order or letting them
+// overlap.
+// This is done by isotonic regression (pool adjacent violators algorithm).
+func placeLayer(layer []*lNode, usePreds bool, gap int) {
+	type block struct {
+		sum float64
+		cnt int
+	}
+	blocks := make([]block, 0, len(layer))
+	offs := make([]int, len(layer))
+	off := 0
+	for i, n := range layer {
+		offs[i] = off
+		off += n.height + gap
+
+		blocks = append(blocks, block{sum: float64(desiredY(n, usePreds) - offs[i]), cnt: 1})
+		for k := len(blocks) - 1; k > 0 &&
+			blocks[k-1].sum/float64(blocks[k-1].cnt) > blocks[k].sum/float64(blocks[k].cnt); k-- {
+
+			blocks[k-1].sum += blocks[k].sum
+			blocks[k-1].cnt += blocks[k].cnt
+			blocks = blocks[:k]
+		}
+	}
+	i := 0
+	for _, b := range blocks {
+		y := max(int(math.Round(b.sum/float64(b.cnt))), 1)
+		for k := 0; k < b.cnt; k++ {
+			layer[i].y = y + offs[i]
+			i++
+		}
+	}
+}
+
+func desiredY(n *lNode, usePreds bool) int {
+	sum, cnt := 0, 0
+	if usePreds {
+		for _, s := range n.ins {
+			sum += s.from.y + s.srcOff - s.dstOff
+			cnt++
+		}
+	} else {
+		for _, s := range n.outs {
+			sum += s.to.y + s.dstOff - s.srcOff
+			cnt++
+		}
+	}
+	if cnt == 0 {
+		return n.y
+	}
+	return int(math.Round(float64(sum) / float64(cnt)))
+}
+
+func (lg *layeredGraph) assignX() {
+	cfg := lg.cfg
+	jogGap := cfg.ArrowTipSize
+	lg.layerW = make([]int, len(lg.layers))
+	x := 2
+	for l, layer := range lg.layers {
+		labelW, portW := 0, 0
+		jogs := make([]*lSeg, 0, 16)
+		for _, n := range layer {
+			n.x = x
+			lg.layerW[l] = max(lg.layerW[l], n.width)
+			for _, s := range n.outs {
+				if s.isFirst() {
+					labelW = max(labelW, srcLabelWidth(s, cfg))
+				}
+				if s.isLast() {
+					portW = max(portW, dstPortWidth(s, cfg))
+				}
+				if n.y+s.srcOff != s.to.y+s.dstOff {
+					jogs = append(jogs, s)
+				}
+			}
+		}
+		for n, s := range jogs {
+			s.jogX = x + lg.layerW[l] + labelW + n*jogGap
+		}
+		x += lg.layerW[l] + labelW + len(jogs)*jogGap + portW + cfg.tipSpace()
+	}
+}
+
+// srcLabelWidth returns the width needed for the data types and source port.
+func srcLabelWidth(s *lSeg, cfg *LayoutConfig) int {
+	a := s.edge.edge.Arrow
+	portLen := 0
+	if s.from.isOp() {
+		portLen = textLen(a.SrcPort)
+	}
+	dataLen := maxLineLen(cfg.fitTexts(a.DataType, dataBreaks))
+	return cfg.textWidth(max(portLen, dataLen)) + cfg.MinArrowLength + cfg.Padding
+}
+
+// dstPortWidth returns the width needed for the destination port.
+func dstPortWidth(s *lSeg, cfg *LayoutConfig) int {
+	a := s.edge.edge.Arrow
+	if !s.to.isOp() || a.DstPort == "" {
+		return 0
+	}
+	return cfg.textWidth(textLen(a.DstPort)) + cfg.tipSpace()
+}
+
+func layeredGraphToSVGFlow(lg *layeredGraph) *svgFlow {
+	sf, _, _ := initSVGData(lg.cfg)
+	xn, yn := 0, 0
+	for _, n := range lg.nodes {
+		if n.isOp() {
+			opDataToSVG(n.node.Op, sf, n.x, n.y, n.y+n.lanesH)
+		}
+		xn = max(xn, n.x+n.width)
+		yn = max(yn, n.y+n.height)
+	}
+	for _, le := range lg.edges {
+		xn = max(xn, edgeToSVG(le, lg, sf))
+	}
+	xn = collapseErrorSinks(sf, xn)
+	addPortMarkers(sf)
+	return adjustDimensions(sf, xn, yn)
+}
+
+func edgeToSVG(le *lEdge, lg *layeredGraph, sf *svgFlow) (xn int) {
+	a := le.edge.Arrow
+	cfg := lg.cfg
+	first := le.segs[0]
+	src := first.from
+	x0, y0 := src.x+src.width, src.y+first.srcOff
+
+	pts := []svgPoint{{X: x0, Y: y0}}
+	y := y0
+	for _, s := range le.segs {
+		yt := s.to.y + s.dstOff
+		if yt != y {
+			pts = append(pts, svgPoint{X: s.jogX, Y: y}, svgPoint{X: s.jogX, Y: yt})
+			y = yt
+		}
+		pts = append(pts, svgPoint{X: s.to.x, Y: y})
+		if s.to.node == nil { // pass the layer of the dummy node
+			pts = append(pts, svgPoint{X: s.to.x + lg.layerW[s.to.layer], Y: y})
+		}
+	}
+	pts = simplifyPoints(pts)
+
+	dst := le.segs[len(le.segs)-1].to
+	isError := cfg.MarkErrors && cfg.isErrorArrow(a, src.isOp(), dst.isOp())
+	n := len(pts)
+	arr := newSVGArrow(cfg, pts[n-2].X, pts[n-1].X, pts[n-1].Y)
+	arr.IsError = isError
+	sf.Arrows = append(sf.Arrows, arr)
+	start := &svgPoint{X: arr.X1, Y: arr.Y1}
+	if n > 2 {
+		poly := &svgPolyline{Points: pts[:n-1], IsError: isError}
+		sf.Polylines = append(sf.Polylines, poly)
+		arr.path = poly
+		start = &poly.Points[0]
+	}
+	xe, ye := arr.X2, arr.Y2
+
+	// texts at the start of the edge
+	if src.isOp() {
+		if a.SrcPort != "" {
+			st := &svgText{
+				X: x0 + cfg.Padding, Y: y0 + cfg.portTextOffset(),
+				Width: cfg.textWidth(textLen(a.SrcPort)),
+				Text:  a.SrcPort,
+				under: true,
+			}
+			sf.Texts = append(sf.Texts, st)
+			addPortEnd(sf, &start.X, &start.Y, true, a.SrcPort, st, nil)
+		}
+	} else if src.node.Port != "" {
+		sf.Texts = append(sf.Texts, &svgText{
+			X: src.x + 1, Y: y0 + cfg.descent(),
+			Width:  cfg.textWidth(textLen(src.node.Port)) - 2,
+			Text:   src.node.Port,
+			onLine: true,
+		})
+	}
+	if dataLines := cfg.fitTexts(a.DataType, dataBreaks); len(dataLines) != 0 {
+		labelW := srcLabelWidth(first, cfg)
+		dataX := x0 + (labelW-cfg.textWidth(maxLineLen(dataLines)))/2
+		for i, line := range dataLines {
+			sf.Texts = append(sf.Texts, &svgText{
+				X: dataX, Y: y0 - cfg.FontSize/2 - (len(dataLines)-1-i)*cfg.DataLineHeight,
+				Width: cfg.textWidth(textLen(line.text)),
+				Text:  line.text,
+				Title: line.title,
+			})
+		}
+	}
+
+	// texts at the end of the edge
+	xn = xe
+	switch {
+	case dst.isOp():
+		if a.DstPort != "" {
+			st := &svgText{
+				X: xe - cfg.textWidth(textLen(a.DstPort)) - cfg.tipSpace(), Y: ye + cfg.portTextOffset(),
+				Width: cfg.textWidth(textLen(a.DstPort)),
+				Text:  a.DstPort,
+				under: true,
+			}
+			sf.Texts = append(sf.Texts, st)
+			addPortEnd(sf, &arr.X2, &arr.Y2, false, a.DstPort, st, nil)
+		}
+	case dst.node.Rect != nil:
+		txt := backRefText(dst.node.Rect)
+		sf.Texts = append(sf.Texts, &svgText{
+			X: xe + cfg.Padding/2, Y: ye + cfg.descent(),
+			Width:  cfg.textWidth(textLen(txt)),
+			Text:   txt,
+			onLine: true,
+		})
+		xn = xe + cfg.Padding/2 + cfg.textWidth(textLen(txt))
+	default:
+		st := &svgText{
+			X: xe + cfg.Padding/2, Y: ye + cfg.descent(),
+			Width:  cfg.textWidth(textLen(dst.node.Port)) - 2,
+			Text:   dst.node.Port,
+			onLine: true,
+		}
+		sf.Texts = append(sf.Texts, st)
+		addErrorEnd(sf, dst.node.Port, arr, st, nil)
+		xn = xe + cfg.Padding/2 + cfg.textWidth(textLen(dst.node.Port))
+	}
+	return xn
+}
+
+// simplifyPoints removes points that are in the middle of a straight line.
+func simplifyPoints(pts []svgPoint) []svgPoint {
+	result := make([]svgPoint, 0, len(pts))
+	for i, p := range pts {
+		if i > 0 && i < len(pts)-1 {
+			prev, next := result[len(result)-1], pts[i+1]
+			if (prev.X == p.X && p.X == next.X) || (prev.Y == p.Y && p.Y == next.Y) {
+				continue
+			}
+		}
+		result = append(result, p)
+	}
+	return result
+}
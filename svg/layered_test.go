@@ -0,0 +1,128 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewLayeredGraph(t *testing.T) {
+	op := func(id string) *Node {
+		return &Node{ID: id, Op: &Op{Main: &Rect{Text: []string{id}}}}
+	}
+	edge := func(from, to string) *Edge {
+		return &Edge{From: from, To: to, Arrow: &Arrow{DataType: []string{"(data)"}}}
+	}
+	specs := []struct {
+		name           string
+		given          Graph
+		expectedLayers map[string]int
+		expectedError  bool
+	}{
+		{
+			name: "chain",
+			given: Graph{
+				Nodes: []*Node{{ID: "in", Port: "in"}, op("a"), op("b"), {ID: "out", Port: "out"}},
+				Edges: []*Edge{edge("in", "a"), edge("a", "b"), edge("b", "out")},
+			},
+			expectedLayers: map[string]int{"in": 0, "a": 1, "b": 2, "out": 3},
+		}, {
+			name: "pulled source",
+			given: Graph{
+				Nodes: []*Node{{ID: "in", Port: "in"}, op("a"), op("b"), {ID: "in2", Port: "in2"}},
+				Edges: []*Edge{edge("in", "a"), edge("a", "b"), edge("in2", "b")},
+			},
+			expectedLayers: map[string]int{"in": 0, "a": 1, "b": 2, "in2": 1},
+		}, {
+			name: "circle",
+			given: Graph{
+				Nodes: []*Node{op("a"), op("b")},
+				Edges: []*Edge{edge("a", "b"), edge("b", "a")},
+			},
+			expectedError: true,
+		},
+	}
+	cfg := DefaultLayoutConfig()
+	for _, spec := range specs {
+		t.Run(spec.name, func(t *testing.T) {
+			lg, err := newLayeredGraph(spec.given, &cfg)
+			if spec.expectedError {
+				if err == nil {
+					t.Error("Expected an error but didn't get one.")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			for _, n := range lg.nodes {
+				if n.node == nil {
+					continue
+				}
+				if l := spec.expectedLayers[n.node.ID]; l != n.layer {
+					t.Errorf("Expected node '%s' in layer %d but got: %d", n.node.ID, l, n.layer)
+				}
+			}
+			if c := lg.crossings(); c != 0 {
+				t.Errorf("Expected no crossings but got: %d", c)
+			}
+		})
+	}
+}
+
+func TestMinimizeCrossings(t *testing.T) {
+	g := Graph{
+		Nodes: []*Node{
+			{ID: "a", Op: &Op{Main: &Rect{Text: []string{"a"}}}},
+			{ID: "b", Op: &Op{Main: &Rect{Text: []string{"b"}}}},
+			{ID: "out1", Port: "out1"},
+			{ID: "out2", Port: "out2"},
+		},
+		Edges: []*Edge{
+			{From: "a", To: "out2", Arrow: &Arrow{}},
+			{From: "b", To: "out1", Arrow: &Arrow{}},
+			{From: "a", To: "out1", Arrow: &Arrow{}},
+		},
+	}
+	cfg := DefaultLayoutConfig()
+	lg, err := newLayeredGraph(g, &cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if c := lg.crossings(); c != 0 {
+		t.Errorf("Expected no crossings but got: %d", c)
+	}
+}
+
+func TestFromGraphData(t *testing.T) {
+	g := Graph{
+		Nodes: []*Node{
+			{ID: "in", Port: "in"},
+			{ID: "a", Op: &Op{Main: &Rect{Text: []string{"a"}}}},
+			{ID: "b", Op: &Op{Main: &Rect{Text: []string{"b"}}}},
+			{ID: "back", Rect: &Rect{Text: []string{"a"}}},
+		},
+		Edges: []*Edge{
+			{From: "in", To: "a", Arrow: &Arrow{DataType: []string{"(data)"}}},
+			{From: "a", To: "b", Arrow: &Arrow{SrcPort: "out", DstPort: "in"}},
+			{From: "b", To: "back", Arrow: &Arrow{DataType: []string{"(data)"}}},
+		},
+	}
+	buf, err := FromGraphData(g)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	got := string(buf)
+	for _, exp := range []string{">in</text>", ">(data)</text>", ">out</text>", ">... back to: a</text>"} {
+		if !strings.Contains(got, exp) {
+			t.Errorf("Expected SVG to contain '%s' but it doesn't:\n%s", exp, got)
+		}
+	}
+	if strings.Contains(got, "<polyline") {
+		t.Errorf("Expected only straight arrows but got:\n%s", got)
+	}
+
+	_, err = FromGraphData(Graph{Nodes: []*Node{{ID: "a"}}})
+	if err == nil {
+		t.Error("Expected an error for an empty node but didn't get one.")
+	}
+}
@@ -20,19 +20,19 @@ func mergeDataToSVG(m *Merge, sf *svgFlow, mod *moveData, x0, y0 int,
 		md.moveData = append(md.moveData, mod)
 	}
 	if md.curSize >= m.Size { // merge is comleted!
-		moveXTo(md, md.x0)
+		moveXTo(md, md.x0, sf.cfg)
 		return md
 	}
 	return nil
 }
 
-func moveXTo(med *myMergeData, newX int) {
+func moveXTo(med *myMergeData, newX int, cfg *LayoutConfig) {
 	for _, mod := range med.moveData {
 		xShift := newX - mod.arrow.X2
 
 		mod.arrow.X2 = newX
-		mod.arrow.XTip1 = newX - 8
-		mod.arrow.XTip2 = newX - 8
+		mod.arrow.XTip1 = newX - cfg.ArrowTipSize
+		mod.arrow.XTip2 = newX - cfg.ArrowTipSize
 
 		if mod.dstPortText != nil {
 			mod.dstPortText.X += xShift
@@ -0,0 +1,117 @@
+package svg
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var mermaidEscaper = strings.NewReplacer(
+	`"`, "#quot;",
+	"<", "#lt;",
+	">", "#gt;",
+)
+
+// MermaidFromGraphData creates a Mermaid flowchart from graph data.
+// Operations become nodes with their name, type and plugins, outer ports
+// become terminal nodes and arrows are labelled with their ports and data
+// types.
+// In contrast to FromGraphData the graph may contain circles.
+// If the graph data isn't valid, an error is returned.
+func MermaidFromGraphData(g Graph) ([]byte, error) {
+	err := validateGraphData(g)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.Buffer{}
+	buf.WriteString("flowchart LR\n")
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		var id, shape string
+		switch {
+		case n.Op != nil:
+			id, shape = "c_"+safeID(n.ID), `("`+mermaidOpText(n.Op)+`")`
+		case n.Rect != nil:
+			id, shape = "r"+strconv.Itoa(i), `["`+mermaidEscaper.Replace(backRefText(n.Rect))+`"]`
+		default:
+			id, shape = "p"+strconv.Itoa(i), `(["`+mermaidEscaper.Replace(n.Port)+`"])`
+		}
+		ids[n.ID] = id
+		buf.WriteString("    " + id + shape + "\n")
+	}
+
+	ops := make(map[string]bool, len(g.Nodes))
+	for _, n := range g.Nodes {
+		ops[n.ID] = n.Op != nil
+	}
+	for _, e := range g.Edges {
+		buf.WriteString("    " + ids[e.From] + " -->")
+		if label := edgeLabel(e.Arrow, ops[e.From], ops[e.To]); label != "" {
+			buf.WriteString(`|"` + mermaidEscaper.Replace(label) + `"|`)
+		}
+		buf.WriteString(" " + ids[e.To] + "\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// safeID replaces all characters that aren't allowed in IDs of Mermaid or
+// PlantUML diagrams.
+// Non-ASCII characters are replaced by their code point and '_' is doubled,
+// so different Unicode names keep different IDs.
+func safeID(id string) string {
+	buf := strings.Builder{}
+	for _, r := range id {
+		switch {
+		case ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9'):
+			buf.WriteRune(r)
+		case r == '_':
+			buf.WriteString("__")
+		case r >= utf8.RuneSelf:
+			buf.WriteString("_" + strconv.FormatInt(int64(r), 16) + "_")
+		default:
+			buf.WriteByte('_')
+		}
+	}
+	return buf.String()
+}
+
+func mermaidOpText(op *Op) string {
+	lines := make([]string, 0, len(op.Main.Text)+len(op.Plugins))
+	for _, t := range op.Main.Text {
+		lines = append(lines, mermaidEscaper.Replace(t))
+	}
+	for _, p := range op.Plugins {
+		lines = append(lines, "<i>"+mermaidEscaper.Replace(pluginText(p))+"</i>")
+	}
+	return strings.Join(lines, "<br/>")
+}
+
+// pluginText returns the title and types of a plugin in a single line.
+func pluginText(p *Plugin) string {
+	types := make([]string, 0, len(p.Rects))
+	for _, r := range p.Rects {
+		types = append(types, strings.Join(r.Text, " "))
+	}
+	if p.Title == "" {
+		return strings.Join(types, ", ")
+	}
+	return p.Title + ": " + strings.Join(types, ", ")
+}
+
+// edgeLabel returns the ports (only at operations) and data types of an arrow
+// in a single line.
+func edgeLabel(a *Arrow, hasSrcOp, hasDstOp bool) string {
+	parts := make([]string, 0, 3)
+	if hasSrcOp && a.SrcPort != "" {
+		parts = append(parts, a.SrcPort)
+	}
+	if len(a.DataType) > 0 {
+		parts = append(parts, strings.Join(a.DataType, ""))
+	}
+	if hasDstOp && a.DstPort != "" {
+		parts = append(parts, a.DstPort)
+	}
+	return strings.Join(parts, " ")
+}
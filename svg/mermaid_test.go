@@ -0,0 +1,80 @@
+package svg_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/gflowparser/svg"
+)
+
+func TestMermaidFromGraphData(t *testing.T) {
+	g := svg.Graph{
+		Nodes: []*svg.Node{
+			{ID: "#0", Port: "in"},
+			{ID: "fetch", Op: &svg.Op{
+				Main: &svg.Rect{Text: []string{"fetch", "http.Get"}},
+				Plugins: []*svg.Plugin{{
+					Title: "semantics",
+					Rects: []*svg.Rect{{Text: []string{"Text"}}, {Text: []string{"Number"}}},
+				}},
+			}},
+			{ID: "end", Op: &svg.Op{Main: &svg.Rect{Text: []string{"end"}}}},
+			{ID: "#3", Port: "error"},
+		},
+		Edges: []*svg.Edge{
+			{From: "#0", To: "fetch", Arrow: &svg.Arrow{DataType: []string{"(url)"}, SrcPort: "in"}},
+			{From: "fetch", To: "end", Arrow: &svg.Arrow{
+				DataType: []string{"(a,", " b)"}, SrcPort: "out", DstPort: `in"x"`,
+			}},
+			{From: "end", To: "fetch", Arrow: &svg.Arrow{}},
+			{From: "fetch", To: "#3", Arrow: &svg.Arrow{SrcPort: "error", DstPort: "error"}},
+		},
+	}
+	expected := `flowchart LR
+    p0(["in"])
+    c_fetch("fetch<br/>http.Get<br/><i>semantics: Text, Number</i>")
+    c_end("end")
+    p3(["error"])
+    p0 -->|"(url)"| c_fetch
+    c_fetch -->|"out (a, b) in#quot;x#quot;"| c_end
+    c_end --> c_fetch
+    c_fetch -->|"error"| p3
+`
+	got, err := svg.MermaidFromGraphData(g)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected Mermaid flowchart:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	_, err = svg.MermaidFromGraphData(svg.Graph{})
+	if err == nil {
+		t.Error("Expected an error for an empty graph but didn't get one.")
+	}
+}
+
+func TestMermaidUnicodeIDs(t *testing.T) {
+	g := svg.Graph{
+		Nodes: []*svg.Node{
+			{ID: "größe", Op: &svg.Op{Main: &svg.Rect{Text: []string{"größe"}}}},
+			{ID: "grüße", Op: &svg.Op{Main: &svg.Rect{Text: []string{"grüße"}}}},
+			{ID: "gr_f6_", Op: &svg.Op{Main: &svg.Rect{Text: []string{"gr_f6_"}}}},
+		},
+		Edges: []*svg.Edge{
+			{From: "größe", To: "grüße", Arrow: &svg.Arrow{}},
+		},
+	}
+	expected := `flowchart LR
+    c_gr_f6__df_e("größe")
+    c_gr_fc__df_e("grüße")
+    c_gr__f6__("gr_f6_")
+    c_gr_f6__df_e --> c_gr_fc__df_e
+`
+	got, err := svg.MermaidFromGraphData(g)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected Mermaid flowchart:\n%s\nbut got:\n%s", expected, got)
+	}
+}
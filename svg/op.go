@@ -3,13 +3,19 @@ package svg
 func opDataToSVG(op *Op, sf *svgFlow, x0, y0, y1 int,
 ) (nsf *svgFlow, lsr *svgRect, ny0 int, xn, yn int) {
 	var y int
+	cfg := sf.cfg
 
-	opW := maxTextWidth(op.Main) + 2*12 // text + padding
+	opW := maxTextWidth(cfg, op.Main) + 2*cfg.CharWidth // text + padding
 	opH := y1 - y0
 	for _, f := range op.Plugins {
-		w := maxPluginWidth(f)
+		w := maxPluginWidth(cfg, f)
 		opW = max(opW, w)
 	}
+	var sub *svgFlow
+	if op.Subflow != nil {
+		sub = flowDataToSVGFlow(*op.Subflow, cfg)
+		opW = max(opW, sub.TotalWidth+2*cfg.Padding)
+	}
 
 	if sf.completedMerge != nil {
 		x0 = sf.completedMerge.x0
@@ -18,24 +24,78 @@ func opDataToSVG(op *Op, sf *svgFlow, x0, y0, y1 int,
 		opH = max(opH, sf.completedMerge.yn-y0)
 	}
 
+	nTexts := len(sf.Texts)
 	lsr, y, xn, yn = outerOpToSVG(op.Main, opW, opH, sf, x0, y0)
+	if op.Link != "" {
+		lsr.Link = op.Link
+		for _, t := range sf.Texts[nTexts:] {
+			t.Link = op.Link
+		}
+	}
 	for _, f := range op.Plugins {
 		y = pluginDataToSVG(f, xn-x0, sf, x0, y)
 	}
 	if len(op.Plugins) > 0 {
-		y += 6
-		lsr.Height = max(lsr.Height+6, y-y0)
-		yn = max(yn, y0+lsr.Height+2*6)
+		y += cfg.Padding
+		lsr.Height = max(lsr.Height+cfg.Padding, y-y0)
+		yn = max(yn, y0+lsr.Height+2*cfg.Padding)
+	}
+	if sub != nil {
+		lsr.IsSubflow = true
+		addSubflowToSVG(sub, sf, x0+cfg.Padding, y)
+		y += sub.TotalHeight
+		lsr.Height = max(lsr.Height, y-lsr.Y)
+		yn = max(yn, lsr.Y+lsr.Height+cfg.Padding)
 	}
 
 	return sf, lsr, y0, xn, yn
 }
 
+// addSubflowToSVG moves all elements of the subflow by dx and dy and adds
+// them to the outer flow.
+func addSubflowToSVG(nsf, sf *svgFlow, dx, dy int) {
+	for _, a := range nsf.Arrows {
+		a.X1, a.Y1, a.X2, a.Y2 = a.X1+dx, a.Y1+dy, a.X2+dx, a.Y2+dy
+		a.XTip1, a.YTip1 = a.XTip1+dx, a.YTip1+dy
+		a.XTip2, a.YTip2 = a.XTip2+dx, a.YTip2+dy
+		sf.Arrows = append(sf.Arrows, a)
+	}
+	for _, pl := range nsf.Polylines {
+		for i := range pl.Points {
+			pl.Points[i].X += dx
+			pl.Points[i].Y += dy
+		}
+		sf.Polylines = append(sf.Polylines, pl)
+	}
+	for _, r := range nsf.Rects {
+		r.X, r.Y = r.X+dx, r.Y+dy
+		sf.Rects = append(sf.Rects, r)
+	}
+	for _, l := range nsf.Lines {
+		l.X1, l.Y1, l.X2, l.Y2 = l.X1+dx, l.Y1+dy, l.X2+dx, l.Y2+dy
+		sf.Lines = append(sf.Lines, l)
+	}
+	for _, t := range nsf.Texts {
+		t.X, t.Y = t.X+dx, t.Y+dy
+		sf.Texts = append(sf.Texts, t)
+	}
+	for _, m := range nsf.PortMarkers {
+		m.X, m.Y = m.X+dx, m.Y+dy
+		sf.PortMarkers = append(sf.PortMarkers, m)
+	}
+	for _, c := range nsf.Circles {
+		c.CX, c.CY = c.CX+dx, c.CY+dy
+		sf.Circles = append(sf.Circles, c)
+	}
+}
+
 func outerOpToSVG(r *Rect, w int, h int, sf *svgFlow, x0, y0 int,
 ) (svgMainRect *svgRect, y02 int, xn int, yn int) {
+	cfg := sf.cfg
 	x := x0
-	y := y0 + 6
-	h0 := len(r.Text)*24 + 6*2
+	y := y0 + cfg.Padding
+	lines := cfg.fitTexts(r.Text, typeBreaks)
+	h0 := len(lines)*cfg.LineHeight + cfg.Padding*2
 	h = max(h, h0)
 
 	svgMainRect = &svgRect{
@@ -45,17 +105,18 @@ func outerOpToSVG(r *Rect, w int, h int, sf *svgFlow, x0, y0 int,
 	}
 	sf.Rects = append(sf.Rects, svgMainRect)
 
-	y += 6
-	for _, t := range r.Text {
+	y += cfg.Padding
+	for _, t := range lines {
 		sf.Texts = append(sf.Texts, &svgText{
-			X: x + 12, Y: y + 24 - 6,
-			Width: len(t) * 12,
-			Text:  t,
+			X: x + cfg.CharWidth, Y: y + cfg.LineHeight - cfg.descent(),
+			Width: cfg.textWidth(textLen(t.text)),
+			Text:  t.text,
+			Title: t.title,
 		})
-		y += 24
+		y += cfg.LineHeight
 	}
 
-	return svgMainRect, y0 + 6 + h0, x + w, y0 + h + 2*6
+	return svgMainRect, y0 + cfg.Padding + h0, x + w, y0 + h + 2*cfg.Padding
 }
 
 func pluginDataToSVG(
@@ -64,17 +125,18 @@ func pluginDataToSVG(
 	sf *svgFlow,
 	x0, y0 int,
 ) (yn int) {
+	cfg := sf.cfg
 	x := x0
 	y := y0
 
-	y += 3
+	y += cfg.Padding / 2
 	if f.Title != "" {
 		sf.Texts = append(sf.Texts, &svgText{
-			X: x + 6, Y: y + 24 - 6,
-			Width: (len(f.Title) + 1) * 12,
+			X: x + cfg.Padding, Y: y + cfg.LineHeight - cfg.descent(),
+			Width: cfg.textWidth(textLen(f.Title) + 1),
 			Text:  f.Title + ":",
 		})
-		y += 24
+		y += cfg.LineHeight
 	}
 
 	for i, r := range f.Rects {
@@ -83,19 +145,20 @@ func pluginDataToSVG(
 				X1: x0, Y1: y,
 				X2: x0 + width, Y2: y,
 			})
-			y += 3
+			y += cfg.Padding / 2
 		}
-		for _, t := range r.Text {
+		for _, t := range cfg.fitTexts(r.Text, typeBreaks) {
 			sf.Texts = append(sf.Texts, &svgText{
-				X: x + 6, Y: y + 24 - 6,
-				Width: len(t) * 12,
-				Text:  t,
+				X: x + cfg.Padding, Y: y + cfg.LineHeight - cfg.descent(),
+				Width: cfg.textWidth(textLen(t.text)),
+				Text:  t.text,
+				Title: t.title,
 			})
-			y += 24
+			y += cfg.LineHeight
 		}
 	}
 
-	y += 3
+	y += cfg.Padding / 2
 	sf.Rects = append(sf.Rects, &svgRect{
 		X: x0, Y: y0,
 		Width:    width,
@@ -106,26 +169,18 @@ func pluginDataToSVG(
 	return y
 }
 
-func maxPluginWidth(f *Plugin) int {
+func maxPluginWidth(cfg *LayoutConfig, f *Plugin) int {
 	width := 0
 	if f.Title != "" {
-		width = (len(f.Title)+1)*12 + 2*6 // title text and padding
+		width = cfg.textWidth(textLen(f.Title)+1) + 2*cfg.Padding // title text and padding
 	}
 	for _, r := range f.Rects {
-		w := maxTextWidth(r)
-		width = max(width, w+2*6)
+		w := maxTextWidth(cfg, r)
+		width = max(width, w+2*cfg.Padding)
 	}
 	return width
 }
 
-func maxTextWidth(r *Rect) int {
-	return maxLen(r.Text) * 12
-}
-
-func maxLen(ss []string) int {
-	m := 0
-	for _, s := range ss {
-		m = max(m, len(s))
-	}
-	return m
+func maxTextWidth(cfg *LayoutConfig, r *Rect) int {
+	return cfg.textWidth(maxLineLen(cfg.fitTexts(r.Text, typeBreaks)))
 }
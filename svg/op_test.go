@@ -0,0 +1,91 @@
+package svg_test
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/flowdev/gflowparser/svg"
+)
+
+func TestSubflowOps(t *testing.T) {
+	inner := svg.NewFlow().
+		Arrow(&svg.Arrow{DstPort: "in"}).
+		Op(&svg.Op{Main: &svg.Rect{Text: []string{"inner"}}}).
+		Arrow(&svg.Arrow{SrcPort: "out"}).
+		Flow()
+	outer := func(op *svg.Op) svg.Flow {
+		return svg.NewFlow().
+			Arrow(&svg.Arrow{DataType: []string{"(x)"}, SrcPort: "in"}).
+			Op(op).
+			Arrow(&svg.Arrow{SrcPort: "out"}).
+			Flow()
+	}
+
+	expanded := outer(&svg.Op{Main: &svg.Rect{Text: []string{"sub", "Sub"}}, Subflow: &inner})
+	buf, err := svg.TextFromFlowData(expanded, svg.TextASCII)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	lines := strings.Split(string(buf), "\n")
+	subLine, innerLine := -1, -1
+	for i, l := range lines {
+		if strings.Contains(l, "|sub ") {
+			subLine = i
+		}
+		if strings.Contains(l, "|inner ") {
+			innerLine = i
+		}
+	}
+	if subLine < 0 || innerLine <= subLine {
+		t.Fatalf("Expected the subflow inside and below the operation but got:\n%s", buf)
+	}
+	if l := lines[innerLine]; strings.Index(l, "|") == strings.Index(l, "|inner ") {
+		t.Errorf("Expected the subflow to be nested inside of the operation but got:\n%s", buf)
+	}
+
+	buf, err = svg.FromFlowData(expanded)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if !strings.Contains(string(buf), `fill="none" stroke="rgb(0,0,0)" stroke-opacity="1.0" stroke-width="2.5" stroke-dasharray="8,4"`) {
+		t.Errorf("Expected a dashed rectangle for the subflow but got:\n%s", buf)
+	}
+	if strings.Count(string(buf), "<rect ") != 3 { // background, operation and inner operation
+		t.Errorf("Expected 3 rectangles but got:\n%s", buf)
+	}
+
+	buf, err = svg.DrawIOFromFlowData(expanded, svg.DefaultLayoutConfig())
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	df := drawioFile{}
+	if err = xml.Unmarshal(buf, &df); err != nil {
+		t.Fatalf("Expected valid XML but got error: %s", err)
+	}
+	parents := make(map[string]string)
+	for _, c := range df.Cells {
+		if c.Vertex == "1" && strings.HasPrefix(c.Style, "rounded=1;") {
+			parents[c.ID] = c.Parent
+		}
+	}
+	if len(parents) != 2 || parents["2"] != "1" || parents["3"] != "2" {
+		t.Errorf("Expected the inner operation inside of the outer one but got: %v", parents)
+	}
+
+	linked := outer(&svg.Op{Main: &svg.Rect{Text: []string{"sub", "Sub"}}, Link: "sub.svg?a=1&b=2"})
+	buf, err = svg.FromFlowData(linked)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if n := strings.Count(string(buf), `href="sub.svg?a=1&amp;b=2"`); n != 6 { // 2 per rectangle and text
+		t.Errorf("Expected 6 escaped links but got %d:\n%s", n, buf)
+	}
+	plain, err := svg.FromFlowData(outer(&svg.Op{Main: &svg.Rect{Text: []string{"sub", "Sub"}}}))
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	if strings.Contains(string(plain), "<a ") {
+		t.Errorf("Expected no links without subflows but got:\n%s", plain)
+	}
+}
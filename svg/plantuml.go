@@ -0,0 +1,131 @@
+package svg
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// plantUMLEscaper keeps free texts (e.g. attribute values) from being
+// interpreted as creole markup or line breaks.
+var plantUMLEscaper = strings.NewReplacer(
+	`"`, "'",
+	"<", "&#60;",
+	">", "&#62;",
+	`\`, "&#92;",
+	"\r\n", " ",
+	"\n", " ",
+	"\r", " ",
+)
+
+// PlantUMLFromGraphData creates a PlantUML component diagram from graph
+// data.
+// Operations become components with their name, type and plugins and the
+// ports of operations become port interfaces of the components.
+// Outer ports become interfaces and arrows are labelled with their data
+// types.
+// In contrast to FromGraphData the graph may contain circles.
+// If the graph data isn't valid, an error is returned.
+func PlantUMLFromGraphData(g Graph) ([]byte, error) {
+	err := validateGraphData(g)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]string, len(g.Nodes))
+	ops := make(map[string]bool, len(g.Nodes))
+	for i, n := range g.Nodes {
+		switch {
+		case n.Op != nil:
+			ids[n.ID] = "c_" + safeID(n.ID)
+			ops[n.ID] = true
+		case n.Rect != nil:
+			ids[n.ID] = "r" + strconv.Itoa(i)
+		default:
+			ids[n.ID] = "p" + strconv.Itoa(i)
+		}
+	}
+
+	// collect the ports of the operations
+	type umlPort struct {
+		id, name string
+		in       bool
+	}
+	ports := make(map[string][]*umlPort, len(g.Nodes))
+	portIDs := make(map[string]string)
+	addPort := func(node, port string, in bool) string {
+		if !ops[node] || port == "" {
+			return ids[node]
+		}
+		dir := "out"
+		if in {
+			dir = "in"
+		}
+		key := node + "\x00" + dir + "\x00" + port
+		if id, ok := portIDs[key]; ok {
+			return id
+		}
+		id := ids[node] + "__" + dir + "_" + safeID(port)
+		portIDs[key] = id
+		ports[node] = append(ports[node], &umlPort{id: id, name: port, in: in})
+		return id
+	}
+	type umlEdge struct {
+		from, to, label string
+	}
+	edges := make([]umlEdge, len(g.Edges))
+	for i, e := range g.Edges {
+		edges[i] = umlEdge{
+			from:  addPort(e.From, e.Arrow.SrcPort, false),
+			to:    addPort(e.To, e.Arrow.DstPort, true),
+			label: plantUMLEscaper.Replace(strings.Join(e.Arrow.DataType, "")),
+		}
+	}
+
+	buf := bytes.Buffer{}
+	buf.WriteString("@startuml\nleft to right direction\n")
+	for _, n := range g.Nodes {
+		id := ids[n.ID]
+		switch {
+		case n.Op != nil:
+			buf.WriteString(`component "` + plantUMLOpText(n.Op) + `" as ` + id)
+			if len(ports[n.ID]) == 0 {
+				buf.WriteString("\n")
+				continue
+			}
+			buf.WriteString(" {\n")
+			for _, p := range ports[n.ID] {
+				kind := "portout"
+				if p.in {
+					kind = "portin"
+				}
+				buf.WriteString("  " + kind + ` "` + plantUMLEscaper.Replace(p.name) + `" as ` + p.id + "\n")
+			}
+			buf.WriteString("}\n")
+		case n.Rect != nil:
+			buf.WriteString(`rectangle "` + plantUMLEscaper.Replace(backRefText(n.Rect)) + `" as ` + id + "\n")
+		default:
+			buf.WriteString(`interface "` + plantUMLEscaper.Replace(n.Port) + `" as ` + id + "\n")
+		}
+	}
+	for _, e := range edges {
+		buf.WriteString(e.from + " --> " + e.to)
+		if e.label != "" {
+			buf.WriteString(" : " + e.label)
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("@enduml\n")
+	return buf.Bytes(), nil
+}
+
+func plantUMLOpText(op *Op) string {
+	lines := make([]string, 0, len(op.Main.Text)+len(op.Plugins))
+	for _, t := range op.Main.Text {
+		lines = append(lines, plantUMLEscaper.Replace(t))
+	}
+	for _, p := range op.Plugins {
+		lines = append(lines, "<i>"+plantUMLEscaper.Replace(pluginText(p))+"</i>")
+	}
+	return strings.Join(lines, `\n`)
+}
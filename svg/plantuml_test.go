@@ -0,0 +1,61 @@
+package svg_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/gflowparser/svg"
+)
+
+func TestPlantUMLFromGraphData(t *testing.T) {
+	g := svg.Graph{
+		Nodes: []*svg.Node{
+			{ID: "#0", Port: "in"},
+			{ID: "fetch", Op: &svg.Op{
+				Main: &svg.Rect{Text: []string{"fetch", "http.Get"}},
+				Plugins: []*svg.Plugin{{
+					Title: "semantics",
+					Rects: []*svg.Rect{{Text: []string{"Text"}}},
+				}},
+			}},
+			{ID: "store", Op: &svg.Op{Main: &svg.Rect{Text: []string{"store"}}}},
+			{ID: "#3", Port: "error"},
+		},
+		Edges: []*svg.Edge{
+			{From: "#0", To: "fetch", Arrow: &svg.Arrow{DataType: []string{"(url)"}, SrcPort: "in"}},
+			{From: "fetch", To: "store", Arrow: &svg.Arrow{
+				DataType: []string{"(a,", " b)"}, SrcPort: "out[1]", DstPort: "in",
+			}},
+			{From: "fetch", To: "store", Arrow: &svg.Arrow{SrcPort: "out[1]"}},
+			{From: "store", To: "#3", Arrow: &svg.Arrow{SrcPort: "error", DstPort: "error"}},
+		},
+	}
+	expected := `@startuml
+left to right direction
+interface "in" as p0
+component "fetch\nhttp.Get\n<i>semantics: Text</i>" as c_fetch {
+  portout "out[1]" as c_fetch__out_out_1_
+}
+component "store" as c_store {
+  portin "in" as c_store__in_in
+  portout "error" as c_store__out_error
+}
+interface "error" as p3
+p0 --> c_fetch : (url)
+c_fetch__out_out_1_ --> c_store__in_in : (a, b)
+c_fetch__out_out_1_ --> c_store
+c_store__out_error --> p3
+@enduml
+`
+	got, err := svg.PlantUMLFromGraphData(g)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Expected PlantUML diagram:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	_, err = svg.PlantUMLFromGraphData(svg.Graph{})
+	if err == nil {
+		t.Error("Expected an error for an empty graph but didn't get one.")
+	}
+}
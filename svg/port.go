@@ -0,0 +1,175 @@
+package svg
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// portEnd is the end of an arrow at a port of an operation.
+type portEnd struct {
+	x, y  *int // position of the arrow end (it might be moved later)
+	src   bool // start of the arrow (else its end)
+	port  string
+	text  *svgText  // label of the port
+	arrow *svgArrow // straight arrow that can be moved to another port (or nil)
+}
+
+func addPortEnd(sf *svgFlow, x, y *int, src bool, port string, text *svgText, arrow *svgArrow) {
+	if !sf.cfg.PortMarkers || port == "" {
+		return
+	}
+	sf.portEnds = append(sf.portEnds, &portEnd{
+		x: x, y: y, src: src, port: port, text: text, arrow: arrow,
+	})
+}
+
+// addPortMarkers draws a small square on the edge of an operation for every
+// port.
+// Neighbouring arrows using the same port share its marker and label.
+// Array ports are stacked in index order (see sortPortEnds).
+func addPortMarkers(sf *svgFlow) {
+	type edgeKey struct {
+		op  *svgRect
+		x   int
+		src bool
+	}
+	edges := make(map[edgeKey][]*portEnd)
+	keys := make([]edgeKey, 0, len(sf.portEnds))
+	for _, pe := range sf.portEnds {
+		k := edgeKey{op: findOpRect(sf, *pe.x, *pe.y, pe.src), x: *pe.x, src: pe.src}
+		if _, ok := edges[k]; !ok {
+			keys = append(keys, k)
+		}
+		edges[k] = append(edges[k], pe)
+	}
+
+	dropTexts := make(map[*svgText]bool)
+	for _, k := range keys {
+		pes := edges[k]
+		sortPortEnds(pes)
+		first := 0
+		for i := 1; i <= len(pes); i++ {
+			if i < len(pes) && pes[i].port == pes[first].port {
+				if pes[i].text != nil {
+					dropTexts[pes[i].text] = true
+				}
+				continue
+			}
+			addPortMarker(sf, pes[first], pes[i-1])
+			first = i
+		}
+	}
+	if len(dropTexts) == 0 {
+		return
+	}
+	texts := sf.Texts[:0]
+	for _, t := range sf.Texts {
+		if !dropTexts[t] {
+			texts = append(texts, t)
+		}
+	}
+	sf.Texts = texts
+}
+
+// sortPortEnds sorts the ends at one edge of an operation by port and
+// index just like sortByPort.
+// Arrows from the rows layout are moved to the positions of their ports
+// since the order of the rows can't be changed.
+func sortPortEnds(pes []*portEnd) {
+	sort.SliceStable(pes, func(i, j int) bool {
+		return *pes[i].y < *pes[j].y
+	})
+	ys := make([]int, len(pes))
+	for i, pe := range pes {
+		ys[i] = *pe.y
+	}
+	rank := make(map[string]int, len(pes))
+	for _, pe := range pes {
+		name, _ := splitPort(pe.port)
+		if _, ok := rank[name]; !ok {
+			rank[name] = len(rank)
+		}
+	}
+	sort.SliceStable(pes, func(i, j int) bool {
+		ni, ii := splitPort(pes[i].port)
+		nj, ij := splitPort(pes[j].port)
+		if rank[ni] != rank[nj] {
+			return rank[ni] < rank[nj]
+		}
+		return ii < ij
+	})
+	for i, pe := range pes {
+		if d := ys[i] - *pe.y; d != 0 && pe.arrow != nil {
+			movePortEnd(pe, d)
+		}
+	}
+}
+
+func movePortEnd(pe *portEnd, d int) {
+	*pe.y += d
+	if pe.text != nil {
+		pe.text.Y += d
+	}
+	if !pe.src {
+		pe.arrow.YTip1 += d
+		pe.arrow.YTip2 += d
+	}
+}
+
+func addPortMarker(sf *svgFlow, first, last *portEnd) {
+	s := sf.cfg.ArrowTipSize
+	sf.PortMarkers = append(sf.PortMarkers, &svgRect{
+		X: *first.x - s/2, Y: *first.y - s/2,
+		Width: s, Height: *last.y - *first.y + s,
+	})
+}
+
+// findOpRect finds the operation that has got the point on its right (src)
+// or left edge.
+func findOpRect(sf *svgFlow, x, y int, src bool) *svgRect {
+	for _, r := range sf.Rects {
+		if r.IsPlugin || y < r.Y || y > r.Y+r.Height {
+			continue
+		}
+		if (src && r.X+r.Width == x) || (!src && r.X == x) {
+			return r
+		}
+	}
+	return nil
+}
+
+// splitPort splits a port like 'out[2]' into its name and index.
+// Ports without index get the index -1.
+func splitPort(port string) (name string, index int) {
+	i := strings.IndexByte(port, '[')
+	if i < 0 || !strings.HasSuffix(port, "]") {
+		return port, -1
+	}
+	idx, err := strconv.Atoi(port[i+1 : len(port)-1])
+	if err != nil {
+		return port, -1
+	}
+	return port[:i], idx
+}
+
+// sortByPort keeps segments of the same port together with array ports
+// stacked in index order.
+// Different ports keep the order of their first segment.
+func sortByPort(segs []*lSeg, port func(*lSeg) string) {
+	rank := make(map[string]int, len(segs))
+	for _, s := range segs {
+		name, _ := splitPort(port(s))
+		if _, ok := rank[name]; !ok {
+			rank[name] = len(rank)
+		}
+	}
+	sort.SliceStable(segs, func(i, j int) bool {
+		ni, ii := splitPort(port(segs[i]))
+		nj, ij := splitPort(port(segs[j]))
+		if rank[ni] != rank[nj] {
+			return rank[ni] < rank[nj]
+		}
+		return ii < ij
+	})
+}
@@ -0,0 +1,110 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitPort(t *testing.T) {
+	specs := []struct {
+		given         string
+		expectedName  string
+		expectedIndex int
+	}{
+		{given: "out", expectedName: "out", expectedIndex: -1},
+		{given: "out[3]", expectedName: "out", expectedIndex: 3},
+		{given: "out[x]", expectedName: "out[x]", expectedIndex: -1},
+		{given: "...2", expectedName: "...2", expectedIndex: -1},
+	}
+	for _, spec := range specs {
+		name, idx := splitPort(spec.given)
+		if name != spec.expectedName || idx != spec.expectedIndex {
+			t.Errorf("Expected '%s' and %d for port '%s' but got: '%s' and %d",
+				spec.expectedName, spec.expectedIndex, spec.given, name, idx)
+		}
+	}
+}
+
+func TestPortMarkers(t *testing.T) {
+	op := func(id string) *Node {
+		return &Node{ID: id, Op: &Op{Main: &Rect{Text: []string{id}}}}
+	}
+	g := Graph{
+		Nodes: []*Node{op("a"), op("b"), op("c"), op("d")},
+		Edges: []*Edge{
+			{From: "a", To: "d", Arrow: &Arrow{SrcPort: "out[2]"}},
+			{From: "a", To: "b", Arrow: &Arrow{SrcPort: "out[1]"}},
+			{From: "a", To: "c", Arrow: &Arrow{SrcPort: "out[1]"}},
+		},
+	}
+	cfg := DefaultLayoutConfig()
+	cfg.PortMarkers = true
+	lg, err := newLayeredGraph(g, &cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	var ports []string
+	for _, n := range lg.nodes {
+		if n.node != nil && n.node.ID == "a" {
+			for _, s := range n.outs {
+				ports = append(ports, s.edge.edge.Arrow.SrcPort)
+			}
+		}
+	}
+	if got := strings.Join(ports, " "); got != "out[1] out[1] out[2]" {
+		t.Errorf("Expected ports stacked in index order but got: %s", got)
+	}
+
+	sf := layeredGraphToSVGFlow(lg)
+	if len(sf.PortMarkers) != 2 {
+		t.Fatalf("Expected 2 port markers but got: %d", len(sf.PortMarkers))
+	}
+	if m := sf.PortMarkers[0]; m.Height <= cfg.ArrowTipSize {
+		t.Errorf("Expected shared port marker to span both arrows but got height: %d", m.Height)
+	}
+	labels := 0
+	for _, st := range sf.Texts {
+		if st.Text == "out[1]" {
+			labels++
+		}
+	}
+	if labels != 1 {
+		t.Errorf("Expected exactly 1 label for the shared port but got: %d", labels)
+	}
+}
+
+func TestSortPortEnds(t *testing.T) {
+	cfg := DefaultLayoutConfig()
+	newEnd := func(port string, y int) *portEnd {
+		arr := newSVGArrow(&cfg, 10, 100, y)
+		return &portEnd{
+			x: &arr.X1, y: &arr.Y1, src: true, port: port,
+			text:  &svgText{Y: y + cfg.portTextOffset(), Text: port},
+			arrow: arr,
+		}
+	}
+	out2 := newEnd("out[2]", 45)
+	out1 := newEnd("out[1]", 110)
+	err := newEnd("err", 175)
+	pes := []*portEnd{err, out2, out1}
+
+	sortPortEnds(pes)
+
+	for i, expected := range []string{"out[1]", "out[2]", "err"} {
+		if pes[i].port != expected {
+			t.Errorf("Expected port %s at position %d but got: %s", expected, i, pes[i].port)
+		}
+	}
+	for _, spec := range []struct {
+		pe *portEnd
+		y  int
+	}{{out1, 45}, {out2, 110}, {err, 175}} {
+		if *spec.pe.y != spec.y || spec.pe.text.Y != spec.y+cfg.portTextOffset() {
+			t.Errorf("Expected port %s and its label at y=%d but got: %d and %d",
+				spec.pe.port, spec.y, *spec.pe.y, spec.pe.text.Y)
+		}
+		if spec.pe.arrow.Y2 != spec.pe.arrow.YTip1+cfg.ArrowTipSize {
+			t.Errorf("Expected the tip of port %s to stay at the arrow end", spec.pe.port)
+		}
+	}
+}
@@ -1,10 +1,11 @@
 package svg
 
 func rectDataToSVG(r *Rect, sf *svgFlow, x int, y int) (nsf *svgFlow, nx, ny int) {
-	txt := "... back to: " + r.Text[0]
-	width := len(txt) * 12
+	cfg := sf.cfg
+	txt := backRefText(r)
+	width := cfg.textWidth(textLen(txt))
 
-	y += 12 + 24 - 6
+	y += cfg.LineHeight/2 + cfg.LineHeight - cfg.descent()
 	sf.Texts = append(sf.Texts, &svgText{
 		X: x, Y: y,
 		Width: width,
@@ -13,5 +14,9 @@ func rectDataToSVG(r *Rect, sf *svgFlow, x int, y int) (nsf *svgFlow, nx, ny int
 
 	x += width
 
-	return sf, x + width + 12, y + 12
+	return sf, x + width + cfg.CharWidth, y + cfg.LineHeight/2
+}
+
+func backRefText(r *Rect) string {
+	return "... back to: " + r.Text[0]
 }
@@ -0,0 +1,103 @@
+package svg
+
+import (
+	"fmt"
+)
+
+// Shape is a single shape of a Flow or Split.
+// It is implemented by *Arrow, *Op, *Rect, *Split and *Merge only.
+type Shape interface {
+	isShape()
+}
+
+func (*Arrow) isShape() {}
+func (*Op) isShape()    {}
+func (*Rect) isShape()  {}
+func (*Split) isShape() {}
+func (*Merge) isShape() {}
+
+// ShapesFromInterfaces converts shapes given as [][]interface{} (as used by
+// older versions of this package) into typed shapes.
+// If a value isn't a shape, an error is returned.
+func ShapesFromInterfaces(rows [][]interface{}) ([][]Shape, error) {
+	shapes := make([][]Shape, len(rows))
+	for i, row := range rows {
+		shapes[i] = make([]Shape, len(row))
+		for j, ishape := range row {
+			shape, ok := ishape.(Shape)
+			if !ok {
+				return nil, fmt.Errorf(
+					"unsupported shape type %T at row index %d and column index %d",
+					ishape, i, j)
+			}
+			shapes[i][j] = shape
+		}
+	}
+	return shapes, nil
+}
+
+// Builder builds the rows of shapes of a Flow or Split.
+// Shapes are always added to the last row.
+type Builder struct {
+	rows [][]Shape
+}
+
+// NewFlow returns a Builder for a Flow.
+func NewFlow() *Builder {
+	return &Builder{}
+}
+
+// NewSplit returns a Builder for the shapes of a Split.
+func NewSplit() *Builder {
+	return &Builder{}
+}
+
+// Row starts a new row.
+func (b *Builder) Row() *Builder {
+	b.rows = append(b.rows, []Shape{})
+	return b
+}
+
+// Arrow adds an arrow to the last row.
+func (b *Builder) Arrow(a *Arrow) *Builder {
+	return b.add(a)
+}
+
+// Op adds an operation to the last row.
+func (b *Builder) Op(op *Op) *Builder {
+	return b.add(op)
+}
+
+// Rect adds a rectangle (e.g. a back reference) to the last row.
+func (b *Builder) Rect(r *Rect) *Builder {
+	return b.add(r)
+}
+
+// Split adds a split with the rows of the given Builder to the last row.
+func (b *Builder) Split(split *Builder) *Builder {
+	return b.add(&Split{Shapes: split.rows})
+}
+
+// Merge adds a merge to the last row.
+func (b *Builder) Merge(m *Merge) *Builder {
+	return b.add(m)
+}
+
+// Shapes returns the rows of shapes built so far.
+func (b *Builder) Shapes() [][]Shape {
+	return b.rows
+}
+
+// Flow returns the flow built so far.
+func (b *Builder) Flow() Flow {
+	return Flow{Shapes: b.rows}
+}
+
+func (b *Builder) add(s Shape) *Builder {
+	if len(b.rows) == 0 {
+		b.Row()
+	}
+	i := len(b.rows) - 1
+	b.rows[i] = append(b.rows[i], s)
+	return b
+}
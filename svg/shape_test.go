@@ -0,0 +1,71 @@
+package svg_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/flowdev/gflowparser/svg"
+)
+
+func TestBuilder(t *testing.T) {
+	expected := svg.Flow{Shapes: [][]svg.Shape{
+		{
+			&svg.Arrow{SrcPort: "in", HasDstOp: true},
+			&svg.Op{Main: &svg.Rect{Text: []string{"a"}}},
+			&svg.Split{Shapes: [][]svg.Shape{
+				{
+					&svg.Arrow{HasSrcOp: true, HasDstOp: true},
+					&svg.Merge{ID: "b", Size: 2},
+				},
+				{&svg.Arrow{HasSrcOp: true, HasDstOp: true}, &svg.Merge{ID: "b", Size: 2}},
+			}},
+		},
+		{
+			&svg.Op{Main: &svg.Rect{Text: []string{"b"}}},
+			&svg.Arrow{HasSrcOp: true, HasDstOp: true},
+			&svg.Rect{Text: []string{"a"}},
+		},
+	}}
+
+	got := svg.NewFlow().
+		Arrow(&svg.Arrow{SrcPort: "in", HasDstOp: true}).
+		Op(&svg.Op{Main: &svg.Rect{Text: []string{"a"}}}).
+		Split(svg.NewSplit().
+			Row().Arrow(&svg.Arrow{HasSrcOp: true, HasDstOp: true}).Merge(&svg.Merge{ID: "b", Size: 2}).
+			Row().Arrow(&svg.Arrow{HasSrcOp: true, HasDstOp: true}).Merge(&svg.Merge{ID: "b", Size: 2}),
+		).
+		Row().
+		Op(&svg.Op{Main: &svg.Rect{Text: []string{"b"}}}).
+		Arrow(&svg.Arrow{HasSrcOp: true, HasDstOp: true}).
+		Rect(&svg.Rect{Text: []string{"a"}}).
+		Flow()
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected flow %#v but got: %#v", expected, got)
+	}
+	if _, err := svg.FromFlowData(got); err != nil {
+		t.Errorf("Expected no error but got: %s", err)
+	}
+}
+
+func TestShapesFromInterfaces(t *testing.T) {
+	arrow := &svg.Arrow{SrcPort: "in", HasDstOp: true}
+	op := &svg.Op{Main: &svg.Rect{Text: []string{"a"}}}
+	got, err := svg.ShapesFromInterfaces([][]interface{}{{arrow, op}, {}})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %s", err)
+	}
+	expected := [][]svg.Shape{{arrow, op}, {}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected shapes %#v but got: %#v", expected, got)
+	}
+
+	_, err = svg.ShapesFromInterfaces([][]interface{}{{arrow}, {op, svg.Arrow{}}})
+	if err == nil {
+		t.Error("Expected an error for a non-pointer arrow but didn't get one.")
+	}
+	_, err = svg.FromFlowData(svg.Flow{Shapes: [][]svg.Shape{{arrow, nil}}})
+	if err == nil {
+		t.Error("Expected an error for a missing shape but didn't get one.")
+	}
+}
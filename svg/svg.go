@@ -11,22 +11,39 @@ const svgDiagram = `<?xml version="1.0" ?>
 <!-- Generated by FlowDev tool. -->
 	<rect fill="rgb(255,255,255)" fill-opacity="1" stroke="none" stroke-opacity="1" stroke-width="0.0" width="{{.TotalWidth}}" height="{{.TotalHeight}}" x="0" y="0"/>
 {{- range .Arrows}}
-	<line stroke="rgb(0,0,0)" stroke-opacity="1.0" stroke-width="2.5" x1="{{.X1}}" y1="{{.Y1}}" x2="{{.X2}}" y2="{{.Y2}}"/>
-	<line stroke="rgb(0,0,0)" stroke-opacity="1.0" stroke-width="2.5" x1="{{.XTip1}}" y1="{{.YTip1}}" x2="{{.X2}}" y2="{{.Y2}}"/>
-	<line stroke="rgb(0,0,0)" stroke-opacity="1.0" stroke-width="2.5" x1="{{.XTip2}}" y1="{{.YTip2}}" x2="{{.X2}}" y2="{{.Y2}}"/>
+	<line stroke="{{if .IsError}}rgb(208,0,0){{else}}rgb(0,0,0){{end}}" stroke-opacity="1.0" stroke-width="2.5"{{if .IsError}} stroke-dasharray="8,4"{{end}} x1="{{.X1}}" y1="{{.Y1}}" x2="{{.X2}}" y2="{{.Y2}}"/>
+	<line stroke="{{if .IsError}}rgb(208,0,0){{else}}rgb(0,0,0){{end}}" stroke-opacity="1.0" stroke-width="2.5" x1="{{.XTip1}}" y1="{{.YTip1}}" x2="{{.X2}}" y2="{{.Y2}}"/>
+	<line stroke="{{if .IsError}}rgb(208,0,0){{else}}rgb(0,0,0){{end}}" stroke-opacity="1.0" stroke-width="2.5" x1="{{.XTip2}}" y1="{{.YTip2}}" x2="{{.X2}}" y2="{{.Y2}}"/>
 {{end}}
+{{- range .Polylines}}
+	<polyline fill="none" stroke="{{if .IsError}}rgb(208,0,0){{else}}rgb(0,0,0){{end}}" stroke-opacity="1.0" stroke-width="2.5"{{if .IsError}} stroke-dasharray="8,4"{{end}} points="{{range $i, $p := .Points}}{{if $i}} {{end}}{{$p.X}},{{$p.Y}}{{end}}"/>
+{{- end}}
 {{- range .Rects}}
 {{- if .IsPlugin}}
 	<rect fill="rgb(32,224,32)" fill-opacity="1.0" stroke="rgb(0,0,0)" stroke-opacity="1.0" stroke-width="2.5" width="{{.Width}}" height="{{.Height}}" x="{{.X}}" y="{{.Y}}"/>
+{{- else if .IsSubflow}}
+	<rect fill="none" stroke="rgb(0,0,0)" stroke-opacity="1.0" stroke-width="2.5" stroke-dasharray="8,4" width="{{.Width}}" height="{{.Height}}" x="{{.X}}" y="{{.Y}}" rx="10" ry="10"/>
+{{- else if .Link}}
+	<a xmlns:xlink="http://www.w3.org/1999/xlink" xlink:href="{{html .Link}}" href="{{html .Link}}"><rect fill="rgb(96,196,255)" fill-opacity="1.0" stroke="rgb(0,0,0)" stroke-opacity="1.0" stroke-width="2.5" width="{{.Width}}" height="{{.Height}}" x="{{.X}}" y="{{.Y}}" rx="10" ry="10"/></a>
 {{- else}}
 	<rect fill="rgb(96,196,255)" fill-opacity="1.0" stroke="rgb(0,0,0)" stroke-opacity="1.0" stroke-width="2.5" width="{{.Width}}" height="{{.Height}}" x="{{.X}}" y="{{.Y}}" rx="10" ry="10"/>
 {{- end}}
 {{- end}}
+{{- range .PortMarkers}}
+	<rect fill="rgb(255,255,255)" fill-opacity="1.0" stroke="rgb(0,0,0)" stroke-opacity="1.0" stroke-width="1.5" width="{{.Width}}" height="{{.Height}}" x="{{.X}}" y="{{.Y}}"/>
+{{- end}}
+{{- range .Circles}}
+	<circle fill="rgb(255,255,255)" fill-opacity="1.0" stroke="rgb(0,0,0)" stroke-opacity="1.0" stroke-width="1.5" cx="{{.CX}}" cy="{{.CY}}" r="{{.R}}"/>
+{{- end}}
 {{range .Lines}}
 	<line stroke="rgb(0,0,0)" stroke-opacity="1.0" stroke-width="1.0" x1="{{.X1}}" y1="{{.Y1}}" x2="{{.X2}}" y2="{{.Y2}}"/>
 {{- end}}
 {{range .Texts}}
-	<text fill="rgb(0,0,0)" fill-opacity="1.0" font-family="monospace" font-size="16" x="{{.X}}" y="{{.Y}}" textLength="{{.Width}}" lengthAdjust="spacingAndGlyphs" xml:space="preserve">{{.Text}}</text>
+{{- if .Link}}
+	<a xmlns:xlink="http://www.w3.org/1999/xlink" xlink:href="{{html .Link}}" href="{{html .Link}}"><text fill="rgb(0,0,0)" fill-opacity="1.0" font-family="monospace" font-size="{{$.FontSize}}" x="{{.X}}" y="{{.Y}}" textLength="{{.Width}}" lengthAdjust="spacingAndGlyphs" xml:space="preserve">{{html .Text}}{{if .Title}}<title>{{html .Title}}</title>{{end}}</text></a>
+{{- else}}
+	<text fill="rgb(0,0,0)" fill-opacity="1.0" font-family="monospace" font-size="{{$.FontSize}}" x="{{.X}}" y="{{.Y}}" textLength="{{.Width}}" lengthAdjust="spacingAndGlyphs" xml:space="preserve">{{html .Text}}{{if .Title}}<title>{{html .Title}}</title>{{end}}</text>
+{{- end}}
 {{- end}}
 </svg>
 `
@@ -34,46 +51,51 @@ const svgDiagram = `<?xml version="1.0" ?>
 // Arrow contains all information for displaying an Arrow including data type
 // and ports.
 type Arrow struct {
-	DataType []string
-	HasSrcOp bool
-	SrcPort  string
-	HasDstOp bool
-	DstPort  string
+	DataType []string `json:"dataType,omitempty"`
+	HasSrcOp bool     `json:"hasSrcOp,omitempty"`
+	SrcPort  string   `json:"srcPort,omitempty"`
+	HasDstOp bool     `json:"hasDstOp,omitempty"`
+	DstPort  string   `json:"dstPort,omitempty"`
 }
 
 // Rect just contains the text lines to display in a rectangle.
 type Rect struct {
-	Text []string
+	Text []string `json:"text"`
 }
 
 // Plugin is a helper operation that is used inside a proper operation.
 type Plugin struct {
-	Title string
-	Rects []*Rect
+	Title string  `json:"title,omitempty"`
+	Rects []*Rect `json:"rects,omitempty"`
 }
 
 // Op holds all data to describe a single operation including possible plugins.
+// An operation that is implemented by another flow can show it inline
+// (Subflow) or link to its diagram (Link, SVG and draw.io only).
+// Subflows are always drawn in rows.
 type Op struct {
-	Main    *Rect
-	Plugins []*Plugin
+	Main    *Rect     `json:"main"`
+	Plugins []*Plugin `json:"plugins,omitempty"`
+	Subflow *Flow     `json:"subflow,omitempty"`
+	Link    string    `json:"link,omitempty"`
 }
 
 // Split contains data for multiple paths/arrows originating from a single Op.
 type Split struct {
-	Shapes [][]interface{}
+	Shapes [][]Shape
 }
 
 // Merge holds data for merging multiple paths/arrows into a single Op.
 type Merge struct {
-	ID   string
-	Size int
+	ID   string `json:"id"`
+	Size int    `json:"size"`
 }
 
 // Flow contains data for a whole flow.
 // The data is organized in rows and individual shapes per row.
-// Valid shapes are Arrow, Op, Split and Merge.
+// It can be built with NewFlow, too.
 type Flow struct {
-	Shapes [][]interface{}
+	Shapes [][]Shape
 }
 
 type svgArrow struct {
@@ -81,13 +103,27 @@ type svgArrow struct {
 	X2, Y2       int
 	XTip1, YTip1 int
 	XTip2, YTip2 int
+	IsError      bool
+
+	path *svgPolyline // the start of the arrow (layered layout only)
+}
+
+type svgPoint struct {
+	X, Y int
+}
+
+type svgPolyline struct {
+	Points  []svgPoint
+	IsError bool
 }
 
 type svgRect struct {
-	X, Y     int
-	Width    int
-	Height   int
-	IsPlugin bool
+	X, Y      int
+	Width     int
+	Height    int
+	IsPlugin  bool
+	IsSubflow bool   // the operation contains its subflow
+	Link      string // URL of the diagram of the subflow
 }
 
 type svgLine struct {
@@ -96,19 +132,31 @@ type svgLine struct {
 }
 
 type svgText struct {
-	X, Y  int
-	Width int
-	Text  string
+	X, Y   int
+	Width  int
+	Text   string
+	Title  string
+	Link   string // URL of the diagram of the subflow
+	onLine bool   // the text is vertically centered on an arrow
+	under  bool   // the text is a port under an arrow
 }
 
 type svgFlow struct {
 	TotalWidth  int
 	TotalHeight int
+	FontSize    int
 	Arrows      []*svgArrow
+	Polylines   []*svgPolyline
 	Rects       []*svgRect
 	Lines       []*svgLine
 	Texts       []*svgText
+	PortMarkers []*svgRect
+	Circles     []*svgCircle
 
+	cfg            *LayoutConfig
+	portEnds       []*portEnd
+	connectors     []*connector
+	errorEnds      []*errorEnd
 	completedMerge *myMergeData
 	allMerges      map[string]*myMergeData
 }
@@ -128,48 +176,33 @@ type moveData struct {
 
 var tmpl = template.Must(template.New("diagram").Parse(svgDiagram))
 
-// FromFlowData creates a SVG diagram from flow data.
+// FromFlowData creates a SVG diagram from flow data using the default
+// layout configuration.
 // If the flow data isn't valid or the SVG diagram can't be created with its
 // template, an error is returned.
 func FromFlowData(f Flow) ([]byte, error) {
-	err := validateFlowData(f)
+	return FromFlowDataWithConfig(f, DefaultLayoutConfig())
+}
+
+// FromFlowDataWithConfig creates a SVG diagram from flow data using the given
+// layout configuration.
+// If the flow data or configuration isn't valid or the SVG diagram can't be
+// created with its template, an error is returned.
+func FromFlowDataWithConfig(f Flow, cfg LayoutConfig) ([]byte, error) {
+	err := validateLayoutConfig(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	err = validateFlowData(f)
 	if err != nil {
 		return nil, err
 	}
 
-	sf := flowDataToSVGFlow(f)
+	sf := flowDataToSVGFlow(f, &cfg)
 
 	return svgFlowToBytes(sf)
 }
 
-func validateFlowData(f Flow) error {
-	return validateShapes(f.Shapes)
-}
-
-func validateShapes(shapes [][]interface{}) error {
-	if len(shapes) <= 0 {
-		return fmt.Errorf("No shapes found")
-	}
-	for i, row := range shapes {
-		for j, ishape := range row {
-			switch shape := ishape.(type) {
-			case *Arrow, *Op, *Merge, *Rect:
-				break
-			case *Split:
-				err := validateShapes(shape.Shapes)
-				if err != nil {
-					return err
-				}
-			default:
-				return fmt.Errorf(
-					"unsupported shape type %T at row index %d and column index %d",
-					ishape, i, j)
-			}
-		}
-	}
-	return nil
-}
-
 func svgFlowToBytes(sf *svgFlow) ([]byte, error) {
 	buf := bytes.Buffer{}
 	err := tmpl.Execute(&buf, sf)
@@ -179,8 +212,8 @@ func svgFlowToBytes(sf *svgFlow) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-func flowDataToSVGFlow(f Flow) *svgFlow {
-	sf, x, y := initSVGData()
+func flowDataToSVGFlow(f Flow, cfg *LayoutConfig) *svgFlow {
+	sf, x, y := initSVGData(cfg)
 	sf, x, y = shapesToSVG(
 		f.Shapes,
 		sf, x, y,
@@ -190,16 +223,23 @@ func flowDataToSVGFlow(f Flow) *svgFlow {
 		splitDataToSVG,
 		mergeDataToSVG,
 	)
+	x = collapseErrorSinks(sf, x)
+	addPortMarkers(sf)
+	addConnectorMarkers(sf)
 	return adjustDimensions(sf, x, y)
 }
 
-func initSVGData() (sf *svgFlow, x0, y0 int) {
+func initSVGData(cfg *LayoutConfig) (sf *svgFlow, x0, y0 int) {
 	return &svgFlow{
-		Arrows: make([]*svgArrow, 0, 64),
-		Rects:  make([]*svgRect, 0, 64),
-		Lines:  make([]*svgLine, 0, 64),
-		Texts:  make([]*svgText, 0, 64),
+		FontSize: cfg.FontSize,
+
+		Arrows:    make([]*svgArrow, 0, 64),
+		Polylines: make([]*svgPolyline, 0, 16),
+		Rects:     make([]*svgRect, 0, 64),
+		Lines:     make([]*svgLine, 0, 64),
+		Texts:     make([]*svgText, 0, 64),
 
+		cfg:       cfg,
 		allMerges: make(map[string]*myMergeData),
 	}, 2, 1
 }
@@ -210,7 +250,7 @@ func adjustDimensions(sf *svgFlow, xn, yn int) *svgFlow {
 }
 
 func shapesToSVG(
-	shapes [][]interface{}, sf *svgFlow, x0 int, y0 int,
+	shapes [][]Shape, sf *svgFlow, x0 int, y0 int,
 	pluginArrowDataToSVG func(*Arrow, *svgFlow, *svgRect, int, int) (*svgFlow, int, int, *moveData),
 	pluginOpDataToSVG func(*Op, *svgFlow, int, int, int) (*svgFlow, *svgRect, int, int, int),
 	pluginRectDataToSVG func(*Rect, *svgFlow, int, int) (*svgFlow, int, int),
@@ -225,7 +265,7 @@ func shapesToSVG(
 		x := x0
 		lsr = nil
 		if len(ss) < 1 {
-			y0 += 48
+			y0 += 2 * sf.cfg.LineHeight
 			continue
 		}
 		ya := y0
@@ -234,7 +274,7 @@ func shapesToSVG(
 			switch s := is.(type) {
 			case *Arrow:
 				sf, x, y, mod = pluginArrowDataToSVG(s, sf, lsr, x, y)
-				ya = y - 48 // use the upper arrow Y not the lowest Y
+				ya = y - 2*sf.cfg.LineHeight // use the upper arrow Y not the lowest Y
 				lsr = nil
 			case *Op:
 				sf, lsr, y0, x, y = pluginOpDataToSVG(s, sf, x, y0, ymax)
@@ -257,7 +297,7 @@ func shapesToSVG(
 			ymax = max(ymax, y)
 		}
 		xmax = max(xmax, x)
-		y0 = ymax + 5
+		y0 = ymax + sf.cfg.RowGap
 	}
 	return sf, xmax, ymax
 }
@@ -1,7 +1,7 @@
 package svg
 
 var BigTestFlowData = Flow{
-	Shapes: [][]interface{}{
+	Shapes: [][]Shape{
 		{
 			&Arrow{
 				DataType: []string{"Data"},
@@ -14,7 +14,7 @@ var BigTestFlowData = Flow{
 				},
 			},
 			&Split{
-				Shapes: [][]interface{}{
+				Shapes: [][]Shape{
 					{
 						&Arrow{
 							DataType: []string{"Data"},
@@ -71,7 +71,7 @@ var BigTestFlowData = Flow{
 			},
 		}, {
 			&Split{
-				Shapes: [][]interface{}{
+				Shapes: [][]Shape{
 					{
 						&Arrow{
 							DataType: []string{},
@@ -0,0 +1,108 @@
+package svg
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Characters after which texts may be wrapped.
+const (
+	dataBreaks = ","  // data type lists of arrows
+	typeBreaks = ".," // component and plugin types (package dots)
+)
+
+const ellipsis = "..."
+
+// textLine is a single line of text as displayed in the diagram.
+// If the text has been truncated, title contains the full text.
+type textLine struct {
+	text  string
+	title string
+}
+
+// fitTexts makes the texts fit into the maximal text width of the
+// configuration.
+// Texts that are too long are wrapped after one of the breaks characters.
+// If TruncateTexts is set, they are truncated with an ellipsis instead.
+func (c *LayoutConfig) fitTexts(texts []string, breaks string) []textLine {
+	lines := make([]textLine, 0, len(texts))
+	n := c.maxTextLen()
+	for _, t := range texts {
+		switch {
+		case n <= 0 || textLen(t) <= n:
+			lines = append(lines, textLine{text: t})
+		case c.TruncateTexts:
+			lines = append(lines, textLine{text: truncateText(t, n), title: t})
+		default:
+			for _, w := range wrapText(t, n, breaks) {
+				lines = append(lines, textLine{text: w})
+			}
+		}
+	}
+	return lines
+}
+
+// maxTextLen returns the maximal number of characters of a text line
+// (0 means unlimited).
+func (c *LayoutConfig) maxTextLen() int {
+	if c.MaxTextWidth <= 0 {
+		return 0
+	}
+	return max(c.MaxTextWidth/c.CharWidth, 1)
+}
+
+// wrapText wraps the text after a break character so every line has got at
+// most n characters.
+// Lines without any possible break are kept as long as necessary.
+func wrapText(text string, n int, breaks string) []string {
+	var lines []string
+	for textLen(text) > n {
+		m := runeOffset(text, n)
+		i := strings.LastIndexAny(text[:m], breaks)
+		if i < 0 { // no break in time: use the first one possible
+			i = strings.IndexAny(text[m:], breaks)
+			if i < 0 {
+				break
+			}
+			i += m
+		}
+		if i >= len(text)-1 {
+			break
+		}
+		lines = append(lines, text[:i+1])
+		text = text[i+1:]
+	}
+	return append(lines, text)
+}
+
+func truncateText(text string, n int) string {
+	if n <= len(ellipsis) {
+		return text[:runeOffset(text, n)]
+	}
+	return text[:runeOffset(text, n-len(ellipsis))] + ellipsis
+}
+
+func maxLineLen(lines []textLine) int {
+	m := 0
+	for _, l := range lines {
+		m = max(m, textLen(l.text))
+	}
+	return m
+}
+
+// textLen returns the number of characters (not bytes) of the text.
+func textLen(text string) int {
+	return utf8.RuneCountInString(text)
+}
+
+// runeOffset returns the byte offset of the n-th character of the text
+// (or its length if it is shorter).
+func runeOffset(text string, n int) int {
+	for i := range text {
+		if n == 0 {
+			return i
+		}
+		n--
+	}
+	return len(text)
+}
@@ -0,0 +1,96 @@
+package svg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFitTexts(t *testing.T) {
+	specs := []struct {
+		name     string
+		given    []string
+		breaks   string
+		maxWidth int
+		truncate bool
+		expected []textLine
+	}{
+		{
+			name:     "unlimited",
+			given:    []string{"(a.Bla, b.Blue, c.Blup)"},
+			breaks:   dataBreaks,
+			expected: []textLine{{text: "(a.Bla, b.Blue, c.Blup)"}},
+		}, {
+			name:     "short",
+			given:    []string{"(a.Bla)"},
+			breaks:   dataBreaks,
+			maxWidth: 120,
+			expected: []textLine{{text: "(a.Bla)"}},
+		}, {
+			name:     "data",
+			given:    []string{"(a.Bla, b.Blue, c.Blup)", " d.Dodo)"},
+			breaks:   dataBreaks,
+			maxWidth: 180,
+			expected: []textLine{
+				{text: "(a.Bla, b.Blue,"}, {text: " c.Blup)"}, {text: " d.Dodo)"},
+			},
+		}, {
+			name:     "type",
+			given:    []string{"fetch", "httpclient.GetRequest"},
+			breaks:   typeBreaks,
+			maxWidth: 120,
+			expected: []textLine{
+				{text: "fetch"}, {text: "httpclient."}, {text: "GetRequest"},
+			},
+		}, {
+			name:     "noBreakInTime",
+			given:    []string{"(VeryLongTypeName, a.Bla)"},
+			breaks:   dataBreaks,
+			maxWidth: 60,
+			expected: []textLine{{text: "(VeryLongTypeName,"}, {text: " a.Bla)"}},
+		}, {
+			name:     "noBreakAtAll",
+			given:    []string{"(VeryLongTypeName)"},
+			breaks:   dataBreaks,
+			maxWidth: 60,
+			expected: []textLine{{text: "(VeryLongTypeName)"}},
+		}, {
+			name:     "truncate",
+			given:    []string{"httpclient.GetRequest", "short"},
+			breaks:   typeBreaks,
+			maxWidth: 120,
+			truncate: true,
+			expected: []textLine{
+				{text: "httpcli...", title: "httpclient.GetRequest"},
+				{text: "short"},
+			},
+		}, {
+			name:     "unicode",
+			given:    []string{"größenmaß.Übergröße", "(Größe)"},
+			breaks:   typeBreaks,
+			maxWidth: 120,
+			expected: []textLine{
+				{text: "größenmaß."}, {text: "Übergröße"}, {text: "(Größe)"},
+			},
+		}, {
+			name:     "truncateUnicode",
+			given:    []string{"Übergrößenmaß"},
+			breaks:   typeBreaks,
+			maxWidth: 120,
+			truncate: true,
+			expected: []textLine{
+				{text: "Übergrö...", title: "Übergrößenmaß"},
+			},
+		},
+	}
+	for _, spec := range specs {
+		t.Run(spec.name, func(t *testing.T) {
+			cfg := DefaultLayoutConfig()
+			cfg.MaxTextWidth = spec.maxWidth
+			cfg.TruncateTexts = spec.truncate
+			got := cfg.fitTexts(spec.given, spec.breaks)
+			if !reflect.DeepEqual(got, spec.expected) {
+				t.Errorf("Expected lines %q but got: %q", spec.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package svg
+
+import (
+	"bytes"
+	"strings"
+)
+
+// TextStyle is the set of characters used for drawing a diagram as text.
+type TextStyle int
+
+// Available text styles.
+const (
+	// TextUnicode uses Unicode box-drawing characters.
+	TextUnicode TextStyle = iota
+	// TextASCII uses plain ASCII characters only.
+	TextASCII
+)
+
+// Directions of the lines in a cell.
+const (
+	lineLeft = 1 << iota
+	lineRight
+	lineUp
+	lineDown
+)
+
+var unicodeLines = map[int]rune{
+	lineLeft:                                 '─',
+	lineRight:                                '─',
+	lineLeft | lineRight:                     '─',
+	lineUp:                                   '│',
+	lineDown:                                 '│',
+	lineUp | lineDown:                        '│',
+	lineRight | lineDown:                     '┌',
+	lineLeft | lineDown:                      '┐',
+	lineRight | lineUp:                       '└',
+	lineLeft | lineUp:                        '┘',
+	lineUp | lineDown | lineRight:            '├',
+	lineUp | lineDown | lineLeft:             '┤',
+	lineLeft | lineRight | lineDown:          '┬',
+	lineLeft | lineRight | lineUp:            '┴',
+	lineLeft | lineRight | lineUp | lineDown: '┼',
+}
+
+var unicodeRoundCorners = map[int]rune{
+	lineRight | lineDown: '╭',
+	lineLeft | lineDown:  '╮',
+	lineRight | lineUp:   '╰',
+	lineLeft | lineUp:    '╯',
+}
+
+// TextLayoutConfig returns the layout configuration used for drawing
+// diagrams as text.
+// All metrics are measured in character cells.
+func TextLayoutConfig() LayoutConfig {
+	return LayoutConfig{
+		FontSize:       3,
+		CharWidth:      1,
+		LineHeight:     2,
+		DataLineHeight: 1,
+		Padding:        1,
+		ArrowTipSize:   1,
+		RowGap:         1,
+		MinArrowLength: 3,
+	}
+}
+
+// TextFromFlowData creates a text diagram from flow data.
+// The diagram is the same as the one created by FromFlowData.
+// If the flow data isn't valid, an error is returned.
+func TextFromFlowData(f Flow, style TextStyle) ([]byte, error) {
+	cfg := TextLayoutConfig()
+	err := validateFlowData(f)
+	if err != nil {
+		return nil, err
+	}
+
+	sf := flowDataToSVGFlow(f, &cfg)
+
+	return svgFlowToText(sf, style), nil
+}
+
+// TextFromGraphData creates a text diagram from graph data.
+// The diagram is the same as the one created by FromGraphData.
+// If the graph data isn't valid, an error is returned.
+func TextFromGraphData(g Graph, style TextStyle) ([]byte, error) {
+	cfg := TextLayoutConfig()
+	err := validateGraphData(g)
+	if err != nil {
+		return nil, err
+	}
+
+	lg, err := newLayeredGraph(g, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	sf := layeredGraphToSVGFlow(lg)
+
+	return svgFlowToText(sf, style), nil
+}
+
+// textCanvas is a grid of character cells.
+type textCanvas struct {
+	width, height int
+	lines         [][]int  // directions of lines
+	round         [][]bool // rounded corners
+	chars         [][]rune // texts and arrow tips
+}
+
+func newTextCanvas(width, height int) *textCanvas {
+	tc := &textCanvas{
+		width:  width,
+		height: height,
+		lines:  make([][]int, height),
+		round:  make([][]bool, height),
+		chars:  make([][]rune, height),
+	}
+	for y := 0; y < height; y++ {
+		tc.lines[y] = make([]int, width)
+		tc.round[y] = make([]bool, width)
+		tc.chars[y] = make([]rune, width)
+	}
+	return tc
+}
+
+func (tc *textCanvas) inside(x, y int) bool {
+	return x >= 0 && x < tc.width && y >= 0 && y < tc.height
+}
+
+func (tc *textCanvas) addLine(x, y, dir int) {
+	if tc.inside(x, y) {
+		tc.lines[y][x] |= dir
+	}
+}
+
+func (tc *textCanvas) hLine(x1, x2, y int) {
+	if x1 > x2 {
+		x1, x2 = x2, x1
+	}
+	for x := x1; x <= x2; x++ {
+		if x > x1 {
+			tc.addLine(x, y, lineLeft)
+		}
+		if x < x2 {
+			tc.addLine(x, y, lineRight)
+		}
+	}
+	if x1 == x2 {
+		tc.addLine(x1, y, lineLeft|lineRight)
+	}
+}
+
+func (tc *textCanvas) vLine(x, y1, y2 int) {
+	if y1 > y2 {
+		y1, y2 = y2, y1
+	}
+	for y := y1; y <= y2; y++ {
+		if y > y1 {
+			tc.addLine(x, y, lineUp)
+		}
+		if y < y2 {
+			tc.addLine(x, y, lineDown)
+		}
+	}
+}
+
+func (tc *textCanvas) rect(r *svgRect) {
+	x2, y2 := r.X+r.Width, r.Y+r.Height
+	tc.hLine(r.X, x2, r.Y)
+	tc.hLine(r.X, x2, y2)
+	tc.vLine(r.X, r.Y, y2)
+	tc.vLine(x2, r.Y, y2)
+	if !r.IsPlugin {
+		for _, p := range []svgPoint{{r.X, r.Y}, {x2, r.Y}, {r.X, y2}, {x2, y2}} {
+			if tc.inside(p.X, p.Y) {
+				tc.round[p.Y][p.X] = true
+			}
+		}
+	}
+}
+
+func (tc *textCanvas) text(x, y int, text string) {
+	for _, r := range text {
+		if tc.inside(x, y) {
+			tc.chars[y][x] = r
+		}
+		x++
+	}
+}
+
+func svgFlowToText(sf *svgFlow, style TextStyle) []byte {
+	tc := newTextCanvas(sf.TotalWidth, sf.TotalHeight)
+
+	for _, r := range sf.Rects {
+		tc.rect(r)
+	}
+	for _, l := range sf.Lines {
+		tc.hLine(l.X1, l.X2, l.Y1)
+	}
+	for _, pl := range sf.Polylines {
+		for i := 1; i < len(pl.Points); i++ {
+			p, q := pl.Points[i-1], pl.Points[i]
+			if p.Y == q.Y {
+				tc.hLine(p.X, q.X, p.Y)
+			} else {
+				tc.vLine(p.X, p.Y, q.Y)
+			}
+		}
+	}
+	tip := '▶'
+	if style == TextASCII {
+		tip = '>'
+	}
+	for _, a := range sf.Arrows {
+		tc.hLine(a.X1, a.X2-1, a.Y1)
+		if tc.inside(a.X2-1, a.Y2) {
+			tc.chars[a.Y2][a.X2-1] = tip
+		}
+	}
+	for _, t := range sf.Texts {
+		y := t.Y
+		switch {
+		case t.onLine:
+			y -= sf.cfg.descent()
+		case t.under:
+			y -= sf.cfg.portTextOffset() - 1
+		}
+		tc.text(t.X, y, t.Text)
+	}
+
+	return tc.bytes(style)
+}
+
+// bytes returns the text of the canvas.
+// Rows that only contain vertical lines are left out since the layout
+// reserves more vertical space than necessary for text.
+// Multiple empty rows are collapsed into one.
+func (tc *textCanvas) bytes(style TextStyle) []byte {
+	buf := bytes.Buffer{}
+	empty := false
+	for y := 0; y < tc.height; y++ {
+		if tc.onlyVertical(y) {
+			continue
+		}
+		line := make([]rune, tc.width)
+		for x := 0; x < tc.width; x++ {
+			line[x] = tc.cell(x, y, style)
+		}
+		s := strings.TrimRight(string(line), " ")
+		if s == "" {
+			empty = buf.Len() > 0
+			continue
+		}
+		if empty {
+			buf.WriteByte('\n')
+			empty = false
+		}
+		buf.WriteString(s)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func (tc *textCanvas) onlyVertical(y int) bool {
+	found := false
+	for x := 0; x < tc.width; x++ {
+		if tc.chars[y][x] != 0 {
+			return false
+		}
+		switch tc.lines[y][x] {
+		case 0:
+		case lineUp | lineDown:
+			found = true
+		default:
+			return false
+		}
+	}
+	return found
+}
+
+func (tc *textCanvas) cell(x, y int, style TextStyle) rune {
+	if c := tc.chars[y][x]; c != 0 {
+		return c
+	}
+	dirs := tc.lines[y][x]
+	if dirs == 0 {
+		return ' '
+	}
+	if style == TextASCII {
+		switch dirs {
+		case lineLeft, lineRight, lineLeft | lineRight:
+			return '-'
+		case lineUp, lineDown, lineUp | lineDown:
+			return '|'
+		default:
+			return '+'
+		}
+	}
+	if tc.round[y][x] {
+		if c, ok := unicodeRoundCorners[dirs]; ok {
+			return c
+		}
+	}
+	return unicodeLines[dirs]
+}
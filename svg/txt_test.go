@@ -0,0 +1,112 @@
+package svg_test
+
+import (
+	"testing"
+
+	"github.com/flowdev/gflowparser/svg"
+)
+
+const expText = `
+      Data   ╭───────╮   Data      ╭──────────────╮ BigDataType   ╭─────────╮
+   in───────▶│       ├────────────▶│              ├──────────────▶│         │
+             │ra     │special   in │do            │out        in1 │BigMerge │
+             │(MiSo) │             ├──────────────┤               │         │
+             │       │             │semantics:    │               │         │
+             │       │             ├──────────────┤               │         │
+             │       │             │TextSemantics │               │         │
+             │       │             ├──────────────┤               │         │
+             │       │             │subParser:    │               │         │
+             │       │             ├──────────────┤               │         │
+             │       │             │LitralParser  │               │         │
+             │       │             ├──────────────┤               │         │
+             │       │             │NaturalParser │               │         │
+             │       │             ├──────────────┤               │         │
+             │       │             ╰──────────────╯               │         │
+             │       │ Data    ╭───────╮ Data2                    │         │
+             │       ├────────▶│       ├────────▶...              │         │
+             │       │out   in │bla    │                          │         │
+             │       │         │(Blue) │                          │         │
+             │       │         ╰───────╯                          │         │
+             ╰───────╯                                            │         │
+            ╭───────╮                   Data                      │         │
+   ...─────▶│       ├────────────────────────────────────────────▶│         │
+         in │bla    │out                                      in2 │         │
+            │(Blue) │                                             │         │
+            ╰───────╯                                             │         │
+       Data3   ╭──────────────╮             (Data,                │         │
+   in2────────▶│              │              data2,               │         │
+            in │megaParser    │              Data3)               │         │
+               │              ├──────────────────────────────────▶│         │
+               │(MegaParser)  │out                            in3 │         │
+               ├──────────────┤                                   ╰─────────╯
+               │semantics:    │
+               ├──────────────┤
+               │TextSemantics │
+               ├──────────────┤
+               │subParser:    │
+               ├──────────────┤
+               │LitralParser  │
+               ├──────────────┤
+               │NaturalParser │
+               ├──────────────┤
+               ╰──────────────╯
+
+       (Data,    ╭──────────╮ (Data)   ╭─────────╮ (Data,
+        data2,   │          ├─────────▶│         │  data2,
+        Data3)   │recursive │          │secondOp │  Data3)
+   in3──────────▶│          │          │         ├──────────▶... back to: recursive
+                 │          │          │         │out
+                 ╰──────────╯          │         │
+                                       ╰─────────╯
+`
+
+func TestTextFromFlowData(t *testing.T) {
+	buf, err := svg.TextFromFlowData(svg.BigTestFlowData, svg.TextUnicode)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if got := "\n" + string(buf); got != expText {
+		t.Errorf("Expected text diagram:\n%s\nbut got:\n%s", expText, got)
+	}
+
+	buf, err = svg.TextFromFlowData(svg.BigTestFlowData, svg.TextASCII)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	for _, r := range string(buf) {
+		if r > 127 {
+			t.Fatalf("Expected only ASCII characters but found '%c' in:\n%s", r, buf)
+		}
+	}
+
+	_, err = svg.TextFromFlowData(svg.Flow{}, svg.TextUnicode)
+	if err == nil {
+		t.Error("Expected an error for an empty flow but didn't get one.")
+	}
+}
+
+func TestTextFromGraphData(t *testing.T) {
+	g := svg.Graph{
+		Nodes: []*svg.Node{
+			{ID: "in", Port: "in"},
+			{ID: "a", Op: &svg.Op{Main: &svg.Rect{Text: []string{"a"}}}},
+			{ID: "back", Rect: &svg.Rect{Text: []string{"a"}}},
+		},
+		Edges: []*svg.Edge{
+			{From: "in", To: "a", Arrow: &svg.Arrow{DataType: []string{"(data)"}}},
+			{From: "a", To: "back", Arrow: &svg.Arrow{SrcPort: "out"}},
+		},
+	}
+	buf, err := svg.TextFromGraphData(g, svg.TextASCII)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	exp := `      (data)   +--+
+   in--------->|  +------>... back to: a
+               |a |out
+               +--+
+`
+	if got := string(buf); got != exp {
+		t.Errorf("Expected text diagram:\n%s\nbut got:\n%s", exp, got)
+	}
+}
@@ -0,0 +1,213 @@
+package svg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError is a single structural problem of flow data.
+// The path of the problematic value uses the names of the JSON encoding,
+// e.g.: shapes[0][3].shapes[1][0].main
+type ValidationError struct {
+	Path string
+	Msg  string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Path + ": " + e.Msg
+}
+
+// ValidationErrors are all problems found by Validate in the order of the
+// shapes.
+type ValidationErrors []*ValidationError
+
+func (es ValidationErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	if len(msgs) == 1 {
+		return "invalid flow data: " + msgs[0]
+	}
+	return fmt.Sprintf("invalid flow data (%d problems):\n", len(msgs)) +
+		strings.Join(msgs, "\n")
+}
+
+// mergeUse is a single use of a merge ID.
+type mergeUse struct {
+	path string
+	size int
+}
+
+type validator struct {
+	errs      ValidationErrors
+	mergeIDs  []string // in order of first use
+	mergeUses map[string][]mergeUse
+}
+
+// Validate checks the structure of flow data and returns all problems found
+// as ValidationErrors.
+// Valid flow data can be converted by FromFlowData, TextFromFlowData and
+// DrawIOFromFlowData.
+// Besides missing shapes it finds:
+// - operations without main rectangle,
+// - rectangles without text,
+// - empty splits and
+// - merges without ID, with inconsistent size or that never complete.
+// Subflows of operations are validated, too.
+func Validate(f Flow) error {
+	v := &validator{mergeUses: make(map[string][]mergeUse)}
+	if len(f.Shapes) == 0 {
+		v.add("shapes", "no shapes found")
+	}
+	v.validateShapes("shapes", f.Shapes)
+	v.validateMerges()
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func validateFlowData(f Flow) error {
+	return Validate(f)
+}
+
+func (v *validator) add(path, format string, a ...interface{}) {
+	v.errs = append(v.errs, &ValidationError{Path: path, Msg: fmt.Sprintf(format, a...)})
+}
+
+func (v *validator) validateShapes(path string, shapes [][]Shape) {
+	for i, row := range shapes {
+		for j, ishape := range row {
+			p := fmt.Sprintf("%s[%d][%d]", path, i, j)
+			switch shape := ishape.(type) {
+			case *Arrow:
+				if shape == nil {
+					v.add(p, "missing arrow")
+				}
+			case *Op:
+				v.validateOp(p, shape)
+			case *Rect:
+				v.validateRect(p, shape)
+			case *Split:
+				v.validateSplit(p, shape)
+			case *Merge:
+				v.validateMerge(p, shape, row[:j])
+			case nil:
+				v.add(p, "missing shape")
+			default:
+				v.add(p, "unsupported shape type %T", ishape)
+			}
+		}
+	}
+}
+
+func (v *validator) validateOp(path string, op *Op) {
+	if op == nil {
+		v.add(path, "missing operation")
+		return
+	}
+	if op.Main == nil {
+		v.add(path+".main", "missing main rectangle")
+	} else {
+		v.validateRect(path+".main", op.Main)
+	}
+	for i, plugin := range op.Plugins {
+		p := fmt.Sprintf("%s.plugins[%d]", path, i)
+		if plugin == nil {
+			v.add(p, "missing plugin")
+			continue
+		}
+		for j, r := range plugin.Rects {
+			v.validateRect(fmt.Sprintf("%s.rects[%d]", p, j), r)
+		}
+	}
+	if op.Subflow != nil {
+		// merge IDs are local to a flow
+		if errs, ok := Validate(*op.Subflow).(ValidationErrors); ok {
+			for _, e := range errs {
+				v.add(path+".subflow."+e.Path, "%s", e.Msg)
+			}
+		}
+	}
+}
+
+func (v *validator) validateRect(path string, r *Rect) {
+	if r == nil {
+		v.add(path, "missing rectangle")
+		return
+	}
+	if len(r.Text) == 0 {
+		v.add(path+".text", "a rectangle needs at least one line of text")
+	}
+}
+
+func (v *validator) validateSplit(path string, s *Split) {
+	if s == nil {
+		v.add(path, "missing split")
+		return
+	}
+	empty := true
+	for _, row := range s.Shapes {
+		if len(row) > 0 {
+			empty = false
+			break
+		}
+	}
+	if empty {
+		v.add(path+".shapes", "a split needs at least one shape")
+		return
+	}
+	v.validateShapes(path+".shapes", s.Shapes)
+}
+
+func (v *validator) validateMerge(path string, m *Merge, before []Shape) {
+	if m == nil {
+		v.add(path, "missing merge")
+		return
+	}
+	if len(before) == 0 {
+		v.add(path, "a merge has to follow an arrow")
+	} else if _, ok := before[len(before)-1].(*Arrow); !ok {
+		v.add(path, "a merge has to follow an arrow but follows a %T",
+			before[len(before)-1])
+	}
+	if m.ID == "" {
+		v.add(path+".id", "missing merge ID")
+		return
+	}
+	if m.Size <= 0 {
+		v.add(path+".size", "merge size has to be positive but is: %d", m.Size)
+	}
+	if _, ok := v.mergeUses[m.ID]; !ok {
+		v.mergeIDs = append(v.mergeIDs, m.ID)
+	}
+	v.mergeUses[m.ID] = append(v.mergeUses[m.ID], mergeUse{path: path, size: m.Size})
+}
+
+// validateMerges checks that all merges with the same ID have got the same
+// size and that the size is the number of merged arrows.
+func (v *validator) validateMerges() {
+	for _, id := range v.mergeIDs {
+		uses := v.mergeUses[id]
+		size := uses[0].size
+		for _, u := range uses[1:] {
+			if u.size != size {
+				v.add(u.path+".size",
+					"merge '%s' has got size %d here but size %d at %s",
+					id, u.size, size, uses[0].path)
+			}
+		}
+		switch {
+		case size <= 0:
+			// already reported
+		case len(uses) < size:
+			v.add(uses[0].path,
+				"merge '%s' never completes: it has got size %d but merges only %d arrows",
+				id, size, len(uses))
+		case len(uses) > size:
+			v.add(uses[0].path, "merge '%s' has got size %d but merges %d arrows",
+				id, size, len(uses))
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package svg_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/flowdev/gflowparser/svg"
+)
+
+func TestValidate(t *testing.T) {
+	arrow := func() *svg.Arrow { return &svg.Arrow{HasSrcOp: true, HasDstOp: true} }
+	op := func(txt string) *svg.Op { return &svg.Op{Main: &svg.Rect{Text: []string{txt}}} }
+
+	specs := []struct {
+		name          string
+		givenFlow     svg.Flow
+		expectedPaths []string
+	}{
+		{
+			name:          "big test flow",
+			givenFlow:     svg.BigTestFlowData,
+			expectedPaths: nil,
+		}, {
+			name:          "no shapes",
+			givenFlow:     svg.Flow{},
+			expectedPaths: []string{"shapes"},
+		}, {
+			name: "missing shapes",
+			givenFlow: svg.Flow{Shapes: [][]svg.Shape{
+				{arrow(), nil},
+				{(*svg.Op)(nil), (*svg.Arrow)(nil)},
+			}},
+			expectedPaths: []string{"shapes[0][1]", "shapes[1][0]", "shapes[1][1]"},
+		}, {
+			name: "bad ops and rects",
+			givenFlow: svg.Flow{Shapes: [][]svg.Shape{{
+				&svg.Op{},
+				arrow(),
+				&svg.Op{
+					Main: &svg.Rect{},
+					Plugins: []*svg.Plugin{
+						nil,
+						{Title: "p", Rects: []*svg.Rect{{Text: []string{"a"}}, nil}},
+					},
+				},
+				arrow(),
+				&svg.Rect{},
+			}}},
+			expectedPaths: []string{
+				"shapes[0][0].main",
+				"shapes[0][2].main.text",
+				"shapes[0][2].plugins[0]",
+				"shapes[0][2].plugins[1].rects[1]",
+				"shapes[0][4].text",
+			},
+		}, {
+			name: "empty split",
+			givenFlow: svg.Flow{Shapes: [][]svg.Shape{{
+				op("a"),
+				&svg.Split{Shapes: [][]svg.Shape{{}, {}}},
+			}, {
+				op("b"),
+				&svg.Split{Shapes: [][]svg.Shape{{arrow(), &svg.Rect{}}}},
+			}}},
+			expectedPaths: []string{"shapes[0][1].shapes", "shapes[1][1].shapes[0][1].text"},
+		}, {
+			name: "bad subflow",
+			givenFlow: svg.Flow{Shapes: [][]svg.Shape{{
+				arrow(),
+				&svg.Op{
+					Main: &svg.Rect{Text: []string{"a"}},
+					Subflow: &svg.Flow{Shapes: [][]svg.Shape{{
+						arrow(), &svg.Merge{ID: "b", Size: 2}, op("b"),
+					}}},
+				},
+			}}},
+			expectedPaths: []string{"shapes[0][1].subflow.shapes[0][1]"},
+		}, {
+			name: "bad merges",
+			givenFlow: svg.Flow{Shapes: [][]svg.Shape{{
+				op("a"),
+				&svg.Split{Shapes: [][]svg.Shape{
+					{arrow(), &svg.Merge{ID: "b", Size: 3}},
+					{arrow(), &svg.Merge{ID: "b", Size: 2}},
+					{arrow(), &svg.Merge{ID: "c", Size: 1}},
+					{arrow(), &svg.Merge{ID: "c", Size: 1}},
+					{arrow(), &svg.Merge{Size: 1}},
+					{arrow(), &svg.Merge{ID: "d", Size: 0}},
+					{&svg.Merge{ID: "e", Size: 1}},
+				}},
+			}, {
+				op("b"),
+			}}},
+			expectedPaths: []string{
+				"shapes[0][1].shapes[4][1].id",
+				"shapes[0][1].shapes[5][1].size",
+				"shapes[0][1].shapes[6][0]",
+				"shapes[0][1].shapes[1][1].size",
+				"shapes[0][1].shapes[0][1]",
+				"shapes[0][1].shapes[2][1]",
+			},
+		},
+	}
+
+	for _, spec := range specs {
+		t.Run(spec.name, func(t *testing.T) {
+			err := svg.Validate(spec.givenFlow)
+			if spec.expectedPaths == nil {
+				if err != nil {
+					t.Fatalf("Expected no error but got: %s", err)
+				}
+				return
+			}
+			errs, ok := err.(svg.ValidationErrors)
+			if !ok {
+				t.Fatalf("Expected validation errors but got: %#v", err)
+			}
+			paths := make([]string, len(errs))
+			for i, e := range errs {
+				paths[i] = e.Path
+			}
+			if !reflect.DeepEqual(paths, spec.expectedPaths) {
+				t.Errorf("Expected paths %q but got %q:\n%s", spec.expectedPaths, paths, err)
+			}
+			if _, err = svg.FromFlowData(spec.givenFlow); err == nil {
+				t.Error("Expected FromFlowData to fail for invalid flow data.")
+			}
+		})
+	}
+}